@@ -3,16 +3,25 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/shtirlic/knotidx/internal/config"
 	"github.com/shtirlic/knotidx/internal/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type Client struct {
@@ -25,11 +34,9 @@ func NewClient(c config.GRPCConfig) *Client {
 	}
 }
 
-func (c *Client) Start() (int, error) {
-
+// dial connects to the gRPC server of the daemon.
+func (c *Client) dial() (*grpc.ClientConn, error) {
 	var opts []grpc.DialOption
-	var err error
-	var jr []byte
 	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 
 	var address string
@@ -43,13 +50,25 @@ func (c *Client) Start() (int, error) {
 	}
 
 	slog.Info("GRPC Client Connect", "address", address)
-	conn, err := grpc.Dial(address, opts...)
+	return grpc.Dial(address, opts...)
+}
+
+func (c *Client) Start() (int, error) {
+	conn, err := c.dial()
 	if err != nil {
 		return 1, err
 	}
 	defer conn.Close()
 
 	grpcClient := pb.NewKnotidxClient(conn)
+
+	if *accessCmd != "" {
+		if _, err = grpcClient.ReportAccess(context.Background(), &pb.AccessRequest{Path: *accessCmd}); err != nil {
+			return 1, err
+		}
+		return 0, nil
+	}
+
 	s := bufio.NewScanner(os.Stdin)
 
 	if !*jsonCmd {
@@ -57,28 +76,257 @@ func (c *Client) Start() (int, error) {
 	}
 	for s.Scan() {
 		text := s.Text()
-		res, err := grpcClient.GetKeys(context.Background(), &pb.SearchRequest{Query: text})
+		results, err := c.search(grpcClient, &pb.SearchRequest{
+			Query:         text,
+			Limit:         int32(*limitCmd),
+			Rank:          *rankCmd,
+			Fuzzy:         *fuzzyCmd,
+			IgnoreCase:    *ignoreCaseCmd,
+			IgnoreAccents: *ignoreAccentsCmd,
+		})
 		if err != nil {
 			return 1, err
 		}
 
-		// if *jsonCmd {
-		// jr, err = json.Marshal(res.Results)
-		// } else {
-		jr, err = json.MarshalIndent(res.Results, "", "\t")
-		// }
-
+		jr, err := marshalResults(results)
 		if err != nil {
 			return 1, err
 		}
+		fmt.Println(string(jr))
 		if *jsonCmd {
-			fmt.Println(string(jr))
 			return 0, nil
-		} else {
-			fmt.Println("Results", "results")
-			fmt.Println(string(jr))
-			fmt.Print("Enter query: ")
 		}
+		fmt.Print("Enter query: ")
 	}
 	return 0, nil
 }
+
+// jsonOptions marshal messages to the protobuf JSON mapping under their proto
+// field names.
+var jsonOptions = protojson.MarshalOptions{UseProtoNames: true}
+
+// marshalResults returns the search results as an indented JSON array.
+func marshalResults(results []*pb.SearchItemResponse) ([]byte, error) {
+	list := make([]json.RawMessage, 0, len(results))
+	for _, r := range results {
+		b, err := jsonOptions.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return json.MarshalIndent(list, "", "\t")
+}
+
+// marshalMessage returns the message as indented JSON.
+func marshalMessage(m proto.Message) ([]byte, error) {
+	b, err := jsonOptions.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "\t"); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// search streams the results of the search request from the server, warning
+// about ranked results truncated by the server.
+func (c *Client) search(grpcClient pb.KnotidxClient, sr *pb.SearchRequest) (results []*pb.SearchItemResponse, err error) {
+	stream, err := grpcClient.SearchStream(context.Background(), sr)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if status.Code(err) == codes.OutOfRange {
+			slog.Warn("Search results truncated", "error", status.Convert(err).Message())
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+}
+
+// Command runs the daemon command: "status" prints the state of the daemon
+// indexers, scheduler and store, "reindex" and "cancel" restart or stop the
+// indexers of the path, all if empty, "add" and "remove" add or remove the fs
+// indexer of the path, "list" prints the configured indexers and "reload"
+// reloads the config file, printing the applied changes.
+func (c *Client) Command(cmd, path string) (int, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return 1, err
+	}
+	defer conn.Close()
+
+	grpcClient := pb.NewKnotidxClient(conn)
+	var res proto.Message
+	switch cmd {
+	case "status":
+		res, err = grpcClient.Status(context.Background(), &pb.EmptyRequest{})
+	case "reindex":
+		res, err = grpcClient.Reindex(context.Background(), &pb.IndexerRequest{Path: path})
+	case "cancel":
+		res, err = grpcClient.CancelIndexing(context.Background(), &pb.IndexerRequest{Path: path})
+	case "add":
+		res, err = grpcClient.AddIndexer(context.Background(), &pb.AddIndexerRequest{
+			Indexer: &pb.IndexerConfig{Path: absPath(path), Notify: *notifyCmd},
+			Persist: *persistCmd,
+		})
+	case "remove":
+		res, err = grpcClient.RemoveIndexer(context.Background(), &pb.RemoveIndexerRequest{Path: absPath(path), Persist: *persistCmd})
+	case "list":
+		res, err = grpcClient.ListIndexers(context.Background(), &pb.EmptyRequest{})
+	case "reload":
+		res, err = grpcClient.Reload(context.Background(), &pb.EmptyRequest{})
+	default:
+		return 1, fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		return 1, err
+	}
+
+	if *jsonCmd {
+		jr, err := marshalMessage(res)
+		if err != nil {
+			return 1, err
+		}
+		fmt.Println(string(jr))
+		return 0, nil
+	}
+	switch res := res.(type) {
+	case *pb.StatusResponse:
+		printStatus(os.Stdout, res)
+	case *pb.IndexersResponse:
+		for _, idx := range res.GetIndexers() {
+			printIndexer(os.Stdout, idx)
+		}
+	case *pb.RemoveIndexerResponse:
+		if idx := res.GetIndexer(); idx != nil {
+			printIndexer(os.Stdout, idx)
+		}
+		fmt.Printf("Deleted %d items\n", res.GetDeleted())
+	case *pb.ListIndexersResponse:
+		for _, e := range res.GetIndexers() {
+			status := "not started"
+			if st := e.GetStatus(); st != nil {
+				status = st.GetStatus()
+			}
+			fmt.Printf("%s\t%s\tnotify=%t\t%s\n", e.GetConfig().GetType(), e.GetConfig().GetPath(), e.GetConfig().GetNotify(), status)
+		}
+	case *pb.ReloadResponse:
+		printReload(os.Stdout, res)
+	}
+	return 0, nil
+}
+
+// absPath returns the absolute path of the command argument, the daemon may
+// run in another directory.
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// printStatus writes the human-readable view of the daemon status.
+func printStatus(w io.Writer, st *pb.StatusResponse) {
+	if sc := st.GetScheduler(); sc != nil {
+		fmt.Fprintln(w, "Scheduler")
+		fmt.Fprintf(w, "  last trigger: %s\n", formatUnix(sc.GetLastTriggerTime()))
+		fmt.Fprintf(w, "  interval:     %s\n", time.Duration(sc.GetInterval())*time.Second)
+		fmt.Fprintf(w, "  idle:         %.1f%% (threshold %.1f%%)\n", sc.GetIdle(), sc.GetIdleThreshold())
+	}
+
+	if ss := st.GetStore(); ss != nil {
+		fmt.Fprintln(w, "Store")
+		fmt.Fprintf(w, "  type:         %s %s\n", ss.GetType(), ss.GetInfo())
+		fmt.Fprintf(w, "  keys:         ~%d\n", ss.GetKeys())
+		fmt.Fprintf(w, "  size:         %s (lsm %s, vlog %s)\n",
+			formatBytes(ss.GetSize()), formatBytes(ss.GetLsmSize()), formatBytes(ss.GetVlogSize()))
+	}
+
+	for _, idx := range st.GetIndexers() {
+		printIndexer(w, idx)
+	}
+}
+
+// printIndexer writes the human-readable view of the indexer status.
+func printIndexer(w io.Writer, idx *pb.IndexerStatus) {
+	fmt.Fprintf(w, "Indexer %s %s\n", idx.GetType(), idx.GetRoot())
+	fmt.Fprintf(w, "  status:       %s\n", idx.GetStatus())
+	fmt.Fprintf(w, "  started:      %s\n", formatUnix(idx.GetStartTime()))
+	fmt.Fprintf(w, "  finished:     %s\n", formatUnix(idx.GetFinishTime()))
+	fmt.Fprintf(w, "  duration:     %s\n", time.Duration(idx.GetDuration())*time.Millisecond)
+	fmt.Fprintf(w, "  items:        %d dirs, %d files, %d failed, %d total\n",
+		idx.GetDirs(), idx.GetFiles(), idx.GetFailed(), idx.GetTotal())
+	fmt.Fprintf(w, "  update:       %d updated, %d skipped, %d removed\n",
+		idx.GetUpdated(), idx.GetSkipped(), idx.GetRemoved())
+	wt := idx.GetWatcher()
+	if !wt.GetEnabled() {
+		fmt.Fprintln(w, "  watcher:      disabled")
+		return
+	}
+	fmt.Fprintf(w, "  watcher:      %d watches, %d unwatched\n", wt.GetWatches(), len(wt.GetUnwatched()))
+	for _, dir := range wt.GetUnwatched() {
+		fmt.Fprintf(w, "    %s\n", dir)
+	}
+}
+
+// printReload writes the changes applied by the config reload.
+func printReload(w io.Writer, res *pb.ReloadResponse) {
+	if res.GetInterval() {
+		fmt.Fprintln(w, "Scheduler interval changed")
+	}
+	if res.GetStore() {
+		fmt.Fprintln(w, "Store reopened")
+	}
+	if res.GetGrpc() {
+		fmt.Fprintln(w, "GRPC server restarted")
+	}
+	for _, c := range res.GetAdded() {
+		fmt.Fprintf(w, "Added indexer %s %s\n", c.GetType(), c.GetPath())
+	}
+	for _, c := range res.GetRemoved() {
+		fmt.Fprintf(w, "Removed indexer %s %s\n", c.GetType(), c.GetPath())
+	}
+	for _, c := range res.GetChanged() {
+		fmt.Fprintf(w, "Changed indexer %s %s\n", c.GetType(), c.GetPath())
+	}
+	if !res.GetInterval() && !res.GetStore() && !res.GetGrpc() &&
+		len(res.GetAdded())+len(res.GetRemoved())+len(res.GetChanged()) == 0 {
+		fmt.Fprintln(w, "Config unchanged")
+	}
+}
+
+// formatUnix formats the unix time in seconds, "never" for 0.
+func formatUnix(sec int64) string {
+	if sec == 0 {
+		return "never"
+	}
+	return time.Unix(sec, 0).Format(time.DateTime)
+}
+
+// formatBytes formats the size in bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shtirlic/knotidx/internal/pb"
+)
+
+func TestMarshalResults(t *testing.T) {
+	b, err := marshalResults(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[]" {
+		t.Errorf("no results marshaled to %s, want []", b)
+	}
+
+	b, err = marshalResults([]*pb.SearchItemResponse{{
+		Key:        "fs_file_/a.txt",
+		Item:       &pb.Item{Path: "/a.txt", Size: 3},
+		Highlights: []*pb.Range{{Start: 1, End: 2}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("bad JSON %s: %v", b, err)
+	}
+	if len(got) != 1 || got[0]["key"] != "fs_file_/a.txt" {
+		t.Fatalf("got %s, want the result", b)
+	}
+	if item, _ := got[0]["item"].(map[string]any); item["path"] != "/a.txt" {
+		t.Errorf("got item %v, want the item under its proto field names", got[0]["item"])
+	}
+	if _, ok := got[0]["highlights"].([]any); !ok {
+		t.Errorf("got %s, want the highlights", b)
+	}
+}
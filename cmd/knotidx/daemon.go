@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"sync"
 	"time"
 
@@ -34,17 +36,357 @@ type Daemon struct {
 	config          config.Config
 	store           store.Store
 	grpcServer      *GRPServer
-	feedback        sync.Map
+	mu              sync.Mutex             // Protects lastTriggerTime, jobs, store, grpcServer and config changes.
+	jobs            []*job                 // Indexers started per configured path, in config order.
+	jobsMu          sync.Mutex             // Serializes starting and canceling jobs.
+	reloads         chan chan reloadResult // Reload requests handled by the main loop.
+}
+
+// reloadResult represents the result of a config reload.
+type reloadResult struct {
+	diff config.Diff
+	err  error
+}
+
+// job represents an indexer started by the daemon, updating the index and
+// watching for changes until canceled.
+type job struct {
+	key      string // Indexer type and configured path.
+	idx      indexer.Indexer
+	cancel   context.CancelFunc
+	updated  chan struct{}  // Closed when the update of the index is done.
+	wg       sync.WaitGroup // Update and watcher goroutines.
+	canceled bool           // Canceled by a request, not restarted by the scheduler. Protected by jobsMu.
+}
+
+// updating reports whether the job is still updating the index.
+func (j *job) updating() bool {
+	select {
+	case <-j.updated:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop cancels the job and waits for its goroutines.
+func (j *job) stop() {
+	j.cancel()
+	j.wg.Wait()
+}
+
+// Errors of the indexer control requests.
+var (
+	errNoIndexers     = errors.New("no matching indexers")
+	errIndexerExists  = errors.New("indexer already exists")
+	errInvalidIndexer = errors.New("invalid indexer")
+)
+
+// ConfiguredIndexer represents a configured indexer path with its indexer.
+type ConfiguredIndexer struct {
+	Config  config.IndexerConfig // Config of the indexer with its single path.
+	Indexer indexer.Indexer      // Started indexer, nil if not started.
+}
+
+// DaemonStatus represents the state of the daemon indexers and scheduler.
+type DaemonStatus struct {
+	Indexers        []indexer.Indexer // Indexers started per configured path.
+	LastTriggerTime time.Time         // Time of the last indexing job, zero if none.
+	Interval        int               // Scheduler interval in seconds.
+	Idle            float64           // System idle percentage.
+	IdleThreshold   float64           // Idle percentage triggering indexing jobs.
 }
 
 func NewDaemon(c config.Config, s store.Store) *Daemon {
-	return &Daemon{
+	d := &Daemon{
 		config:          c,
 		store:           s,
 		lastTriggerTime: time.UnixMicro(0),
 		grpcServer:      NewGRPCServer(c, s),
-		feedback:        sync.Map{},
+		reloads:         make(chan chan reloadResult),
+	}
+	d.grpcServer.daemon = d
+	return d
+}
+
+// Status returns the state of the daemon indexers and scheduler.
+func (d *Daemon) Status() DaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := DaemonStatus{
+		Interval:      d.config.Interval,
+		Idle:          idle.Idle(),
+		IdleThreshold: idleThreshold,
 	}
+	for _, j := range d.jobs {
+		st.Indexers = append(st.Indexers, j.idx)
+	}
+	if d.lastTriggerTime != time.UnixMicro(0) {
+		st.LastTriggerTime = d.lastTriggerTime
+	}
+	return st
+}
+
+// Store returns the store of the indexers and the gRPC server.
+func (d *Daemon) Store() store.Store {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.store
+}
+
+// server returns the gRPC server, replaced by reloads.
+func (d *Daemon) server() *GRPServer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.grpcServer
+}
+
+// interval returns the scheduler interval in seconds.
+func (d *Daemon) interval() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.config.Interval
+}
+
+// ResetScheduler resets the scheduler to the configured interval, triggering
+// the indexing job on the next tick.
+func (d *Daemon) ResetScheduler() {
+	d.resetScheduler(d.interval())
+}
+
+// Reload reloads the config file in the main loop and returns the applied
+// changes, see reload.
+func (d *Daemon) Reload(ctx context.Context) (config.Diff, error) {
+	res := make(chan reloadResult, 1)
+	select {
+	case d.reloads <- res:
+	case <-ctx.Done():
+		return config.Diff{}, ctx.Err()
+	case <-d.cancelContext.Done():
+		return config.Diff{}, context.Cause(d.cancelContext)
+	}
+	select {
+	case r := <-res:
+		return r.diff, r.err
+	case <-ctx.Done():
+		return config.Diff{}, ctx.Err()
+	}
+}
+
+// Reindex restarts the configured indexers of the path and type, canceling
+// their running updates. An empty path or type matches all indexers.
+func (d *Daemon) Reindex(path, typ string) ([]indexer.Indexer, error) {
+	started := d.reindex(path, typ, true)
+	if len(started) == 0 {
+		return nil, errNoIndexers
+	}
+	return started, nil
+}
+
+// CancelIndexing stops the running indexers of the path and type, with their
+// watchers, until reindexed. The scheduler doesn't restart them. An empty path
+// or type matches all indexers.
+func (d *Daemon) CancelIndexing(path, typ string) ([]indexer.Indexer, error) {
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+
+	d.mu.Lock()
+	jobs := slices.Clone(d.jobs)
+	d.mu.Unlock()
+
+	var canceled []indexer.Indexer
+	for _, j := range jobs {
+		c := j.idx.Config()
+		if !matchIndexer(c.Type, j.idx.Root(), path, typ) {
+			continue
+		}
+		slog.Info("Canceling indexer", "type", c.Type, "root", j.idx.Root())
+		j.stop()
+		j.canceled = true
+		canceled = append(canceled, j.idx)
+	}
+	if len(canceled) == 0 {
+		return nil, errNoIndexers
+	}
+	return canceled, nil
+}
+
+// AddIndexer adds the fs indexer of the config path to the daemon config and
+// starts it, writing it to the config file too if persist is set.
+func (d *Daemon) AddIndexer(c config.IndexerConfig, persist bool) (indexer.Indexer, error) {
+	if c.Type == "" {
+		c.Type = string(indexer.FileSystemIndexerType)
+	}
+	if c.Type != string(indexer.FileSystemIndexerType) || len(c.Paths) != 1 {
+		return nil, fmt.Errorf("%w: only fs indexers of a single path can be added", errInvalidIndexer)
+	}
+	root := indexer.RootPath(indexer.FileSystemIndexerType, c.Paths[0])
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("%w: path %s is not absolute", errInvalidIndexer, root)
+	}
+	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("%w: path %s is not a directory", errInvalidIndexer, root)
+	}
+	c.Paths = []string{root}
+
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	if d.hasIndexer(c.Type, root) {
+		return nil, fmt.Errorf("%w: %s", errIndexerExists, root)
+	}
+	if persist {
+		if err := config.AddIndexer(*configCmd, c); err != nil {
+			return nil, err
+		}
+	}
+	d.mu.Lock()
+	d.config.Indexer = append(slices.Clip(d.config.Indexer), c)
+	d.mu.Unlock()
+
+	slog.Info("Adding indexer", "type", c.Type, "root", root, "persist", persist)
+	return d.startIndexer(c, root, true), nil
+}
+
+// RemoveIndexer stops the fs indexer of the path with its watcher, removes it
+// from the daemon config and deletes its items from the store, writing the
+// config file too if persist is set. Items of other indexers containing or
+// nested in the path are kept. It returns the stopped indexer, nil if it
+// wasn't started, and the number of deleted items.
+func (d *Daemon) RemoveIndexer(path string, persist bool) (indexer.Indexer, int, error) {
+	typ := string(indexer.FileSystemIndexerType)
+	root := indexer.RootPath(indexer.FileSystemIndexerType, path)
+
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	if !d.hasIndexer(typ, root) {
+		return nil, 0, fmt.Errorf("%w: %s", errNoIndexers, root)
+	}
+	if persist {
+		if err := config.EditIndexers(*configCmd, func(ic config.IndexerConfig) (config.IndexerConfig, bool) {
+			res := withoutIndexer([]config.IndexerConfig{ic}, typ, root)
+			if len(res) == 0 {
+				return ic, false
+			}
+			return res[0], true
+		}); err != nil {
+			return nil, 0, err
+		}
+	}
+	d.mu.Lock()
+	d.config.Indexer = withoutIndexer(d.config.Indexer, typ, root)
+	d.mu.Unlock()
+
+	slog.Info("Removing indexer", "type", typ, "root", root, "persist", persist)
+	idx := d.stopJob(typ + ":" + root)
+	d.forget(indexer.IndexerType(typ), root)
+	deleted, err := d.deleteItems(root)
+	return idx, deleted, err
+}
+
+// forget deletes the state saved in the store by the indexers of the type and root.
+func (d *Daemon) forget(t indexer.IndexerType, root string) {
+	if err := indexer.Forget(d.Store(), t, root); err != nil {
+		slog.Error("Can't delete indexer state", "type", t, "root", root, "error", err)
+	}
+}
+
+// stopJob stops the job of the key and drops it, returning its indexer, nil if none.
+func (d *Daemon) stopJob(key string) indexer.Indexer {
+	j := d.job(key)
+	if j == nil {
+		return nil
+	}
+	j.stop()
+	d.mu.Lock()
+	d.jobs = slices.DeleteFunc(d.jobs, func(j *job) bool { return j.key == key })
+	d.mu.Unlock()
+	return j.idx
+}
+
+// deleteItems deletes the items of the removed fs indexer root from the store,
+// keeping the items of the configured fs indexers containing or nested in it.
+// It returns the number of deleted items.
+func (d *Daemon) deleteItems(root string) (int, error) {
+	var keep []string
+	for _, c := range d.config.Indexer {
+		if c.Type != string(indexer.FileSystemIndexerType) {
+			continue
+		}
+		for _, p := range c.Paths {
+			r := indexer.RootPath(indexer.FileSystemIndexerType, p)
+			if indexer.Within(root, r) {
+				return 0, nil
+			}
+			if indexer.Within(r, root) {
+				keep = append(keep, r)
+			}
+		}
+	}
+	return indexer.DeleteFileSystemIndex(d.store, root, keep)
+}
+
+// ListIndexers returns the configured indexers per path, in config order,
+// with their started indexers.
+func (d *Daemon) ListIndexers() []ConfiguredIndexer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var list []ConfiguredIndexer
+	for _, c := range d.config.IndexerPaths() {
+		ci := ConfiguredIndexer{Config: c}
+		key := c.Type + ":" + indexer.RootPath(indexer.IndexerType(c.Type), c.Paths[0])
+		if i := slices.IndexFunc(d.jobs, func(j *job) bool { return j.key == key }); i >= 0 {
+			ci.Indexer = d.jobs[i].idx
+		}
+		list = append(list, ci)
+	}
+	return list
+}
+
+// hasIndexer reports whether an indexer of the type and root is configured.
+func (d *Daemon) hasIndexer(typ, root string) bool {
+	for _, c := range d.config.Indexer {
+		if c.Type != typ {
+			continue
+		}
+		for _, p := range c.Paths {
+			if indexer.RootPath(indexer.IndexerType(typ), p) == root {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutIndexer returns the indexer configs without the path of the type
+// and root, dropping the configs left without paths.
+func withoutIndexer(idxConfigs []config.IndexerConfig, typ, root string) []config.IndexerConfig {
+	var res []config.IndexerConfig
+	for _, c := range idxConfigs {
+		if c.Type == typ {
+			paths := slices.DeleteFunc(slices.Clone(c.Paths), func(p string) bool {
+				return indexer.RootPath(indexer.IndexerType(typ), p) == root
+			})
+			if len(paths) == 0 && len(c.Paths) > 0 {
+				continue
+			}
+			c.Paths = paths
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// matchIndexer reports whether the indexer of the type and root matches the
+// requested path and type. The path matches its indexer roots and the roots
+// of its parents, an empty path or type matches all.
+func matchIndexer(idxType, root, path, typ string) bool {
+	if typ != "" && typ != idxType {
+		return false
+	}
+	if path == "" {
+		return true
+	}
+	return indexer.Within(filepath.Clean(path), root)
 }
 
 // stopTicker stops the background ticker
@@ -69,8 +411,8 @@ func (d *Daemon) ShutDown() {
 	// slog.Debug("Shutdown", "phase", "waitjobs")
 	d.waitJobs() // Wait for all indexers jobs to finish
 	// slog.Debug("Shutdown", "phase", "stopticker")
-	d.stopTicker()      // Stop the background ticker
-	d.grpcServer.Stop() // Stop the gRPC server
+	d.stopTicker()    // Stop the background ticker
+	d.server().Stop() // Stop the gRPC server
 }
 
 // daemonStart initializes and starts the knotidx daemon.
@@ -88,10 +430,10 @@ func (d *Daemon) Start() (int, error) {
 	sigCh := d.watchSignals()
 
 	// Start background ticker
-	d.ticker = d.newTicker(time.Duration(d.config.Interval))
+	d.ticker = d.newTicker(time.Duration(d.interval()))
 
 	// Start gRPC server in a goroutine
-	go d.grpcServer.Start()
+	go d.server().Start()
 
 	var daemonErr error
 	var daemonExitCode int
@@ -102,6 +444,10 @@ func (d *Daemon) Start() (int, error) {
 		case <-d.ticker.C:
 			// Periodic work
 			d.tick()
+		case res := <-d.reloads:
+			// Reload requested over gRPC
+			diff, err := d.reload()
+			res <- reloadResult{diff, err}
 		case sig := <-sigCh:
 			// Handle received signals
 			_, exit, err := d.handleSginal(sig)
@@ -126,23 +472,26 @@ func (d *Daemon) newTicker(t time.Duration) *time.Ticker {
 // tick is a function called during each tick of the background ticker.
 // It logs information about the interval and triggers scheduled work.
 func (d *Daemon) tick() {
-	slog.Debug("Got work?", "interval", d.config.Interval)
+	slog.Debug("Got work?", "interval", d.interval())
 	d.scheduleWork()
-	d.getFeedback()
 }
 
-func (d *Daemon) getFeedback() {
-	d.feedback.Range(func(i, ch any) bool {
-		slog.Debug("Feedback:", "recieving", i)
-		s, ok := <-ch.(chan indexer.IndexerRuntimeInfo)
-		if !ok {
-			slog.Debug("Feedback:", "closed", i)
-			return true
+// getFeedback logs the progress reported by the indexer of the job until its
+// update is done.
+func (d *Daemon) getFeedback(j *job) {
+	ch := j.idx.Feedback()
+	for {
+		select {
+		case info, ok := <-ch:
+			if !ok {
+				slog.Debug("Feedback:", "closed", j.key)
+				return
+			}
+			slog.Debug("Feedback:", "key", j.key, "msg", info)
+		case <-j.updated:
+			return
 		}
-		slog.Debug("Feedback:", "Num", i, "msg", s)
-		// ch.(chan string) <- ""
-		return true
-	})
+	}
 }
 
 // scheduleWork is responsible for scheduling and triggering background work based on specified conditions.
@@ -158,69 +507,150 @@ func (d *Daemon) scheduleWork() {
 
 	slog.Debug("Load AVG", "load", idle.SysinfoAvg())
 	slog.Debug("Idle time", "idle", idleTime)
-	slog.Debug("Last work was at:", "date", d.lastTriggerTime)
+	d.mu.Lock()
+	lastTriggerTime := d.lastTriggerTime
+	d.mu.Unlock()
+	slog.Debug("Last work was at:", "date", lastTriggerTime)
 
 	// Check if the conditions for triggering work are met
-	if (idleTime >= idleThreshold && time.Since(d.lastTriggerTime) >= triggerInterval) || time.UnixMicro(0) == d.lastTriggerTime {
+	if (idleTime >= idleThreshold && time.Since(lastTriggerTime) >= triggerInterval) || time.UnixMicro(0) == lastTriggerTime {
 
-		// Wait for any unfinished jobs before scheduling new ones
-		d.waitJobs()
 		// Update the last trigger time to the current time
+		d.mu.Lock()
 		d.lastTriggerTime = time.Now()
-		slog.Info("Start addIndexers job", "time", d.lastTriggerTime, d.store)
+		d.mu.Unlock()
+		slog.Info("Start addIndexers job", "time", time.Now(), "store", d.Store().Info())
 		// Start the addIndexers job
 		d.addIndexers()
 	}
 }
 
 // addWatcher is responsible for adding a watcher to the specified indexer and managing its lifecycle.
-func (d *Daemon) addWatcher(idx indexer.Indexer) {
+func (d *Daemon) addWatcher(j *job) {
 	defer d.wg.Done()
+	defer j.wg.Done()
 
 	// Start the watcher for the indexer
-	idx.Watch()
+	j.idx.Watch()
 }
 
 // addToIndex is responsible for adding items to the index using the specified indexer.
-func (d *Daemon) addToIndex(idx indexer.Indexer, i int) {
+func (d *Daemon) addToIndex(j *job) {
 	defer d.wg.Done()
-	defer func() {
-		d.feedback.Delete(i)
-		slog.Debug("addToIndex", "map chan cleared", i)
-	}()
+	defer j.wg.Done()
+	defer close(j.updated)
+	go d.getFeedback(j)
 
+	idx := j.idx
 	slog.Info("Starting updateIndex", "config", idx.Config())
 	// Attempt to update the index using the specified indexer
 	td, err := idx.UpdateIndex()
 	if err != nil {
 		slog.Error("new index failed", "error", err, "indexer", idx)
 	}
-	slog.Info("Finished updateIndex", "duration", td, "config", idx.Config())
+	info := idx.Info()
+	slog.Info("Finished updateIndex", "duration", td, "updated", info.Updated,
+		"skipped", info.Skipped, "removed", info.Removed, "watches", info.Watches,
+		"unwatched", len(info.Unwatched), "config", idx.Config())
 }
 
 // addIndexers creates and starts indexers and watchers for each configuration in idxc.
 // It launches goroutines to update the index and watch for changes in the background.
+// Indexers still updating the index are left running.
 func (d *Daemon) addIndexers() {
+	d.mu.Lock()
+	n := len(d.config.Indexer)
+	d.mu.Unlock()
+	slog.Debug("Indexers", "idx count", n)
+	d.reindex("", "", false)
+}
 
-	slog.Debug("Indexers", "idx count", len(d.config.Indexer))
+// reindex restarts the configured indexers of the path and type, returning
+// the started ones. Running updates are canceled if force is set, or else
+// their indexers are left running. The config is only changed with jobsMu
+// held, so it's read without mu.
+func (d *Daemon) reindex(path, typ string, force bool) []indexer.Indexer {
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
 
+	var started []indexer.Indexer
 	// Iterate over each indexer configuration
-	for i, idxConfig := range d.config.Indexer {
-		// Create indexers based on the configuration
-		for _, idx := range indexer.NewIndexers(d.cancelContext, idxConfig, d.store) {
+	for _, idxConfig := range d.config.Indexer {
+		for _, p := range idxConfig.Paths {
+			root := indexer.RootPath(indexer.IndexerType(idxConfig.Type), p)
+			if !matchIndexer(idxConfig.Type, root, path, typ) {
+				continue
+			}
+			if idx := d.startIndexer(idxConfig, p, force); idx != nil {
+				started = append(started, idx)
+			}
+		}
+	}
+	return started
+}
 
-			// Save feedback channel in sync.map
-			d.feedback.Store(i, idx.Feedback())
+// startIndexer starts the indexer of the configured path, replacing its job.
+// Running updates are canceled if force is set, or else the indexer is left
+// running and nil is returned, as are canceled indexers.
+func (d *Daemon) startIndexer(idxConfig config.IndexerConfig, p string, force bool) indexer.Indexer {
+	key := idxConfig.Type + ":" + indexer.RootPath(indexer.IndexerType(idxConfig.Type), p)
+	if old := d.job(key); old != nil {
+		if !force && old.canceled {
+			slog.Debug("Indexer is canceled, skipping", "key", key)
+			return nil
+		}
+		if !force && old.updating() {
+			slog.Info("Indexer is still updating, skipping", "key", key)
+			return nil
+		}
+		old.stop()
+	}
 
-			// Launch a goroutine to update the indexs
-			d.wg.Add(1)
-			go d.addToIndex(idx, i)
+	// Create the indexer of the path, canceled with its job.
+	c := idxConfig
+	c.Paths = []string{p}
+	ctx, cancel := context.WithCancel(d.cancelContext)
+	idxs := indexer.NewIndexers(ctx, c, d.store)
+	if len(idxs) == 0 {
+		cancel()
+		return nil
+	}
+	j := &job{key: key, idx: idxs[0], cancel: cancel, updated: make(chan struct{})}
+	d.startJob(j)
+	return j.idx
+}
 
-			// Launch a goroutine to watch for changes
-			d.wg.Add(1)
-			go d.addWatcher(idx)
+// job returns the job of the key, nil if none.
+func (d *Daemon) job(key string) *job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, j := range d.jobs {
+		if j.key == key {
+			return j
 		}
 	}
+	return nil
+}
+
+// startJob launches the goroutines of the job, replacing the job of its key.
+func (d *Daemon) startJob(j *job) {
+	d.mu.Lock()
+	if i := slices.IndexFunc(d.jobs, func(old *job) bool { return old.key == j.key }); i >= 0 {
+		d.jobs[i] = j
+	} else {
+		d.jobs = append(d.jobs, j)
+	}
+	d.mu.Unlock()
+
+	// Launch a goroutine to update the indexs
+	d.wg.Add(1)
+	j.wg.Add(1)
+	go d.addToIndex(j)
+
+	// Launch a goroutine to watch for changes
+	d.wg.Add(1)
+	j.wg.Add(1)
+	go d.addWatcher(j)
 }
 
 // resetScheduler resets the scheduler by updating the ticker interval and resetting the lastTriggerTime.
@@ -230,7 +660,9 @@ func (d *Daemon) resetScheduler(in int) {
 	// Reset the ticker to the new interval
 	d.ticker.Reset(time.Second * time.Duration(in))
 	// Reset the last background run time
+	d.mu.Lock()
 	d.lastTriggerTime = time.UnixMicro(0)
+	d.mu.Unlock()
 }
 
 // getExitCode calculates the exit code based on the received OS signal.
@@ -241,44 +673,191 @@ func (d *Daemon) getExitCode(sig os.Signal) int {
 }
 
 // handleHUP handles the SIGHUP signal, typically used for reloading configurations.
-// It initiates a graceful shutdown of the daemon, closes the store, performs necessary cleanup,
-// and then restarts the daemon with the updated configuration.
-// If successful, it returns handled as true, exit as 0, and an error if any occurred.
+// It reloads the config file and applies the changes to the running daemon.
+// If the config can't be loaded, the daemon keeps running with the current one.
+// It returns handled as true and exit as 0.
 func (d *Daemon) handleHUP(sig os.Signal) (handled bool, exit int, err error) {
+	// Capture memory profile
+	memprofile()
+
+	if _, err := d.reload(); err != nil {
+		slog.Error("Reload failed, keeping the current config", "signal", sig, "error", err)
+	}
+	return true, 0, nil
+}
+
+// reload loads the config file and applies its changes to the running config.
+// Indexers of added, removed or changed paths are started, stopped or
+// restarted, and the items of removed fs indexers are deleted. The store is
+// reopened and all indexers restarted if the store config changed, and the
+// gRPC server is restarted if its config changed, or else given the reopened
+// store. Unchanged indexers keep running. It must be called by the main loop.
+// The current config is kept on errors.
+func (d *Daemon) reload() (config.Diff, error) {
 	slog.Info("Reloading...")
-	handled = false
-	exit = d.getExitCode(sig)
+	c, err := reloadConfig()
+	if err != nil {
+		return config.Diff{}, err
+	}
 
-	// Gracefully shut down the daemon
-	d.ShutDown()
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	diff := d.config.Diff(c)
+	if diff.Empty() {
+		slog.Info("Reload complete, config unchanged")
+		return diff, nil
+	}
 
-	// Close the store
-	if err = d.store.Close(); err != nil {
-		return
+	// Open the new store before stopping anything, keeping the current one on
+	// errors. A store of the same directory can't be opened while the current
+	// one locks it, so it's reopened in place once the indexers are stopped.
+	var oldStore store.Store
+	if diff.Store {
+		inPlace := sameStoreDir(d.config.Store, c.Store)
+		var s store.Store
+		if !inPlace {
+			if s, err = newStore(c.Store); err != nil {
+				return config.Diff{}, err
+			}
+		}
+		// Stop the indexers of the current store, closed once unused.
+		d.mu.Lock()
+		jobs := slices.Clone(d.jobs)
+		d.mu.Unlock()
+		for _, j := range jobs {
+			d.stopJob(j.key)
+		}
+		if inPlace {
+			s, err = d.reopenStore(c.Store)
+		} else {
+			oldStore = d.store
+		}
+		d.mu.Lock()
+		d.store = s
+		d.mu.Unlock()
+		if err != nil {
+			// Restart the indexers with the store of the current config.
+			for _, ic := range d.config.IndexerPaths() {
+				d.startIndexer(ic, ic.Paths[0], true)
+			}
+			return config.Diff{}, err
+		}
 	}
 
-	// Capture memory profile
-	memprofile()
+	d.mu.Lock()
+	d.config = c
+	d.mu.Unlock()
 
-	// Restart the daemon with the updated configuration
-	if d.config, d.store, err = loadUp(); err != nil {
-		return
+	if diff.Store {
+		// Restart all indexers with the new store.
+		for _, ic := range c.IndexerPaths() {
+			d.startIndexer(ic, ic.Paths[0], true)
+		}
+	} else {
+		for _, ic := range diff.Removed {
+			root := indexer.RootPath(indexer.IndexerType(ic.Type), ic.Paths[0])
+			d.stopJob(ic.Type + ":" + root)
+			d.forget(indexer.IndexerType(ic.Type), root)
+			if ic.Type != string(indexer.FileSystemIndexerType) {
+				continue
+			}
+			if _, err := d.deleteItems(root); err != nil {
+				slog.Error("Can't delete items of removed indexer", "root", root, "error", err)
+			}
+		}
+		// Drop the state of changed indexers, restarted in place.
+		for _, ic := range diff.Changed {
+			t := indexer.IndexerType(ic.Type)
+			root := indexer.RootPath(t, ic.Paths[0])
+			if j := d.job(ic.Type + ":" + root); j != nil {
+				j.stop()
+			}
+			d.forget(t, root)
+		}
+		for _, ic := range slices.Concat(diff.Changed, diff.Added) {
+			d.startIndexer(ic, ic.Paths[0], true)
+		}
 	}
 
-	d.cancelContext, d.cancelJobs = context.WithCancelCause(context.Background())
+	if diff.Interval {
+		d.ticker.Reset(time.Second * time.Duration(c.Interval))
+	}
+	switch {
+	case diff.GRPC:
+		d.restartGRPC(oldStore)
+	case oldStore != nil:
+		// Keep the listener, closing the replaced store after its requests.
+		wait := d.server().SetStore(d.Store())
+		go func() {
+			wait()
+			closeStore(oldStore)
+		}()
+	}
 
-	// Reset the scheduler with the new interval
-	d.resetScheduler(d.config.Interval)
+	slog.Info("Reload complete", "interval", diff.Interval, "grpc", diff.GRPC, "store", diff.Store,
+		"added", len(diff.Added), "removed", len(diff.Removed), "changed", len(diff.Changed))
+	return diff, nil
+}
 
-	// Start the gRPC server in a new goroutine
-	go d.grpcServer.Start()
+// restartGRPC replaces the gRPC server by one of the running config and
+// store. The replaced server is stopped in the background after its pending
+// requests, e.g. the reload request, then the replaced store is closed, if
+// any, and the new server started.
+func (d *Daemon) restartGRPC(oldStore store.Store) {
+	d.mu.Lock()
+	old := d.grpcServer
+	srv := NewGRPCServer(d.config, d.store)
+	srv.daemon = d
+	d.grpcServer = srv
+	d.mu.Unlock()
+	go func() {
+		old.Stop()
+		if oldStore != nil {
+			closeStore(oldStore)
+		}
+		srv.Start()
+	}()
+}
 
-	handled = true
-	exit = 0
-	slog.Info("Reload complete")
+// reopenStore closes the store and opens the one of the config in the same
+// directory once the gRPC requests using it are done, new requests waiting
+// for the reopened store. The indexers must be stopped. If the store of the
+// config can't be opened, the store of the running config is reopened and
+// returned with the error.
+func (d *Daemon) reopenStore(c config.StoreConfig) (s store.Store, err error) {
+	d.server().ReplaceStore(func(old store.Store) store.Store {
+		closeStore(old)
+		if s, err = newStore(c); err == nil {
+			return s
+		}
+		var rerr error
+		if s, rerr = newStore(d.config.Store); rerr != nil {
+			slog.Error("Can't reopen the store", "error", rerr)
+			s = old
+		}
+		return s
+	})
 	return
 }
 
+// sameStoreDir reports whether the store configs use the same directory,
+// which only one store can open at a time.
+func sameStoreDir(a, b config.StoreConfig) bool {
+	if a.Path == "" || b.Path == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a.Path)
+	absB, errB := filepath.Abs(b.Path)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// closeStore closes the store replaced by a reload.
+func closeStore(s store.Store) {
+	if err := s.Close(); err != nil {
+		slog.Error("Can't close the store", "error", err)
+	}
+}
+
 // handleQuit handles termination signals (SIGINT, SIGTERM, SIGQUIT).
 func (d *Daemon) handleQuit(sig os.Signal) (bool, int, error) {
 	// exit := getExitCode(sig)
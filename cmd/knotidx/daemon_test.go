@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/indexer"
+	"github.com/shtirlic/knotidx/internal/pb"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// writeConfig writes the config file.
+func writeConfig(t *testing.T, path string, c config.Config) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestDaemon returns a daemon of the config, written to the config file
+// read by reloads, with its jobs stopped at the end of the test.
+func newTestDaemon(t *testing.T, c config.Config) (*Daemon, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "knotidx.toml")
+	writeConfig(t, path, c)
+	old := *configCmd
+	*configCmd = path
+	t.Cleanup(func() { *configCmd = old })
+
+	s, err := newStore(c.Store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDaemon(c, s)
+	d.cancelContext, d.cancelJobs = context.WithCancelCause(context.Background())
+	d.ticker = d.newTicker(time.Duration(c.Interval))
+	t.Cleanup(func() {
+		d.cancelJobs(nil)
+		d.waitJobs()
+		d.stopTicker()
+		d.Store().Close()
+	})
+	return d, path
+}
+
+// waitKeys waits for the store of the daemon to have the number of keys with the prefix.
+func waitKeys(t *testing.T, d *Daemon, prefix string, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		keys := d.Store().Keys(prefix, "", 0)
+		if len(keys) == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got keys %v, want %d", keys, n)
+		}
+	}
+}
+
+func TestReloadStoreInPlace(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	other := t.TempDir()
+	c := config.DefaultConfig()
+	c.GRPC.Server = false
+	c.Store.Path = filepath.Join(t.TempDir(), "store")
+	c.Indexer = []config.IndexerConfig{{Type: string(indexer.FileSystemIndexerType), Paths: []string{root}}}
+	d, path := newTestDaemon(t, c)
+	d.addIndexers()
+	waitKeys(t, d, "fs_file_", 1)
+
+	// Use the daemon and its store from other goroutines while reloading,
+	// like gRPC requests, to check the locking with the race detector.
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			d.Status()
+			d.ListIndexers()
+			if _, err := d.server().Status(context.Background(), &pb.EmptyRequest{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			ic := config.IndexerConfig{Paths: []string{other}}
+			if _, err := d.AddIndexer(ic, false); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, _, err := d.RemoveIndexer(other, false); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	// The same store directory is reopened in place.
+	c.Store.Path += "/"
+	c.Interval++
+	writeConfig(t, path, c)
+	old := d.Store()
+	d.tick()
+	diff, err := d.reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Store || !diff.Interval {
+		t.Errorf("got diff %+v, want store and interval changes", diff)
+	}
+	if d.Store() == old {
+		t.Error("store not reopened")
+	}
+	d.tick()
+
+	// The store of the running config is reopened if the new one fails.
+	c.Store.Type = "unknown"
+	writeConfig(t, path, c)
+	if _, err := d.reload(); err == nil {
+		t.Error("reload of an unknown store type succeeded")
+	}
+	close(stop)
+	wg.Wait()
+
+	if interval := d.Status().Interval; interval != c.Interval {
+		t.Errorf("got interval %d, want %d", interval, c.Interval)
+	}
+	if got := d.Store().Type(); got != store.BadgerDatabaseType {
+		t.Errorf("got store type %s, want %s", got, store.BadgerDatabaseType)
+	}
+	waitKeys(t, d, "fs_file_"+root, 1)
+}
+
+func TestCancelIndexing(t *testing.T) {
+	root := t.TempDir()
+	c := config.DefaultConfig()
+	c.GRPC.Server = false
+	c.Indexer = []config.IndexerConfig{{Type: string(indexer.FileSystemIndexerType), Paths: []string{root}}}
+	d, _ := newTestDaemon(t, c)
+	key := c.Indexer[0].Type + ":" + root
+	d.addIndexers()
+
+	if _, err := d.CancelIndexing(root, ""); err != nil {
+		t.Fatal(err)
+	}
+	canceled := d.job(key)
+
+	// The scheduler doesn't restart canceled indexers.
+	d.addIndexers()
+	if j := d.job(key); j != canceled {
+		t.Error("scheduler restarted the canceled indexer")
+	}
+
+	// Reindexing does, and the scheduler restarts them again.
+	if _, err := d.Reindex(root, ""); err != nil {
+		t.Fatal(err)
+	}
+	j := d.job(key)
+	if j == canceled || j.canceled {
+		t.Fatal("reindex didn't restart the canceled indexer")
+	}
+	<-j.updated
+	d.addIndexers()
+	if d.job(key) == j {
+		t.Error("scheduler didn't restart the reindexed indexer")
+	}
+}
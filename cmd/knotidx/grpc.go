@@ -1,34 +1,243 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
 	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/fold"
+	"github.com/shtirlic/knotidx/internal/indexer"
 	"github.com/shtirlic/knotidx/internal/pb"
+	"github.com/shtirlic/knotidx/internal/query"
+	"github.com/shtirlic/knotidx/internal/rank"
 	"github.com/shtirlic/knotidx/internal/store"
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type GRPServer struct {
-	server *grpc.Server
-	store  store.Store
-	config config.Config
+	mu         sync.Mutex // Protects server and stopped, started and stopped concurrently on reloads.
+	server     *grpc.Server
+	stopped    bool            // Stop was called, Start doesn't serve.
+	storeMu    sync.Mutex      // Protects store and storeUsers, replaced on reloads.
+	store      store.Store     // Store of the requests, use useStore.
+	storeUsers *sync.WaitGroup // Requests using the store.
+	config     config.Config
+	daemon     daemonControl // daemon controlled by the server, nil if not running as a daemon.
 	pb.UnimplementedKnotidxServer
 }
 
+// daemonControl is the interface of the daemon to the gRPC server.
+type daemonControl interface {
+	Status() DaemonStatus                                                     // Status returns the state of the daemon indexers and scheduler.
+	ResetScheduler()                                                          // ResetScheduler triggers the indexing job on the next tick.
+	Reindex(path, typ string) ([]indexer.Indexer, error)                      // Reindex restarts the indexers of the path and type.
+	CancelIndexing(path, typ string) ([]indexer.Indexer, error)               // CancelIndexing stops the indexers of the path and type.
+	AddIndexer(c config.IndexerConfig, persist bool) (indexer.Indexer, error) // AddIndexer adds and starts the fs indexer of the path.
+	RemoveIndexer(path string, persist bool) (indexer.Indexer, int, error)    // RemoveIndexer stops the fs indexer of the path and deletes its items.
+	ListIndexers() []ConfiguredIndexer                                        // ListIndexers returns the configured indexers per path.
+	Reload(ctx context.Context) (config.Diff, error)                          // Reload applies the changes of the config file.
+}
+
+// errNoDaemon is returned by the daemon control requests if not running as a daemon.
+var errNoDaemon = status.Error(codes.Unavailable, "not running as a daemon")
+
 func (s *GRPServer) ResetScheduler(context.Context, *pb.EmptyRequest) (*pb.EmptyResponse, error) {
-	//  resetScheduler(daemonConf.Interval) //todo fix daemon
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	s.daemon.ResetScheduler()
 	return &pb.EmptyResponse{}, nil
 }
 
-func (s *GRPServer) Reload(context.Context, *pb.EmptyRequest) (*pb.EmptyResponse, error) {
-	unix.Kill(unix.Getpid(), unix.SIGHUP)
-	return &pb.EmptyResponse{}, nil
+// Reindex restarts the indexers of the requested path and type, canceling
+// their running updates, and returns their status.
+func (s *GRPServer) Reindex(_ context.Context, req *pb.IndexerRequest) (*pb.IndexersResponse, error) {
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	return pbIndexers(s.daemon.Reindex(req.GetPath(), req.GetIndexer()))
+}
+
+// CancelIndexing stops the indexers of the requested path and type with their
+// watchers, and returns their status.
+func (s *GRPServer) CancelIndexing(_ context.Context, req *pb.IndexerRequest) (*pb.IndexersResponse, error) {
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	return pbIndexers(s.daemon.CancelIndexing(req.GetPath(), req.GetIndexer()))
+}
+
+// AddIndexer adds the fs indexer of the requested path to the daemon and
+// starts it, writing it to the config file if requested.
+func (s *GRPServer) AddIndexer(_ context.Context, req *pb.AddIndexerRequest) (*pb.IndexersResponse, error) {
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	ic := req.GetIndexer()
+	if ic.GetPath() == "" {
+		return nil, status.Error(codes.InvalidArgument, "path required")
+	}
+	idx, err := s.daemon.AddIndexer(indexerConfig(ic), req.GetPersist())
+	if err != nil {
+		return nil, controlError(err)
+	}
+	return pbIndexers([]indexer.Indexer{idx}, nil)
+}
+
+// RemoveIndexer stops the fs indexer of the requested path and deletes its
+// items, removing it from the config file if requested.
+func (s *GRPServer) RemoveIndexer(_ context.Context, req *pb.RemoveIndexerRequest) (*pb.RemoveIndexerResponse, error) {
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	if req.GetPath() == "" {
+		return nil, status.Error(codes.InvalidArgument, "path required")
+	}
+	idx, deleted, err := s.daemon.RemoveIndexer(req.GetPath(), req.GetPersist())
+	if err != nil {
+		return nil, controlError(err)
+	}
+	res := &pb.RemoveIndexerResponse{Deleted: int64(deleted)}
+	if idx != nil {
+		res.Indexer = pbIndexerStatus(idx)
+	}
+	return res, nil
+}
+
+// ListIndexers returns the configured indexers per path with their status.
+func (s *GRPServer) ListIndexers(context.Context, *pb.EmptyRequest) (*pb.ListIndexersResponse, error) {
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	res := &pb.ListIndexersResponse{}
+	for _, ci := range s.daemon.ListIndexers() {
+		e := &pb.IndexerEntry{Config: pbIndexerConfig(ci.Config)}
+		if ci.Indexer != nil {
+			e.Status = pbIndexerStatus(ci.Indexer)
+		}
+		res.Indexers = append(res.Indexers, e)
+	}
+	return res, nil
+}
+
+// indexerConfig returns the config of the requested indexer of a single path.
+func indexerConfig(ic *pb.IndexerConfig) config.IndexerConfig {
+	return config.IndexerConfig{
+		Type:               ic.GetType(),
+		Paths:              []string{ic.GetPath()},
+		Notify:             ic.GetNotify(),
+		Incremental:        ic.GetIncremental(),
+		IgnoreFiles:        ic.GetIgnoreFiles(),
+		ExcludeDirFilters:  ic.GetExcludeDirFilters(),
+		ExcludeFileFilters: ic.GetExcludeFileFilters(),
+		IncludeDirFilters:  ic.GetIncludeDirFilters(),
+		IncludeFileFilters: ic.GetIncludeFileFilters(),
+		MimeDetection:      ic.GetMimeDetection(),
+		MimeInfoPaths:      ic.GetMimeInfoPaths(),
+		Metadata:           ic.GetMetadata(),
+		Content:            ic.GetContent(),
+		ContentMaxSize:     ic.GetContentMaxSize(),
+		Workers:            int(ic.GetWorkers()),
+		GitCommits:         int(ic.GetGitCommits()),
+		Fanotify:           ic.GetFanotify(),
+		Refresh:            int(ic.GetRefresh()),
+		Endpoint:           ic.GetEndpoint(),
+		Region:             ic.GetRegion(),
+		HeadObjects:        ic.GetHeadObjects(),
+	}
+}
+
+// pbIndexerConfig returns the config of the indexer with a single path,
+// without the s3 credentials.
+func pbIndexerConfig(c config.IndexerConfig) *pb.IndexerConfig {
+	ic := &pb.IndexerConfig{
+		Type:               c.Type,
+		Notify:             c.Notify,
+		Incremental:        c.Incremental,
+		IgnoreFiles:        c.IgnoreFiles,
+		ExcludeDirFilters:  c.ExcludeDirFilters,
+		ExcludeFileFilters: c.ExcludeFileFilters,
+		IncludeDirFilters:  c.IncludeDirFilters,
+		IncludeFileFilters: c.IncludeFileFilters,
+		MimeDetection:      c.MimeDetection,
+		MimeInfoPaths:      c.MimeInfoPaths,
+		Metadata:           c.Metadata,
+		Content:            c.Content,
+		ContentMaxSize:     c.ContentMaxSize,
+		Workers:            int32(c.Workers),
+		GitCommits:         int32(c.GitCommits),
+		Fanotify:           c.Fanotify,
+		Refresh:            int32(c.Refresh),
+		Endpoint:           c.Endpoint,
+		Region:             c.Region,
+		HeadObjects:        c.HeadObjects,
+	}
+	if len(c.Paths) > 0 {
+		ic.Path = c.Paths[0]
+	}
+	return ic
+}
+
+// pbIndexers returns the response of the indexers affected by a request.
+func pbIndexers(idxs []indexer.Indexer, err error) (*pb.IndexersResponse, error) {
+	if err != nil {
+		return nil, controlError(err)
+	}
+	res := &pb.IndexersResponse{}
+	for _, idx := range idxs {
+		res.Indexers = append(res.Indexers, pbIndexerStatus(idx))
+	}
+	return res, nil
+}
+
+// controlError returns the status error of the daemon control error.
+func controlError(err error) error {
+	switch {
+	case errors.Is(err, errNoIndexers):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errIndexerExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, errInvalidIndexer):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// Reload reloads the config file, applying only its changes to the daemon,
+// and returns them.
+func (s *GRPServer) Reload(ctx context.Context, _ *pb.EmptyRequest) (*pb.ReloadResponse, error) {
+	if s.daemon == nil {
+		return nil, errNoDaemon
+	}
+	diff, err := s.daemon.Reload(ctx)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	res := &pb.ReloadResponse{Interval: diff.Interval, Grpc: diff.GRPC, Store: diff.Store}
+	for _, ic := range diff.Added {
+		res.Added = append(res.Added, pbIndexerConfig(ic))
+	}
+	for _, ic := range diff.Removed {
+		res.Removed = append(res.Removed, pbIndexerConfig(ic))
+	}
+	for _, ic := range diff.Changed {
+		res.Changed = append(res.Changed, pbIndexerConfig(ic))
+	}
+	return res, nil
 }
 
 func (s *GRPServer) Shutdown(context.Context, *pb.EmptyRequest) (*pb.EmptyResponse, error) {
@@ -36,34 +245,403 @@ func (s *GRPServer) Shutdown(context.Context, *pb.EmptyRequest) (*pb.EmptyRespon
 	return &pb.EmptyResponse{}, nil
 }
 
+// Status returns the state of the indexers, the scheduler and the store.
+func (s *GRPServer) Status(context.Context, *pb.EmptyRequest) (*pb.StatusResponse, error) {
+	st, done := s.useStore()
+	defer done()
+	stats := st.Stats()
+	res := &pb.StatusResponse{
+		Store: &pb.StoreStatus{
+			Type:     string(st.Type()),
+			Info:     st.Info(),
+			Keys:     int64(stats.Keys),
+			Size:     stats.LSMSize + stats.VlogSize,
+			LsmSize:  stats.LSMSize,
+			VlogSize: stats.VlogSize,
+		},
+	}
+	if s.daemon == nil {
+		return res, nil
+	}
+
+	ds := s.daemon.Status()
+	res.Scheduler = &pb.SchedulerStatus{
+		LastTriggerTime: unixTime(ds.LastTriggerTime),
+		Interval:        int32(ds.Interval),
+		Idle:            ds.Idle,
+		IdleThreshold:   ds.IdleThreshold,
+	}
+	for _, idx := range ds.Indexers {
+		res.Indexers = append(res.Indexers, pbIndexerStatus(idx))
+	}
+	return res, nil
+}
+
+// pbIndexerStatus returns the status of the indexer with its runtime info.
+func pbIndexerStatus(idx indexer.Indexer) *pb.IndexerStatus {
+	info := idx.Info()
+	return &pb.IndexerStatus{
+		Type:       string(idx.Type()),
+		Root:       idx.Root(),
+		Status:     info.Status,
+		StartTime:  unixTime(info.StartTime),
+		FinishTime: unixTime(info.FinishTime),
+		Duration:   info.Duration.Milliseconds(),
+		Dirs:       int32(info.Dirs),
+		Files:      int32(info.Files),
+		Failed:     int32(info.Failed),
+		Total:      int32(info.Total),
+		Updated:    int32(info.Updated),
+		Skipped:    int32(info.Skipped),
+		Removed:    int32(info.Removed),
+		Watcher: &pb.WatcherStatus{
+			Enabled:   idx.Config().Notify,
+			Watches:   int32(info.Watches),
+			Unwatched: info.Unwatched,
+		},
+	}
+}
+
+// unixTime returns the unix time of t in seconds, 0 for the zero time.
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// Search result limits of the unary GetKeys request.
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 10000
+)
+
 func (s *GRPServer) GetKeys(ctx context.Context, sr *pb.SearchRequest) (*pb.SearchResponse, error) {
-	keys := s.store.Keys("", sr.Query, 100)
+	limit := min(cmp.Or(int(sr.Limit), defaultSearchLimit), maxSearchLimit)
 
 	sre := &pb.SearchResponse{}
-	for _, key := range keys {
-		sre.Results = append(sre.Results, &pb.SearchItemResponse{Key: key})
+	truncated, err := s.search(sr, limit, func(r *pb.SearchItemResponse) bool {
+		sre.Results = append(sre.Results, r)
+		return true
+	})
+	if err != nil {
+		slog.Debug("GRPC Search request", "text", sr.Query, "error", err)
+		return nil, err
 	}
 	sre.Count = int32(len(sre.Results))
+	sre.Truncated = truncated
+
+	// A full page may be followed by more results.
+	if len(sre.Results) == limit {
+		sre.NextCursor = sre.Results[len(sre.Results)-1].Cursor
+	}
 
-	slog.Debug("GRPC Search request", "text", sr.Query, "results", sre.Count)
+	slog.Debug("GRPC Search request", "text", sr.Query, "rank", sr.Rank, "results", sre.Count)
 	return sre, nil
 }
 
+func (s *GRPServer) SearchStream(sr *pb.SearchRequest, stream pb.Knotidx_SearchStreamServer) error {
+	var sendErr error
+	count := 0
+	truncated, err := s.search(sr, int(sr.Limit), func(r *pb.SearchItemResponse) bool {
+		sendErr = stream.Send(r)
+		count++
+		return sendErr == nil && stream.Context().Err() == nil
+	})
+	err = cmp.Or(err, sendErr)
+	// Report the dropped results after the returned ones.
+	if err == nil && truncated {
+		err = errRankTruncated
+	}
+
+	slog.Debug("GRPC SearchStream request", "text", sr.Query, "rank", sr.Rank, "results", count, "error", err)
+	return err
+}
+
+// ReportAccess records an access to the item of the key or to the items of
+// the path, used to rank search results by frecency.
+func (s *GRPServer) ReportAccess(ctx context.Context, ar *pb.AccessRequest) (*pb.EmptyResponse, error) {
+	var so store.SearchOptions
+	switch {
+	case ar.Key != "":
+		so = store.SearchOptions{Prefix: ar.Key, Match: func(key string, _ store.ItemInfo) bool {
+			return key == ar.Key
+		}}
+	case ar.Path != "":
+		so = store.SearchOptions{Literals: []string{ar.Path}, Match: func(_ string, item store.ItemInfo) bool {
+			return item.Path == ar.Path
+		}}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "key or path required")
+	}
+
+	st, done := s.useStore()
+	defer done()
+	now := time.Now()
+	results := st.Search(so)
+	for _, r := range results {
+		if err := st.ReportAccess(r.Key, now); err != nil {
+			slog.Error("GRPC ReportAccess request", "key", r.Key, "error", err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	slog.Debug("GRPC ReportAccess request", "key", ar.Key, "path", ar.Path, "items", len(results))
+	if len(results) == 0 {
+		return nil, status.Error(codes.NotFound, "no such item")
+	}
+	return &pb.EmptyResponse{}, nil
+}
+
+// maxRankedResults limits the depth of the ranking of a search request, as
+// ranking keeps the best matches in memory before returning the first one.
+const maxRankedResults = 10000
+
+// errRankTruncated is returned by ranked searches whose results go past the
+// maximum ranking depth.
+var errRankTruncated = status.Errorf(codes.OutOfRange, "ranked results truncated to the best %d matches", maxRankedResults)
+
+// search calls fn with at most limit results of the search request, 0 means
+// no limit, ordered by key or, for ranked requests, by decreasing score.
+// Cursors of ranked results are offsets in the ranking. All matches are
+// ranked, but only the best maxRankedResults are returned, and truncated
+// reports whether results past them were dropped.
+// It returns an InvalidArgument status error for bad queries and cursors.
+func (s *GRPServer) search(sr *pb.SearchRequest, limit int, fn func(*pb.SearchItemResponse) bool) (truncated bool, err error) {
+	st, done := s.useStore()
+	defer done()
+	so, q, err := searchOptions(sr, st)
+	if err != nil {
+		return false, err
+	}
+	so.Limit = limit
+
+	if !sr.Rank {
+		st.Each(so, func(r store.SearchResult) bool {
+			return fn(searchItem(r, q, 0, encodeCursor(sr, r.Key)))
+		})
+		return false, nil
+	}
+
+	offset := 0
+	if so.After != "" {
+		if offset, err = strconv.Atoi(so.After); err != nil || offset < 0 {
+			return false, status.Error(codes.InvalidArgument, "bad cursor: bad ranking offset")
+		}
+	}
+	scorer := rank.NewScorer(q.RankTerms())
+	scorer.SetFuzzy(sr.Fuzzy)
+
+	// Keep the best matches up to the end of the requested page.
+	depth := maxRankedResults
+	if limit > 0 && offset+limit < depth {
+		depth = offset + limit
+	}
+	top := rank.NewTop(depth)
+	so.After, so.Limit, so.Access = "", 0, true
+	st.Each(so, func(r store.SearchResult) bool {
+		top.Add(rank.Result{SearchResult: r, Score: scorer.Score(r)})
+		return true
+	})
+	results := top.Results()
+	for i := offset; i < len(results); i++ {
+		r := results[i]
+		if !fn(searchItem(r.SearchResult, q, r.Score, encodeCursor(sr, strconv.Itoa(i+1)))) {
+			return false, nil
+		}
+	}
+	// Matches past the depth were dropped if the page extends past it.
+	truncated = depth == maxRankedResults && top.Added() > depth && (limit == 0 || offset+limit > depth)
+	return truncated, nil
+}
+
+// searchOptions parses the search request into store search options and the
+// query, looking up content terms in the full-text index of the store.
+// It returns an InvalidArgument status error for bad queries and cursors.
+func searchOptions(sr *pb.SearchRequest, s store.Store) (so store.SearchOptions, q *query.Query, err error) {
+	if q, err = query.Parse(sr.Query); err != nil {
+		return so, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if sr.Limit < 0 {
+		return so, nil, status.Error(codes.InvalidArgument, "negative limit")
+	}
+	if so.After, err = decodeCursor(sr); err != nil {
+		return so, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if sr.Fuzzy {
+		q.SetFuzzy()
+	}
+	var mode fold.Mode
+	if sr.IgnoreCase {
+		mode |= fold.Normalize | fold.Case
+	}
+	if sr.IgnoreAccents {
+		mode |= fold.Normalize | fold.Accents
+	}
+	if mode != 0 {
+		q.SetFold(mode)
+		so.Fold = true
+	}
+	q.SetContentIndex(s)
+	so.Literals = q.Literals()
+	so.Terms = q.ContentTerms()
+	so.Match = q.Match
+	so.Limit = int(sr.Limit)
+	return so, q, nil
+}
+
+// searchItem converts a search result to the protobuf message, with the parts
+// of the item path matched by the bare words of the query.
+func searchItem(r store.SearchResult, q *query.Query, score float64, cursor string) *pb.SearchItemResponse {
+	sir := &pb.SearchItemResponse{Key: r.Key, Item: pbItem(r.Item), Cursor: cursor, Score: score}
+	for _, h := range q.Highlights(r.Item.Path) {
+		sir.Highlights = append(sir.Highlights, &pb.Range{Start: int32(h.Start), End: int32(h.End)})
+	}
+	return sir
+}
+
+// Cursor modes tag the cursors of unranked searches, holding store keys, and
+// of ranked searches, holding offsets in the ranking.
+const (
+	keyCursor  = 'k'
+	rankCursor = 'r'
+)
+
+// cursorMode returns the cursor mode of the search request.
+func cursorMode(sr *pb.SearchRequest) byte {
+	if sr.Rank {
+		return rankCursor
+	}
+	return keyCursor
+}
+
+// queryHash returns the hash of the search request fields selecting and
+// ordering the results, which its cursors are only valid for.
+func queryHash(sr *pb.SearchRequest) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%t%t%t%t", sr.Query, sr.Rank, sr.Fuzzy, sr.IgnoreCase, sr.IgnoreAccents)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// encodeCursor returns the opaque cursor of the search request resuming after
+// the store key or ranking offset, in the format "<mode><query hash>:<value>".
+func encodeCursor(sr *pb.SearchRequest, value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(string(cursorMode(sr)) + queryHash(sr) + ":" + value))
+}
+
+// decodeCursor returns the store key or ranking offset of the opaque cursor
+// of the search request. It fails if the cursor was returned for a search of
+// another mode or query.
+func decodeCursor(sr *pb.SearchRequest) (string, error) {
+	if sr.Cursor == "" {
+		return "", nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(sr.Cursor)
+	if err != nil {
+		return "", fmt.Errorf("bad cursor: %w", err)
+	}
+	header, value, ok := strings.Cut(string(b), ":")
+	if !ok || len(header) != 17 || (header[0] != keyCursor && header[0] != rankCursor) {
+		return "", errors.New("bad cursor: unknown format")
+	}
+	if header[0] != cursorMode(sr) {
+		if sr.Rank {
+			return "", errors.New("bad cursor: not a ranked search cursor")
+		}
+		return "", errors.New("bad cursor: ranked search cursor for an unranked search")
+	}
+	if header[1:] != queryHash(sr) {
+		return "", errors.New("bad cursor: cursor of another query")
+	}
+	return value, nil
+}
+
+// pbItem converts store item information to the protobuf message.
+func pbItem(i store.ItemInfo) *pb.Item {
+	return &pb.Item{
+		Name:    i.Name,
+		Path:    i.Path,
+		Type:    string(i.Type),
+		Mime:    i.MimeType,
+		ModTime: i.ModTime.Unix(),
+		Size:    i.Size,
+		Hash:    i.Hash,
+		Meta:    i.Meta,
+		Xattrs:  i.XAttrs,
+		Media:   pbMedia(i.Media),
+	}
+}
+
+// pbMedia converts media metadata to the protobuf message.
+func pbMedia(m *store.MediaInfo) *pb.Media {
+	if m == nil {
+		return nil
+	}
+	return &pb.Media{
+		Title:     m.Title,
+		Artist:    m.Artist,
+		Album:     m.Album,
+		Genre:     m.Genre,
+		Author:    m.Author,
+		Publisher: m.Publisher,
+		Language:  m.Language,
+		Camera:    m.Camera,
+		Year:      int32(m.Year),
+		Width:     int32(m.Width),
+		Height:    int32(m.Height),
+		Duration:  m.Duration.Milliseconds(),
+	}
+}
+
 func NewGRPCServer(c config.Config, s store.Store) *GRPServer {
 	return &GRPServer{
-		config: c,
-		store:  s,
+		config:     c,
+		store:      s,
+		storeUsers: new(sync.WaitGroup),
 	}
 }
 
+// useStore returns the store for a request, with the function to call when
+// the request is done with it.
+func (s *GRPServer) useStore() (store.Store, func()) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	users := s.storeUsers
+	users.Add(1)
+	return s.store, users.Done
+}
+
+// SetStore replaces the store of the requests, returning the function waiting
+// for the running requests using the replaced store, e.g. before closing it.
+func (s *GRPServer) SetStore(st store.Store) (wait func()) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	users := s.storeUsers
+	s.store, s.storeUsers = st, new(sync.WaitGroup)
+	return users.Wait
+}
+
+// ReplaceStore waits for the running requests using the store and replaces
+// it by the one returned by replace, called with the current store. New
+// requests wait for the replacement, e.g. of a store reopened in place.
+func (s *GRPServer) ReplaceStore(replace func(old store.Store) store.Store) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	s.storeUsers.Wait()
+	s.store = replace(s.store)
+}
+
 func (s *GRPServer) Enabled() bool {
 	return s.config.GRPC.Server
 }
 
 func (s *GRPServer) Stop() {
 	slog.Info("Stopping GRPC Server")
-	if s.server != nil {
-		s.server.GracefulStop()
+	s.mu.Lock()
+	s.stopped = true
+	server := s.server
+	s.mu.Unlock()
+	if server != nil {
+		server.GracefulStop()
 	}
 }
 
@@ -91,13 +669,23 @@ func (s *GRPServer) Start() {
 		return
 	}
 	var opts []grpc.ServerOption
-	s.server = grpc.NewServer(opts...)
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		lis.Close()
+		return
+	}
+	server := grpc.NewServer(opts...)
+	s.server = server
+	s.mu.Unlock()
 
-	pb.RegisterKnotidxServer(s.server, s)
-	reflection.Register(s.server)
+	pb.RegisterKnotidxServer(server, s)
+	reflection.Register(server)
 
-	if err := s.server.Serve(lis); err != nil {
+	if err := server.Serve(lis); err != nil {
 		slog.Error("Can't start GRPC Server", "err", err)
+		s.mu.Lock()
 		s.server = nil
+		s.mu.Unlock()
 	}
 }
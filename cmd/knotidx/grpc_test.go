@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/pb"
+	"github.com/shtirlic/knotidx/internal/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer returns a gRPC server of an in-memory store with the files.
+func newTestServer(t *testing.T, paths ...string) *GRPServer {
+	t.Helper()
+	s := store.NewInMemoryBadgerStore()
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	items := make(map[string]store.ItemInfo)
+	for i, p := range paths {
+		items["fs_file_"+p] = store.NewItemInfo(fmt.Sprint(i), p, time.Unix(0, 0), 1, "file")
+	}
+	if err := s.Add(items); err != nil {
+		t.Fatal(err)
+	}
+	return NewGRPCServer(config.Config{}, s)
+}
+
+// pageKeys returns the keys of all the pages of the search request.
+func pageKeys(t *testing.T, s *GRPServer, sr *pb.SearchRequest) []string {
+	t.Helper()
+	var keys []string
+	for {
+		sre, err := s.GetKeys(context.Background(), sr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range sre.Results {
+			keys = append(keys, r.Key)
+		}
+		if sre.NextCursor == "" {
+			return keys
+		}
+		sr.Cursor = sre.NextCursor
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	s := newTestServer(t, "/a/x1", "/a/x2", "/a/x3", "/b/y1")
+
+	for _, rank := range []bool{false, true} {
+		keys := pageKeys(t, s, &pb.SearchRequest{Query: "x", Limit: 2, Rank: rank})
+		if len(keys) != 3 {
+			t.Errorf("rank %t: got pages of %v, want 3 keys", rank, keys)
+		}
+	}
+
+	first := func(sr *pb.SearchRequest) string {
+		sre, err := s.GetKeys(context.Background(), sr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sre.NextCursor
+	}
+	keyCursor := first(&pb.SearchRequest{Query: "x", Limit: 1})
+	rankCursor := first(&pb.SearchRequest{Query: "x", Limit: 1, Rank: true})
+
+	tests := []struct {
+		name string
+		sr   *pb.SearchRequest
+	}{
+		{"not base64", &pb.SearchRequest{Query: "x", Cursor: "!"}},
+		{"unknown format", &pb.SearchRequest{Query: "x", Cursor: base64.RawURLEncoding.EncodeToString([]byte("fs_file_/a/x1"))}},
+		{"key cursor in ranked search", &pb.SearchRequest{Query: "x", Rank: true, Cursor: keyCursor}},
+		{"ranked cursor in unranked search", &pb.SearchRequest{Query: "x", Cursor: rankCursor}},
+		{"other query", &pb.SearchRequest{Query: "y", Cursor: keyCursor}},
+		{"other options", &pb.SearchRequest{Query: "x", IgnoreCase: true, Cursor: keyCursor}},
+		{"other ranked query", &pb.SearchRequest{Query: "a", Rank: true, Cursor: rankCursor}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetKeys(context.Background(), tt.sr)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("got error %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestIndexerConfig(t *testing.T) {
+	c := config.IndexerConfig{
+		Type:               "fs",
+		Paths:              []string{"/srv"},
+		Notify:             true,
+		Incremental:        true,
+		IgnoreFiles:        true,
+		ExcludeDirFilters:  []string{"build"},
+		ExcludeFileFilters: []string{"*.o"},
+		IncludeDirFilters:  []string{"src"},
+		IncludeFileFilters: []string{"*.go"},
+		MimeDetection:      "content",
+		MimeInfoPaths:      []string{"/usr/share/mime"},
+		Metadata:           true,
+		Content:            true,
+		ContentMaxSize:     1 << 20,
+		Workers:            4,
+		GitCommits:         10,
+		Fanotify:           true,
+		Refresh:            60,
+		Endpoint:           "http://localhost:9000",
+		Region:             "us-east-1",
+		HeadObjects:        true,
+	}
+	if got := indexerConfig(pbIndexerConfig(c)); !reflect.DeepEqual(got, c) {
+		t.Errorf("got %+v, want %+v", got, c)
+	}
+
+	// The credentials are never sent.
+	c.SecretKey = "secret"
+	if got := pbIndexerConfig(c); strings.Contains(got.String(), "secret") {
+		t.Errorf("config %v has the secret key", got)
+	}
+	if got := pbIndexerConfig(config.IndexerConfig{Type: "fs"}); got.Path != "" {
+		t.Errorf("got path %q of an indexer without paths", got.Path)
+	}
+}
@@ -33,18 +33,31 @@ var (
 	daemon *Daemon
 	client *Client
 
-	configCmd      = flag.String("config", config.DefaultConfigFile, "knotidx config file (default: knotidx.toml) ")
-	daemonCmd      = flag.Bool("daemon", false, "run knotidx daemon")
-	showConfigCmd  = flag.Bool("show-config", false, "show knotidx config")
-	checkConfigCmd = flag.Bool("check-config", false, "check knotidx config for errors")
-	clientCmd      = flag.Bool("client", false, "interactive index search")
-	jsonCmd        = flag.Bool("json", false, "json only output")
-	debugCmd       = flag.Bool("debug", false, "debug mode")
-	versionCmd     = flag.Bool("version", false, "show version")
+	configCmd        = flag.String("config", config.DefaultConfigFile, "knotidx config file (default: knotidx.toml) ")
+	daemonCmd        = flag.Bool("daemon", false, "run knotidx daemon")
+	showConfigCmd    = flag.Bool("show-config", false, "show knotidx config")
+	checkConfigCmd   = flag.Bool("check-config", false, "check knotidx config for errors")
+	clientCmd        = flag.Bool("client", false, "interactive index search")
+	jsonCmd          = flag.Bool("json", false, "json only output")
+	limitCmd         = flag.Int("limit", 100, "max search results, 0 for all")
+	rankCmd          = flag.Bool("rank", false, "order search results by relevance")
+	fuzzyCmd         = flag.Bool("fuzzy", false, "fuzzy search tolerant of typos and abbreviations")
+	ignoreCaseCmd    = flag.Bool("ignore-case", false, "case-insensitive search")
+	ignoreAccentsCmd = flag.Bool("ignore-accents", false, "accent-insensitive search")
+	accessCmd        = flag.String("access", "", "report an access to the item path for ranking (with -client)")
+	notifyCmd        = flag.Bool("notify", true, "watch the added indexer for changes (with add)")
+	persistCmd       = flag.Bool("persist", false, "write the added or removed indexer to the config file (with add, remove)")
+	debugCmd         = flag.Bool("debug", false, "debug mode")
+	versionCmd       = flag.Bool("version", false, "show version")
+
+	// command is the daemon command of the first argument: status, reindex,
+	// cancel, add, remove, list or reload, empty for none.
+	command string
 )
 
 func main() {
 	flag.Parse()
+	command = flag.Arg(0)
 
 	// Set slog logger
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
@@ -54,7 +67,7 @@ func main() {
 	// slog.Int("pid", os.Getpid()),
 	))
 
-	if *jsonCmd {
+	if *jsonCmd || command != "" {
 		programLevel.Set(slog.LevelError)
 	}
 
@@ -98,6 +111,11 @@ func main() {
 		client = NewClient(conf.GRPC)
 		programExitCode, programErr = client.Start()
 	}
+
+	if command != "" {
+		client = NewClient(conf.GRPC)
+		programExitCode, programErr = client.Command(command, flag.Arg(1))
+	}
 }
 
 // Do program shutdown
@@ -107,6 +125,8 @@ func shutDown(s store.Store) {
 	// If was run in daemon mode
 	if daemon != nil {
 		daemon.ShutDown()
+		// Close the store reopened by config reloads.
+		s = daemon.Store()
 	}
 
 	// Close the store
@@ -114,7 +134,7 @@ func shutDown(s store.Store) {
 		// TODO: wrap err
 		err := s.Close()
 		if err != nil {
-			slog.Error("err", err)
+			slog.Error("Can't close the store", "error", err)
 		}
 	}
 
@@ -145,7 +165,7 @@ func loadUp() (c config.Config, s store.Store, err error) {
 	if c, err = reloadConfig(); err != nil {
 		return
 	}
-	if *clientCmd {
+	if *clientCmd || command != "" {
 		return
 	}
 	s, err = newStore(c.Store)
@@ -178,6 +198,10 @@ func reloadConfig() (config.Config, error) {
 		slog.Error("Can't read config from toml files", "error", err)
 		return config.Config{}, err
 	}
+	if err = c.Validate(); err != nil {
+		slog.Error("Invalid config", "error", err)
+		return config.Config{}, err
+	}
 	return c, nil
 }
 
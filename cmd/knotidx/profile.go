@@ -13,10 +13,10 @@ func cpuprofile() func() {
 	}
 	f, err := os.Create("cpuprofile.prof")
 	if err != nil {
-		slog.Error("could not create CPU profile: ", err)
+		slog.Error("could not create CPU profile", "error", err)
 	}
 	if err := pprof.StartCPUProfile(f); err != nil {
-		slog.Error("could not start CPU profile: ", err)
+		slog.Error("could not start CPU profile", "error", err)
 	}
 	return func() {
 		pprof.StopCPUProfile()
@@ -31,10 +31,10 @@ func memprofile() {
 	}
 	f, err := os.Create("memprofile.prof")
 	if err != nil {
-		slog.Error("could not create memory profile: ", err)
+		slog.Error("could not create memory profile", "error", err)
 	}
 	defer f.Close()
 	if err := pprof.WriteHeapProfile(f); err != nil {
-		slog.Error("could not write memory profile: ", err)
+		slog.Error("could not write memory profile", "error", err)
 	}
 }
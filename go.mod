@@ -8,6 +8,7 @@ require (
 	github.com/dgraph-io/badger/v4 v4.6.0
 	github.com/fsnotify/fsnotify v1.8.0
 	golang.org/x/sys v0.31.0
+	golang.org/x/text v0.23.0
 	google.golang.org/protobuf v1.36.5
 )
 
@@ -19,7 +20,6 @@ require (
 	go.opentelemetry.io/otel v1.34.0 // indirect
 	go.opentelemetry.io/otel/metric v1.34.0 // indirect
 	go.opentelemetry.io/otel/trace v1.34.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250313205543-e70fdf4c4cb4 // indirect
 )
 
@@ -28,6 +28,6 @@ require (
 	github.com/google/flatbuffers v25.2.10+incompatible // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	golang.org/x/net v0.37.0 // indirect
+	golang.org/x/net v0.37.0
 	google.golang.org/grpc v1.71.0
 )
@@ -28,34 +28,53 @@ const (
 
 // IndexerConfig represents the configuration for an indexer.
 type IndexerConfig struct {
-	Type               string   // Type of the indexer.
-	Paths              []string // List of paths to index.
-	Notify             bool     // Enable/disable file system notifications.
-	ExcludeDirFilters  []string // List of directory filters to exclude during indexing.
-	ExcludeFileFilters []string // List of file filters to exclude during indexing.
+	Type               string   `toml:"type,omitempty"`                    // Type of the indexer.
+	Paths              []string `toml:"paths,omitempty"`                   // List of paths to index.
+	Notify             bool     `toml:"notify,omitempty"`                  // Enable/disable file system notifications.
+	ExcludeDirFilters  []string `toml:"excludeDirFilters,omitempty"`       // List of directory filters to exclude during indexing.
+	ExcludeFileFilters []string `toml:"excludeFileFilters,omitempty"`      // List of file filters to exclude during indexing.
+	IncludeDirFilters  []string `toml:"includeDirFilters,omitempty"`       // List of directory filters to index only, empty for all.
+	IncludeFileFilters []string `toml:"includeFileFilters,omitempty"`      // List of file filters to index only, empty for all.
+	IgnoreFiles        bool     `toml:"ignoreFiles,omitempty"`             // Honour .gitignore and .knotidxignore files.
+	MimeDetection      string   `toml:"mimeDetection,omitempty"`           // MIME type detection: "extension" (default) or "content" sniffing.
+	MimeInfoPaths      []string `toml:"mimeInfoPaths,omitempty"`           // shared-mime-info package directories, default XDG ones.
+	Metadata           bool     `toml:"metadata,omitempty"`                // Extract metadata of images, audio, e-books and videos.
+	Content            bool     `toml:"content,omitempty"`                 // Index the text content of text documents, source code, HTML and PDF files.
+	ContentMaxSize     int64    `toml:"contentMaxSize,omitempty,omitzero"` // Maximum size in bytes of files whose content is indexed, default 10 MiB.
+	Incremental        bool     `toml:"incremental,omitempty"`             // Skip unchanged items and directories when updating fs indexers.
+	Workers            int      `toml:"workers,omitempty,omitzero"`        // Number of directories read concurrently by fs indexers, default the number of CPUs.
+	GitCommits         int      `toml:"gitCommits,omitempty,omitzero"`     // Number of recent commits to index for git indexers.
+	Fanotify           bool     `toml:"fanotify,omitempty"`                // Watch fs directories beyond the inotify watch limit with fanotify, requires CAP_SYS_ADMIN.
+	Refresh            int      `toml:"refresh,omitempty,omitzero"`        // Refresh interval in seconds for indexers without notifications, e.g. sysfs, and unwatched fs directories.
+	Endpoint           string   `toml:"endpoint,omitempty"`                // Object storage endpoint URL for s3 indexers, empty for AWS.
+	Region             string   `toml:"region,omitempty"`                  // Object storage region for s3 indexers.
+	AccessKey          string   `toml:"accessKey,omitempty"`               // Object storage access key ID for s3 indexers.
+	SecretKey          string   `toml:"secretKey,omitempty"`               // Object storage secret access key for s3 indexers.
+	SessionToken       string   `toml:"sessionToken,omitempty"`            // Object storage session token for s3 indexers.
+	HeadObjects        bool     `toml:"headObjects,omitempty"`             // Request the content type of new and changed objects for s3 indexers.
 }
 
 // StoreConfig represents the configuration for the data store.
 type StoreConfig struct {
-	Type string // Type of the data store.
-	Path string // Path for the data store.
+	Type string `toml:"type,omitempty"` // Type of the data store.
+	Path string `toml:"path,omitempty"` // Path for the data store.
 }
 
 // GRPCConfig represents the configuration for the gRPC server.
 type GRPCConfig struct {
-	Server bool           // Enable/disable the gRPC server.
-	Port   int            // Port on which the gRPC server listens.
-	Type   GRPCServerType // Type of the gRPC server (TCP or Unix).
-	Path   string         // Path for Unix socket (if applicable).
-	Host   string         // Host for TCP server.
+	Server bool           `toml:"server"`                  // Enable/disable the gRPC server.
+	Port   int            `toml:"port,omitempty,omitzero"` // Port on which the gRPC server listens.
+	Type   GRPCServerType `toml:"type,omitempty"`          // Type of the gRPC server (TCP or Unix).
+	Path   string         `toml:"path,omitempty"`          // Path for Unix socket (if applicable).
+	Host   string         `toml:"host,omitempty"`          // Host for TCP server.
 }
 
 // Config represents the overall application configuration.
 type Config struct {
-	Interval int             // Interval for indexing.
-	GRPC     GRPCConfig      // gRPC server configuration.
-	Store    StoreConfig     // Data store configuration.
-	Indexer  []IndexerConfig // List of indexer configurations.
+	Interval int             `toml:"interval,omitempty,omitzero"` // Interval for indexing.
+	GRPC     GRPCConfig      `toml:"grpc,omitempty"`              // gRPC server configuration.
+	Store    StoreConfig     `toml:"store,omitempty"`             // Data store configuration.
+	Indexer  []IndexerConfig `toml:"indexer,omitempty"`           // List of indexer configurations.
 }
 
 // DefaultConfig returns the default configuration for the application.
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+)
+
+// Diff represents the changes between two configurations, with the indexers
+// compared per type and path.
+type Diff struct {
+	Interval bool            // Interval changed.
+	GRPC     bool            // gRPC server configuration changed.
+	Store    bool            // Data store configuration changed.
+	Added    []IndexerConfig // Indexers of new paths, with a single path each.
+	Removed  []IndexerConfig // Indexers of removed paths, with a single path each.
+	Changed  []IndexerConfig // New configurations of indexers of kept paths, with a single path each.
+}
+
+// Empty reports whether the configurations are the same.
+func (d Diff) Empty() bool {
+	return !d.Interval && !d.GRPC && !d.Store && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff returns the changes from the configuration to the new one, with the
+// indexers in config order.
+func (c Config) Diff(n Config) Diff {
+	d := Diff{
+		Interval: c.Interval != n.Interval,
+		GRPC:     c.GRPC != n.GRPC,
+		Store:    c.Store != n.Store,
+	}
+
+	oldIndexers := c.IndexerPaths()
+	newIndexers := n.IndexerPaths()
+	old := make(map[string]IndexerConfig, len(oldIndexers))
+	for _, ic := range oldIndexers {
+		old[indexerKey(ic)] = ic
+	}
+	kept := make(map[string]bool, len(newIndexers))
+	for _, ic := range newIndexers {
+		key := indexerKey(ic)
+		kept[key] = true
+		if o, ok := old[key]; !ok {
+			d.Added = append(d.Added, ic)
+		} else if !reflect.DeepEqual(o, ic) {
+			d.Changed = append(d.Changed, ic)
+		}
+	}
+	for _, ic := range oldIndexers {
+		if !kept[indexerKey(ic)] {
+			d.Removed = append(d.Removed, ic)
+		}
+	}
+	return d
+}
+
+// IndexerPaths returns the indexer configurations split per path, with a
+// single path each, in config order.
+func (c Config) IndexerPaths() []IndexerConfig {
+	var res []IndexerConfig
+	for _, ic := range c.Indexer {
+		for _, p := range ic.Paths {
+			ic := ic
+			ic.Paths = []string{p}
+			res = append(res, ic)
+		}
+	}
+	return res
+}
+
+// Validate checks that every indexer configuration has paths, as the
+// indexers are compared and started per path.
+func (c Config) Validate() error {
+	for i, ic := range c.Indexer {
+		if len(ic.Paths) == 0 {
+			return fmt.Errorf("indexer %d of type %q has no paths", i+1, ic.Type)
+		}
+	}
+	return nil
+}
+
+// indexerKey returns the key of the indexer configuration with a single path,
+// see IndexerPaths, the same for equivalent paths like "/a" and "/a/".
+func indexerKey(ic IndexerConfig) string {
+	return ic.Type + ":" + filepath.Clean(ic.Paths[0])
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// indexerTables holds the [[indexer]] tables of a config file.
+type indexerTables struct {
+	Indexer []IndexerConfig `toml:"indexer"`
+}
+
+// AddIndexer appends the indexer to the config file as a new [[indexer]]
+// table, leaving the rest of the file as is. If the file path is empty, the
+// DefaultConfigFile constant is used.
+func AddIndexer(path string, ic IndexerConfig) error {
+	if path == "" {
+		path = DefaultConfigFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	table, err := encodeIndexer(ic)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	data = append(data, '\n')
+	return writeConfig(path, append(data, table...))
+}
+
+// EditIndexers applies the edit function to every [[indexer]] table of the
+// config file, which returns the new config of the indexer and whether to
+// keep it. Only the changed tables are rewritten and the removed ones
+// deleted, the rest of the file with its comments is left as is. If the file
+// path is empty, the DefaultConfigFile constant is used.
+func EditIndexers(path string, edit func(IndexerConfig) (IndexerConfig, bool)) error {
+	if path == "" {
+		path = DefaultConfigFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var out []string
+	lines := strings.SplitAfter(string(data), "\n")
+	for start := 0; start < len(lines); {
+		end := nextTable(lines, start+1)
+		if header, _, _ := strings.Cut(lines[start], "#"); strings.TrimSpace(header) != "[[indexer]]" {
+			out = append(out, lines[start:end]...)
+			start = end
+			continue
+		}
+
+		// The comments and blank lines at the end of the table belong to the next one.
+		last := end
+		for last > start+1 && isBlankOrComment(lines[last-1]) {
+			last--
+		}
+		var t indexerTables
+		if _, err := toml.Decode(strings.Join(lines[start:last], ""), &t); err != nil {
+			return fmt.Errorf("error decoding indexer table at line %d: %w", start+1, err)
+		}
+		ic, keep := edit(t.Indexer[0])
+		switch {
+		case !keep:
+			slog.Debug("Config remove indexer", "path", path, "line", start+1)
+		case reflect.DeepEqual(ic, t.Indexer[0]):
+			out = append(out, lines[start:last]...)
+		default:
+			table, err := encodeIndexer(ic)
+			if err != nil {
+				return err
+			}
+			out = append(out, table)
+		}
+		out = append(out, lines[last:end]...)
+		start = end
+	}
+
+	// Check the edited file before replacing the current one.
+	edited := strings.Join(out, "")
+	var c Config
+	if _, err := toml.Decode(edited, &c); err != nil {
+		return fmt.Errorf("error decoding edited config: %w", err)
+	}
+	return writeConfig(path, []byte(edited))
+}
+
+// nextTable returns the index of the first table header line from i, or the
+// number of lines if none.
+func nextTable(lines []string, i int) int {
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// isBlankOrComment reports whether the line is blank or a comment.
+func isBlankOrComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// encodeIndexer returns the [[indexer]] table of the indexer. The s3
+// credentials are not written, they are only read from the config file.
+func encodeIndexer(ic IndexerConfig) (string, error) {
+	if ic.AccessKey != "" || ic.SecretKey != "" || ic.SessionToken != "" {
+		return "", fmt.Errorf("indexer of %v has credentials, not written to the config file", ic.Paths)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(indexerTables{Indexer: []IndexerConfig{ic}}); err != nil {
+		return "", fmt.Errorf("error encoding config data: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// writeConfig replaces the config file by the data, readable by the user only
+// as it can hold credentials. A temporary file is renamed over the config
+// file, not to leave it truncated on errors.
+func writeConfig(path string, data []byte) error {
+	slog.Info("Config Save", "path", path)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
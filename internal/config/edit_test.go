@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testConfig = `# knotidx config
+interval = 10
+
+# Home
+[[indexer]] # fs
+type = "fs"
+paths = ["/home/a", "/home/b"]
+notify = true
+
+# Buckets
+[[indexer]]
+type = "s3"
+paths = ["s3://bucket"]
+secretKey = "secret"
+
+[store]
+path = "/var/lib/knotidx"
+`
+
+// editConfig writes the test config, applies the change and returns the edited file.
+func editConfig(t *testing.T, change func(path string) error) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "knotidx.toml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := change(path); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0o600 {
+		t.Errorf("got mode %o, want 600", mode)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestAddIndexer(t *testing.T) {
+	got := editConfig(t, func(path string) error {
+		return AddIndexer(path, IndexerConfig{Type: "fs", Paths: []string{"/srv"}, IncludeFileFilters: []string{"*.go"}})
+	})
+	want := testConfig + `
+[[indexer]]
+  type = "fs"
+  paths = ["/srv"]
+  includeFileFilters = ["*.go"]
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "knotidx.toml")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddIndexer(path, IndexerConfig{Type: "s3", Paths: []string{"s3://b"}, SecretKey: "x"}); err == nil {
+		t.Error("added indexer with credentials")
+	}
+}
+
+func TestEditIndexers(t *testing.T) {
+	// removePath removes the path from the fs indexers.
+	removePath := func(p string) func(IndexerConfig) (IndexerConfig, bool) {
+		return func(ic IndexerConfig) (IndexerConfig, bool) {
+			if ic.Type != "fs" {
+				return ic, true
+			}
+			ic.Paths = slices.DeleteFunc(slices.Clone(ic.Paths), func(q string) bool { return q == p })
+			return ic, len(ic.Paths) > 0
+		}
+	}
+
+	tests := []struct {
+		name string
+		edit func(IndexerConfig) (IndexerConfig, bool)
+		want string
+	}{
+		{
+			name: "unchanged",
+			edit: removePath("/missing"),
+			want: testConfig,
+		},
+		{
+			name: "changed",
+			edit: removePath("/home/a"),
+			want: `# knotidx config
+interval = 10
+
+# Home
+[[indexer]]
+  type = "fs"
+  paths = ["/home/b"]
+  notify = true
+
+# Buckets
+[[indexer]]
+type = "s3"
+paths = ["s3://bucket"]
+secretKey = "secret"
+
+[store]
+path = "/var/lib/knotidx"
+`,
+		},
+		{
+			name: "removed",
+			edit: func(ic IndexerConfig) (IndexerConfig, bool) { return ic, ic.Type != "fs" },
+			want: `# knotidx config
+interval = 10
+
+# Home
+
+# Buckets
+[[indexer]]
+type = "s3"
+paths = ["s3://bucket"]
+secretKey = "secret"
+
+[store]
+path = "/var/lib/knotidx"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := editConfig(t, func(path string) error { return EditIndexers(path, tt.edit) })
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package fold normalizes strings for case- and accent-insensitive matching:
+// Unicode normalization to NFC, full case folding and removal of diacritics.
+package fold
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mode selects the normalization and foldings applied to strings, the zero
+// mode leaves them unchanged.
+type Mode uint8
+
+// Normalization and foldings, foldings imply normalization.
+const (
+	Normalize Mode = 1 << iota // Normalize to NFC, e.g. a decomposed "é" to the precomposed one.
+	Case                       // Fold case, e.g. "Straße" to "strasse".
+	Accents                    // Remove diacritics, e.g. "résumé" to "resume".
+
+	All = Normalize | Case | Accents // Fold case and remove diacritics.
+)
+
+// letters are the folds of letters with diacritics that don't decompose.
+var letters = map[rune]string{
+	'ø': "o", 'Ø': "O", 'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D",
+	'ħ': "h", 'Ħ': "H", 'ŧ': "t", 'Ŧ': "T", 'ı': "i",
+}
+
+// String returns s normalized and folded by the mode.
+func String(s string, mode Mode) string {
+	if mode == 0 {
+		return s
+	}
+	if isASCII(s) {
+		if mode&Case != 0 {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	return Map(s, mode).String
+}
+
+// isASCII reports whether s contains only ASCII characters, which only fold
+// their case.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Folded represents a folded string with the mapping of its bytes to the
+// original string.
+type Folded struct {
+	String string
+	start  []int // Start in the original of the segment of each folded byte.
+	end    []int // End in the original of the segment of each folded byte.
+	length int   // Length of the original.
+}
+
+// Map returns s normalized and folded by the mode, with the mapping of the
+// folded bytes to s.
+func Map(s string, mode Mode) *Folded {
+	f := &Folded{length: len(s)}
+	if mode == 0 {
+		f.String = s
+		return f
+	}
+	form := norm.NFC
+	if mode&Accents != 0 {
+		// Decompose to separate the diacritics.
+		form = norm.NFD
+	}
+	caser := cases.Fold()
+
+	var b strings.Builder
+	var it norm.Iter
+	it.InitString(form, s)
+	for !it.Done() {
+		start := it.Pos()
+		seg := string(it.Next())
+		end := it.Pos()
+		if mode&Accents != 0 {
+			seg = removeAccents(seg)
+		}
+		if mode&Case != 0 {
+			seg = caser.String(seg)
+		}
+		if mode&Accents != 0 {
+			seg = norm.NFC.String(seg)
+		}
+		b.WriteString(seg)
+		for range len(seg) {
+			f.start = append(f.start, start)
+			f.end = append(f.end, end)
+		}
+	}
+	f.String = b.String()
+	return f
+}
+
+// accents are the combining marks removed as diacritics: the accents of
+// Latin, Greek and Cyrillic letters and the Hebrew and Arabic vowel points.
+// Other marks, like the Indic and Thai vowel signs or the kana voicing marks,
+// are parts of their letters and kept.
+var accents = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0300, Hi: 0x036f, Stride: 1},
+		{Lo: 0x0591, Hi: 0x05bd, Stride: 1},
+		{Lo: 0x05bf, Hi: 0x05c7, Stride: 1},
+		{Lo: 0x064b, Hi: 0x065f, Stride: 1},
+		{Lo: 0x0670, Hi: 0x0670, Stride: 1},
+	},
+}
+
+// removeAccents returns the decomposed segment without its accents,
+// replacing letters with diacritics that don't decompose.
+func removeAccents(seg string) string {
+	var b strings.Builder
+	for _, r := range seg {
+		if unicode.Is(accents, r) && unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if l, ok := letters[r]; ok {
+			b.WriteString(l)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Original returns the range of the original string folded into the range
+// of the folded string. Without a mapping, as for folded strings not created
+// by Map, the ranges are the same.
+func (f *Folded) Original(start, end int) (int, int) {
+	if f.start == nil {
+		return start, end
+	}
+	if start >= len(f.start) {
+		return f.length, f.length
+	}
+	if end <= start {
+		return f.start[start], f.start[start]
+	}
+	return f.start[start], f.end[min(end, len(f.end))-1]
+}
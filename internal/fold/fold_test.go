@@ -0,0 +1,110 @@
+package fold
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		s    string
+		mode Mode
+		want string
+	}{
+		{"Résumé", 0, "Résumé"},
+		{"ReadMe.TXT", Case, "readme.txt"},
+		{"ReadMe.TXT", Normalize, "ReadMe.TXT"},
+
+		// NFC and NFD input.
+		{"R\u00e9sum\u00e9", Normalize, "R\u00e9sum\u00e9"},
+		{"Re\u0301sume\u0301", Normalize, "R\u00e9sum\u00e9"},
+		{"Re\u0301sume\u0301", Case, "r\u00e9sum\u00e9"},
+		{"R\u00e9sum\u00e9", All, "resume"},
+		{"Re\u0301sume\u0301", All, "resume"},
+		{"A\u030a", Case, "\u00e5"},
+
+		// Latin.
+		{"Straße", Case, "strasse"},
+		{"Straße", All, "strasse"},
+		{"Ærøskøbing", All, "æroskobing"},
+		{"Łódź", All, "lodz"},
+		{"İstanbul", All, "istanbul"},
+
+		// Greek and Cyrillic.
+		{"ΣΊΣΥΦΟΣ", Case, "σίσυφοσ"},
+		{"ΣΊΣΥΦΟΣ", All, "σισυφοσ"},
+		{"Ёлка", All, "елка"},
+		{"Москва", Case, "москва"},
+
+		// Hebrew and Arabic vowel points.
+		{"שָׁלוֹם", All, "שלום"},
+		{"مَرْحَبًا", All, "مرحبا"},
+
+		// Scripts whose marks are parts of the letters.
+		{"東京", All, "東京"},
+		{"한국어", All, "한국어"},
+		{"がぎ", All, "がぎ"},
+		{"ガ", All, "ガ"},
+		{"हिन्दी", All, "हिन्दी"},
+		{"ภาษาไทย", All, "ภาษาไทย"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := String(tt.s, tt.mode); got != tt.want {
+				t.Errorf("String(%q, %d) = %q, want %q", tt.s, tt.mode, got, tt.want)
+			}
+			// Both normal forms of the input fold the same.
+			if tt.mode&Normalize != 0 {
+				for _, form := range []norm.Form{norm.NFC, norm.NFD} {
+					if got := String(form.String(tt.s), tt.mode); got != norm.NFC.String(tt.want) {
+						t.Errorf("String(%+q, %d) = %q, want %q", form.String(tt.s), tt.mode, got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOriginal(t *testing.T) {
+	tests := []struct {
+		s          string
+		mode       Mode
+		sub        string // Substring of the folded string.
+		start, end int    // Its range in the original.
+	}{
+		{"Caf\u00e9", All, "e", 3, 5},
+		{"Cafe\u0301", All, "e", 3, 6},
+		{"Cafe\u0301!", All, "!", 6, 7},
+		{"Straße", Case, "ss", 4, 6},
+		{"Straße", Case, "s", 4, 6},
+		{"ÉCOLE", All, "col", 2, 5},
+		{"Москва", Case, "ква", 6, 12},
+		{"東京", All, "京", 3, 6},
+		{"abc", 0, "b", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			f := Map(tt.s, tt.mode)
+			i := len(f.String) - len(tt.sub)
+			for i >= 0 && f.String[i:i+len(tt.sub)] != tt.sub {
+				i--
+			}
+			if i < 0 {
+				t.Fatalf("folded %q has no %q", f.String, tt.sub)
+			}
+			start, end := f.Original(i, i+len(tt.sub))
+			if start != tt.start || end != tt.end {
+				t.Errorf("Original of %q in %q = %d, %d, want %d, %d", tt.sub, f.String, start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	f := Map("abc", All)
+	if start, end := f.Original(5, 6); start != 3 || end != 3 {
+		t.Errorf("Original past the end = %d, %d, want 3, 3", start, end)
+	}
+	if start, end := f.Original(1, 1); start != 1 || end != 1 {
+		t.Errorf("Original of an empty range = %d, %d, want 1, 1", start, end)
+	}
+}
@@ -0,0 +1,151 @@
+// Package fulltext extracts and tokenizes the text content of documents for
+// the full-text index: plain text, source code, Markdown, HTML and PDF.
+package fulltext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Errors related to content extraction.
+var (
+	ErrNotText  = errors.New("not a text document")
+	ErrTooLarge = errors.New("document too large")
+	ErrFormat   = errors.New("unsupported or malformed document")
+)
+
+// MaxTermLength is the maximum length in bytes of indexed terms, longer
+// words like hashes and base64 data are skipped.
+const MaxTermLength = 64
+
+// sniffLength is the number of bytes checked by the text heuristic.
+const sniffLength = 512
+
+// textTypes are the text-like MIME types outside of text/*.
+var textTypes = map[string]bool{
+	"application/json":          true,
+	"application/xml":           true,
+	"application/javascript":    true,
+	"application/x-javascript":  true,
+	"application/ecmascript":    true,
+	"application/x-sh":          true,
+	"application/x-shellscript": true,
+	"application/x-perl":        true,
+	"application/x-php":         true,
+	"application/x-ruby":        true,
+	"application/x-python":      true,
+	"application/sql":           true,
+	"application/toml":          true,
+	"application/yaml":          true,
+	"application/x-yaml":        true,
+	"application/x-desktop":     true,
+	"application/x-subrip":      true,
+	"application/pdf":           true,
+}
+
+// IsText reports whether documents of the MIME type can be indexed: text/*,
+// XML and JSON based types, scripts and PDF.
+func IsText(mimeType string) bool {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	mimeType = strings.TrimSpace(mimeType)
+	return strings.HasPrefix(mimeType, "text/") || textTypes[mimeType] ||
+		strings.HasSuffix(mimeType, "+xml") || strings.HasSuffix(mimeType, "+json")
+}
+
+// isUnknown reports whether the MIME type doesn't tell the content, e.g. for
+// source files of extensions unknown to the system.
+func isUnknown(mimeType string) bool {
+	return mimeType == "" || mimeType == "application/octet-stream"
+}
+
+// looksText reports whether the head of the data is valid UTF-8 without NUL bytes.
+func looksText(data []byte) bool {
+	data = data[:min(len(data), sniffLength)]
+	if bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	// Allow a rune cut at the end.
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return utf8.Valid(data)
+}
+
+// Extract returns the text of the document of the MIME type.
+func Extract(data []byte, mimeType string) (string, error) {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	switch mimeType = strings.TrimSpace(mimeType); {
+	case mimeType == "application/pdf":
+		return extractPDF(data)
+	case mimeType == "text/html" || mimeType == "application/xhtml+xml":
+		return extractHTML(data), nil
+	case IsText(mimeType) || isUnknown(mimeType):
+		if !looksText(data) {
+			return "", fmt.Errorf("%w: binary content", ErrNotText)
+		}
+		return strings.ToValidUTF8(string(data), " "), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotText, mimeType)
+}
+
+// ExtractFile returns the text of the file of the MIME type, if the file is
+// not larger than maxSize bytes.
+func ExtractFile(path string, mimeType string, maxSize int64) (string, error) {
+	if !IsText(mimeType) && !isUnknown(mimeType) {
+		return "", fmt.Errorf("%w: %s", ErrNotText, mimeType)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fi.Size() > maxSize {
+		return "", fmt.Errorf("%w: %d bytes", ErrTooLarge, fi.Size())
+	}
+	// Sniff files of unknown types before reading them whole.
+	if isUnknown(mimeType) {
+		head := make([]byte, sniffLength)
+		n, _ := f.ReadAt(head, 0)
+		if !looksText(head[:n]) {
+			return "", fmt.Errorf("%w: binary content", ErrNotText)
+		}
+	}
+	// Files may grow after the size check.
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("%w: %d bytes", ErrTooLarge, len(data))
+	}
+	return Extract(data, mimeType)
+}
+
+// Terms splits the text into lower case terms of letters and digits in
+// order of appearance. Terms longer than MaxTermLength are skipped.
+func Terms(text string) []string {
+	var terms []string
+	for _, w := range strings.FieldsFunc(text, isSeparator) {
+		if len(w) > MaxTermLength {
+			continue
+		}
+		terms = append(terms, strings.ToLower(w))
+	}
+	return terms
+}
+
+// isSeparator reports whether the rune separates terms.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
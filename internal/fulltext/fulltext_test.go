@@ -0,0 +1,119 @@
+package fulltext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pdf returns a PDF document of the content stream objects, with their dictionaries.
+func pdf(streams ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	for i, s := range streams {
+		dict, stream, _ := strings.Cut(s, "|")
+		b.WriteString(string(rune('1'+i)) + " 0 obj\n<< " + dict + " >>\nstream\n" + stream + "\nendstream\nendobj\n")
+	}
+	b.WriteString("%%EOF\n")
+	return b.Bytes()
+}
+
+// deflate returns the zlib compressed data.
+func deflate(data string) string {
+	var b bytes.Buffer
+	z := zlib.NewWriter(&b)
+	z.Write([]byte(data))
+	z.Close()
+	return b.String()
+}
+
+func TestExtract(t *testing.T) {
+	flate := deflate("BT (compressed words) Tj ET")
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		want     []string // Terms of the extracted text.
+		err      error
+	}{
+		{"text", []byte("Hello, World!"), "text/plain; charset=utf-8", []string{"hello", "world"}, nil},
+		{"source", []byte("func main() {}"), "", []string{"func", "main"}, nil},
+		{"json", []byte(`{"key": "value"}`), "application/json", []string{"key", "value"}, nil},
+		{"cut rune", []byte("au café")[:7], "text/plain", []string{"au", "caf"}, nil},
+		{"invalid utf-8", []byte("caf\xe9 au lait"), "text/plain", nil, ErrNotText},
+		{"binary", []byte("ELF\x00\x01\x02"), "application/octet-stream", nil, ErrNotText},
+		{"image", []byte("GIF89a"), "image/gif", nil, ErrNotText},
+		{
+			"html",
+			[]byte(`<html><head><style>p {}</style><script>var x</script></head><body><p>Some <b>bold</b> text<img alt="a cat"></p></body></html>`),
+			"text/html",
+			[]string{"some", "bold", "text", "a", "cat"},
+			nil,
+		},
+		{"truncated html", []byte(`<p>cut <a href="x`), "text/html", []string{"cut"}, nil},
+		{"pdf", pdf("/Length 40|BT /F1 12 Tf (Hello) Tj 0 -14 Td (PDF) Tj ET"), "application/pdf", []string{"hello", "pdf"}, nil},
+		{"pdf tj array", pdf("|BT [(Wo) 20 (rd) -500 (gap)] TJ ET"), "application/pdf", []string{"word", "gap"}, nil},
+		{"pdf escapes", pdf(`|BT (a\(b\) \101) Tj <48 69> Tj ET`), "application/pdf", []string{"a", "b", "ahi"}, nil},
+		{"pdf utf-16", pdf("|BT <FEFF0436> Tj ET"), "application/pdf", []string{"ж"}, nil},
+		{"pdf flate", pdf("/Filter /FlateDecode|" + flate), "application/pdf", []string{"compressed", "words"}, nil},
+		{"pdf truncated flate", pdf("/Filter /FlateDecode|" + flate[:len(flate)-6]), "application/pdf", []string{"compressed", "words"}, nil},
+		{"pdf corrupt flate", pdf("/Filter /FlateDecode|garbage", "|BT (kept) Tj ET"), "application/pdf", []string{"kept"}, nil},
+		{"pdf other filter", pdf("/Filter /DCTDecode|BT (skipped) Tj ET"), "application/pdf", nil, nil},
+		{"pdf font", pdf("/Length1 10|BT (skipped) Tj ET"), "application/pdf", nil, nil},
+		{"pdf truncated", pdf("|BT (first) Tj ET", "|BT (second) Tj ET")[:60], "application/pdf", []string{"first"}, nil},
+		{"pdf unterminated string", pdf("|BT (open"), "application/pdf", nil, nil},
+		{"pdf encrypted", append(pdf("|BT (x) Tj ET"), "trailer << /Encrypt 5 0 R >>"...), "application/pdf", nil, ErrFormat},
+		{"not a pdf", []byte("<html>"), "application/pdf", nil, ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := Extract(tt.data, tt.mimeType)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if got := Terms(text); !slices.Equal(got, tt.want) {
+				t.Errorf("got terms %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	text := write("a.txt", "some text")
+	binary := write("a.bin", "\x00\x01\x02")
+
+	if got, err := ExtractFile(text, "text/plain", 100); err != nil || got != "some text" {
+		t.Errorf("got %q, %v, want the file text", got, err)
+	}
+	if _, err := ExtractFile(text, "text/plain", 4); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("got error %v, want %v", err, ErrTooLarge)
+	}
+	if _, err := ExtractFile(binary, "", 100); !errors.Is(err, ErrNotText) {
+		t.Errorf("got error %v, want %v", err, ErrNotText)
+	}
+	if _, err := ExtractFile(text, "image/png", 100); !errors.Is(err, ErrNotText) {
+		t.Errorf("got error %v, want %v", err, ErrNotText)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	long := strings.Repeat("x", MaxTermLength+1)
+	got := Terms("Über-cool 2024 " + long + " Ärger_über ∑ 東京")
+	want := []string{"über", "cool", "2024", "ärger", "über", "東京"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
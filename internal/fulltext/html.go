@@ -0,0 +1,50 @@
+package fulltext
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// extractHTML returns the text of the HTML document without its tags,
+// scripts and styles. Attribute texts like titles and alternate texts of
+// images are kept.
+func extractHTML(data []byte) string {
+	var b strings.Builder
+	z := html.NewTokenizer(bytes.NewReader(data))
+	skip := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// End of document or a malformed one, keep what was read.
+			return b.String()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			a := atom.Lookup(name)
+			if a == atom.Script || a == atom.Style || a == atom.Template {
+				skip++
+			}
+			b.WriteByte(' ')
+			for hasAttr {
+				var k, v []byte
+				k, v, hasAttr = z.TagAttr()
+				if key := string(k); key == "alt" || key == "title" {
+					b.Write(v)
+					b.WriteByte(' ')
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if a := atom.Lookup(name); (a == atom.Script || a == atom.Style || a == atom.Template) && skip > 0 {
+				skip--
+			}
+			b.WriteByte(' ')
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		}
+	}
+}
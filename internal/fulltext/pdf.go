@@ -0,0 +1,260 @@
+package fulltext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxPDFStreamSize limits the size of inflated PDF streams.
+const maxPDFStreamSize = 64 << 20
+
+// tjWordSpace is the TJ array adjustment, in thousandths of a text space
+// unit, from which a gap between strings is taken as a word space.
+const tjWordSpace = -200
+
+// extractPDF returns the text shown by the content streams of the PDF
+// document. Only uncompressed and Flate compressed streams are read and the
+// strings are decoded as PDFDocEncoding or UTF-16, so text of fonts with
+// custom encodings is lost.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", fmt.Errorf("%w: not a PDF document", ErrFormat)
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", fmt.Errorf("%w: encrypted PDF document", ErrFormat)
+	}
+
+	var b strings.Builder
+	for rest := data; ; {
+		i := bytes.Index(rest, []byte("stream"))
+		if i < 0 {
+			break
+		}
+		if i >= 3 && string(rest[i-3:i]) == "end" {
+			rest = rest[i+len("stream"):]
+			continue
+		}
+		// The stream dictionary follows the object header.
+		dict := rest[:i]
+		if j := bytes.LastIndex(dict, []byte("obj")); j >= 0 {
+			dict = dict[j:]
+		}
+		body := bytes.TrimPrefix(rest[i+len("stream"):], []byte("\r"))
+		body = bytes.TrimPrefix(body, []byte("\n"))
+		j := bytes.Index(body, []byte("endstream"))
+		if j < 0 {
+			break
+		}
+		stream := body[:j]
+		rest = body[j+len("endstream"):]
+
+		// Skip images, fonts and streams of unsupported filters.
+		if bytes.Contains(dict, []byte("/Image")) || bytes.Contains(dict, []byte("/Length1")) {
+			continue
+		}
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			z, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			// Keep the text of truncated streams.
+			stream, _ = io.ReadAll(io.LimitReader(z, maxPDFStreamSize))
+		case bytes.Contains(dict, []byte("/Filter")):
+			continue
+		}
+		pdfText(stream, &b)
+	}
+	return b.String(), nil
+}
+
+// pdfText writes the strings shown by the text operators of the content
+// stream to b, with spaces for text moves and large TJ gaps.
+func pdfText(s []byte, b *strings.Builder) {
+	var text strings.Builder // Strings of the pending operator.
+	inText, inArray := false, false
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '(':
+			str, n := pdfLiteral(s[i:])
+			text.WriteString(pdfString(str))
+			i += n
+		case c == '<' && i+1 < len(s) && s[i+1] == '<':
+			i += 2
+		case c == '<':
+			j := bytes.IndexByte(s[i:], '>')
+			if j < 0 {
+				return
+			}
+			text.WriteString(pdfString(pdfHex(s[i+1 : i+j])))
+			i += j + 1
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			// Comment up to the end of line.
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+		case c == '-' || c == '+' || c == '.' || '0' <= c && c <= '9':
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || '0' <= s[j] && s[j] <= '9') {
+				j++
+			}
+			if n, err := strconv.ParseFloat(string(s[i:j]), 64); err == nil && inArray && n <= tjWordSpace {
+				text.WriteByte(' ')
+			}
+			i = j
+		case c == '/':
+			// Name operand.
+			i++
+			for i < len(s) && !isPDFDelimiter(s[i]) {
+				i++
+			}
+		case isPDFDelimiter(c):
+			i++
+		default:
+			j := i
+			for j < len(s) && !isPDFDelimiter(s[j]) {
+				j++
+			}
+			switch op := string(s[i:j]); op {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				b.WriteByte('\n')
+			case "Td", "TD", "T*", "Tm":
+				if inText {
+					b.WriteByte(' ')
+				}
+			case "Tj", "TJ", "'", "\"":
+				if inText {
+					if op == "'" || op == "\"" {
+						b.WriteByte(' ')
+					}
+					b.WriteString(text.String())
+				}
+			}
+			text.Reset()
+			i = j
+		}
+	}
+}
+
+// isPDFDelimiter reports whether the byte ends a PDF token.
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// pdfLiteral decodes the literal string at the start of s, returning its
+// bytes and the length of its encoded form.
+func pdfLiteral(s []byte) ([]byte, int) {
+	var str []byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(':
+			if depth > 0 {
+				str = append(str, c)
+			}
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return str, i + 1
+			}
+			str = append(str, c)
+		case '\\':
+			if i++; i == len(s) {
+				return str, i
+			}
+			switch e := s[i]; e {
+			case 'n':
+				str = append(str, '\n')
+			case 'r':
+				str = append(str, '\r')
+			case 't':
+				str = append(str, '\t')
+			case 'b':
+				str = append(str, '\b')
+			case 'f':
+				str = append(str, '\f')
+			case '\r':
+				// Line continuation.
+				if i+1 < len(s) && s[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if '0' <= e && e <= '7' {
+					j := i
+					for j < len(s) && j < i+3 && '0' <= s[j] && s[j] <= '7' {
+						j++
+					}
+					n, _ := strconv.ParseUint(string(s[i:j]), 8, 8)
+					str = append(str, byte(n))
+					i = j - 1
+				} else {
+					str = append(str, e)
+				}
+			}
+		default:
+			str = append(str, c)
+		}
+	}
+	return str, len(s)
+}
+
+// pdfHex decodes the digits of a hex string, a missing last digit is zero.
+func pdfHex(s []byte) []byte {
+	var digits []byte
+	for _, c := range s {
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	str := make([]byte, len(digits)/2)
+	for i := range str {
+		n, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		str[i] = byte(n)
+	}
+	return str
+}
+
+// isHexDigit reports whether the byte is a hex digit.
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// pdfString decodes a UTF-16BE string with a byte order mark or a
+// PDFDocEncoding string, approximated as Latin-1.
+func pdfString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff {
+		u := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			u = append(u, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+	r := make([]rune, len(s))
+	for i, c := range s {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
@@ -0,0 +1,327 @@
+// Package fuzzy matches patterns against item names and paths the way fzf
+// does: as subsequences scored by the position of the matched characters and
+// the gaps between them, or within a bounded edit distance to tolerate typos.
+package fuzzy
+
+import (
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Range represents a matched part of a string, as half-open byte offsets.
+type Range struct {
+	Start int
+	End   int
+}
+
+// Scores of matched characters and penalties of gaps between them.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary    = 8 // Character after a separator, e.g. the start of a name.
+	bonusCamel       = 7 // Upper case after lower case, digit after letter.
+	bonusConsecutive = 4 // Minimum bonus of a character right after the previous match.
+	bonusFirstChar   = 2 // Multiplier of the bonus of the first pattern character.
+)
+
+// maxLength limits the length in runes of the matched strings, as the
+// matching time grows with the product of pattern and string lengths.
+const maxLength = 1024
+
+// scorePerfectRune is the score of a pattern rune matched in a run of
+// consecutive matches starting at a word boundary, used to normalize scores.
+const scorePerfectRune = scoreMatch + bonusBoundary
+
+// MaxTypos returns the number of typos tolerated in the pattern, by its
+// length in runes: none below 4, 1 below 8, else 2.
+func MaxTypos(pattern string) int {
+	switch n := len([]rune(pattern)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Match matches the pattern against s as a subsequence or, failing that,
+// as a substring with at most MaxTypos edits. It returns the score, higher
+// is better, and the matched ranges of s. Matching is case-insensitive
+// unless the pattern contains upper case letters.
+func Match(pattern, s string) (score int, ranges []Range, ok bool) {
+	if score, ranges, ok = Subsequence(pattern, s); ok {
+		return
+	}
+	return Approximate(pattern, s, MaxTypos(pattern))
+}
+
+// MatchPath matches the pattern against the base name of the path, then as
+// a subsequence of the whole path. It returns the score and the matched
+// ranges of the path. Typos are only tolerated in the base name.
+func MatchPath(pattern, p string) (score int, ranges []Range, ok bool) {
+	name := path.Base(p)
+	offset := strings.LastIndex(p, name)
+	if name != "." && name != "/" && offset >= 0 {
+		if score, ranges, ok = Match(pattern, name); ok {
+			for i := range ranges {
+				ranges[i].Start += offset
+				ranges[i].End += offset
+			}
+			return
+		}
+	}
+	return Subsequence(pattern, p)
+}
+
+// Normalize returns the score of the pattern in [0, 1], relative to an
+// exact match at a word boundary.
+func Normalize(score int, pattern string) float64 {
+	n := len([]rune(pattern))
+	if n == 0 || score <= 0 {
+		return 0
+	}
+	return min(float64(score)/float64(n*scorePerfectRune), 1)
+}
+
+// Merge sorts the ranges and joins the overlapping and adjacent ones.
+func Merge(ranges []Range) []Range {
+	slices.SortFunc(ranges, func(a, b Range) int { return a.Start - b.Start })
+	var merged []Range
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// text holds the runes of a matched string with their byte offsets and
+// position bonuses.
+type text struct {
+	runes   []rune
+	offsets []int // Byte offset of each rune, and of the end.
+	bonus   []int
+}
+
+// newText prepares s for matching a pattern, folding its case unless the
+// pattern has upper case letters.
+func newText(s string, fold bool) *text {
+	t := &text{}
+	prev := '/'
+	for i, r := range s {
+		t.offsets = append(t.offsets, i)
+		t.bonus = append(t.bonus, bonus(prev, r))
+		prev = r
+		if fold {
+			r = unicode.ToLower(r)
+		}
+		t.runes = append(t.runes, r)
+	}
+	t.offsets = append(t.offsets, len(s))
+	return t
+}
+
+// bonus returns the bonus of matching the character after prev.
+func bonus(prev, r rune) int {
+	switch {
+	case isSeparator(prev) && !isSeparator(r):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(r):
+		return bonusCamel
+	case unicode.IsLetter(prev) && unicode.IsDigit(r):
+		return bonusCamel
+	}
+	return 0
+}
+
+// isSeparator reports whether the rune separates words of names and paths.
+func isSeparator(r rune) bool {
+	switch r {
+	case '/', '_', '-', '.', ' ', ',', ':', ';', '+', '(', ')', '[', ']':
+		return true
+	}
+	return false
+}
+
+// patternRunes returns the runes of the pattern and whether matching folds
+// case, only for patterns without upper case letters.
+func patternRunes(pattern string) ([]rune, bool) {
+	fold := strings.ToLower(pattern) == pattern
+	return []rune(pattern), fold
+}
+
+// Subsequence matches the runes of the pattern in order in s, choosing the
+// positions of the best score: matches at word boundaries and consecutive
+// matches add bonuses, gaps between matches cost penalties. Consecutive
+// matches keep the bonus of the first match of their run, so a word matched
+// whole scores higher than its letters matched at separate word boundaries.
+func Subsequence(pattern, s string) (score int, ranges []Range, ok bool) {
+	p, fold := patternRunes(pattern)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	t := newText(s, fold)
+	n, m := len(t.runes), len(p)
+	if n > maxLength || !isSubsequence(p, t.runes) {
+		return 0, nil, false
+	}
+
+	// match[i][j] is the best score of p[:i+1] with p[i] at t[j], gap[i][j]
+	// the best score of p[:i+1] with p[i] before t[j] and a gap up to t[j],
+	// gapFrom[i][j] the position of p[i] for that gap score, chunk[i][j] the
+	// bonus of the run of consecutive matches ending with p[i] at t[j].
+	const none = math.MinInt / 2
+	match := make([][]int, m)
+	gap := make([][]int, m)
+	gapFrom := make([][]int, m)
+	consecutive := make([][]bool, m)
+	chunk := make([][]int, m)
+	for i := range m {
+		match[i] = make([]int, n)
+		gap[i] = make([]int, n)
+		gapFrom[i] = make([]int, n)
+		consecutive[i] = make([]bool, n)
+		chunk[i] = make([]int, n)
+		for j := range n {
+			match[i][j], gap[i][j] = none, none
+			if t.runes[j] == p[i] {
+				b := t.bonus[j]
+				chunk[i][j] = b
+				switch {
+				case i == 0:
+					match[i][j] = scoreMatch + b*bonusFirstChar
+				case j > 0:
+					cb := max(chunk[i-1][j-1], b)
+					viaMatch := match[i-1][j-1] + max(cb, bonusConsecutive)
+					viaGap := gap[i-1][j-1] + b
+					if viaMatch >= viaGap && match[i-1][j-1] > none {
+						match[i][j] = viaMatch + scoreMatch
+						consecutive[i][j] = true
+						chunk[i][j] = cb
+					} else if gap[i-1][j-1] > none {
+						match[i][j] = viaGap + scoreMatch
+					}
+				}
+			}
+			if j > 0 {
+				start, extend := match[i][j-1]+scoreGapStart, gap[i][j-1]+scoreGapExtension
+				if match[i][j-1] > none && start >= extend {
+					gap[i][j], gapFrom[i][j] = start, j-1
+				} else if gap[i][j-1] > none {
+					gap[i][j], gapFrom[i][j] = extend, gapFrom[i][j-1]
+				}
+			}
+		}
+	}
+
+	end := -1
+	score = none
+	for j := range n {
+		if match[m-1][j] > score {
+			score, end = match[m-1][j], j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backtrack the matched positions.
+	positions := make([]int, m)
+	for i, j := m-1, end; i >= 0; i-- {
+		positions[i] = j
+		if i == 0 {
+			break
+		}
+		if consecutive[i][j] {
+			j--
+		} else {
+			j = gapFrom[i-1][j-1]
+		}
+	}
+	for _, j := range positions {
+		ranges = append(ranges, Range{t.offsets[j], t.offsets[j+1]})
+	}
+	return score, Merge(ranges), true
+}
+
+// isSubsequence reports whether p is a subsequence of s.
+func isSubsequence(p, s []rune) bool {
+	i := 0
+	for _, r := range s {
+		if i < len(p) && r == p[i] {
+			i++
+		}
+	}
+	return i == len(p)
+}
+
+// Approximate matches the pattern against the substring of s with the
+// fewest edits, insertions, deletions, substitutions or transpositions of
+// adjacent runes, if there are at most maxEdits of them. Each edit costs the
+// score of two matched runes.
+func Approximate(pattern, s string, maxEdits int) (score int, ranges []Range, ok bool) {
+	p, fold := patternRunes(pattern)
+	t := newText(s, fold)
+	n, m := len(t.runes), len(p)
+	if m == 0 || maxEdits <= 0 || n > maxLength || m-maxEdits > n {
+		return 0, nil, false
+	}
+
+	// dist[j] is the edit distance of p[:i] to the best substring of s
+	// ending before t[j], starting at start[j]. prev and prev2 hold the
+	// rows of p[:i-1] and p[:i-2].
+	dist, start := make([]int, n+1), make([]int, n+1)
+	for j := range start {
+		start[j] = j
+	}
+	prev, prevStart := make([]int, n+1), make([]int, n+1)
+	prev2, prev2Start := make([]int, n+1), make([]int, n+1)
+	for i := 1; i <= m; i++ {
+		prev, prev2 = prev2, prev
+		prevStart, prev2Start = prev2Start, prevStart
+		copy(prev, dist)
+		copy(prevStart, start)
+		dist[0], start[0] = i, 0
+		for j := 1; j <= n; j++ {
+			cost := 1
+			if t.runes[j-1] == p[i-1] {
+				cost = 0
+			}
+			dist[j], start[j] = prev[j-1]+cost, prevStart[j-1]
+			if d := prev[j] + 1; d < dist[j] {
+				dist[j], start[j] = d, prevStart[j]
+			}
+			if d := dist[j-1] + 1; d < dist[j] {
+				dist[j], start[j] = d, start[j-1]
+			}
+			if i > 1 && j > 1 && p[i-1] == t.runes[j-2] && p[i-2] == t.runes[j-1] {
+				if d := prev2[j-2] + 1; d < dist[j] {
+					dist[j], start[j] = d, prev2Start[j-2]
+				}
+			}
+		}
+	}
+
+	// Of the substrings with the fewest edits, take the one ending last,
+	// e.g. "the" rather than "th" for "teh".
+	end := -1
+	for j := 1; j <= n; j++ {
+		if dist[j] <= maxEdits && (end < 0 || dist[j] <= dist[end]) {
+			end = j
+		}
+	}
+	if end < 0 || start[end] == end {
+		return 0, nil, false
+	}
+	d := dist[end]
+	score = max(scoreMatch*(m-2*d)+t.bonus[start[end]], 1)
+	return score, []Range{{t.offsets[start[end]], t.offsets[end]}}, true
+}
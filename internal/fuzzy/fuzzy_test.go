@@ -0,0 +1,179 @@
+package fuzzy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchRanges(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		ok      bool
+		want    []Range
+	}{
+		{"", "abc", true, nil},
+		{"foo", "foo.go", true, []Range{{0, 3}}},
+		{"fb", "foo_bar", true, []Range{{0, 1}, {4, 5}}},
+		{"bar", "foobar_bar.go", true, []Range{{7, 10}}},
+		{"fbg", "foo/bar.go", true, []Range{{0, 1}, {4, 5}, {8, 9}}},
+		{"cc", "CamelCase", true, []Range{{0, 1}, {5, 6}}},
+		{"é", "café", true, []Range{{3, 5}}},
+		{"foo", "FOO", true, []Range{{0, 3}}},
+		{"Foo", "foo", false, nil},
+		{"xyz", "foo", false, nil},
+
+		// Typos in patterns of 4 runes or more.
+		{"docuemnt", "my_document.txt", true, []Range{{3, 11}}},
+		{"fxo", "foo", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.s, func(t *testing.T) {
+			_, ranges, ok := Match(tt.pattern, tt.s)
+			if ok != tt.ok || !slices.Equal(ranges, tt.want) {
+				t.Errorf("got %v %v, want %v %v", ranges, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		p       string
+		ok      bool
+		want    []Range
+	}{
+		{"bar", "/foo/bar.go", true, []Range{{5, 8}}},
+		{"baz", "/foo/bar/baz", true, []Range{{9, 12}}},
+		{"fbg", "/foo/bar.go", true, []Range{{1, 2}, {5, 6}, {9, 10}}},
+		{"fbar", "/foo/bar.go", true, []Range{{5, 8}}}, // Typo in the name.
+		{"bar", "/bar/x.go", true, []Range{{1, 4}}},
+		{"documnet", "/documents/x.go", false, nil},
+		{"x", "/", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+tt.p, func(t *testing.T) {
+			_, ranges, ok := MatchPath(tt.pattern, tt.p)
+			if ok != tt.ok || !slices.Equal(ranges, tt.want) {
+				t.Errorf("got %v %v, want %v %v", ranges, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScoreOrder(t *testing.T) {
+	tests := []struct {
+		pattern string
+		names   []string // Names in descending score order.
+	}{
+		{"main", []string{"main.go", "m_a_i_n.go", "domain.go", "mxaxixn.go"}},
+		{"fb", []string{"foo_bar", "foobar", "fxxxxxxb"}},
+		{"rd", []string{"read_data", "readme"}},
+		{"cc", []string{"CamelCase", "camelcase"}},
+		{"report", []string{"report.pdf", "reprot.pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			prev := 0
+			for i, name := range tt.names {
+				score, _, ok := Match(tt.pattern, name)
+				if !ok {
+					t.Fatalf("%q doesn't match %q", tt.pattern, name)
+				}
+				if i > 0 && score >= prev {
+					t.Errorf("score of %q is %d, want less than %d of %q", name, score, prev, tt.names[i-1])
+				}
+				prev = score
+			}
+		})
+	}
+}
+
+func TestApproximate(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		s        string
+		maxEdits int
+		ok       bool
+		want     string // Matched substring.
+	}{
+		{"kitten", "sitting", 2, true, "sittin"},
+		{"kitten", "sitting", 1, false, ""},
+		{"teh", "the", 1, true, "the"},
+		{"abcd", "acbd", 1, true, "acbd"},
+		{"abc", "xabcx", 1, true, "abc"},
+		{"abcd", "abd", 1, true, "abd"},
+		{"abd", "abcd", 1, true, "abcd"},
+		{"grüße", "grüsse", 2, true, "grüsse"},
+		{"grüße", "grusse", 2, false, ""},
+		{"東京都", "京都", 1, true, "京都"},
+		{"abcdef", "ab", 2, false, ""},
+		{"", "abc", 2, false, ""},
+		{"abc", "abc", 0, false, ""},
+		{"abc", strings.Repeat("x", maxLength+1), 2, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.s, func(t *testing.T) {
+			score, ranges, ok := Approximate(tt.pattern, tt.s, tt.maxEdits)
+			if ok != tt.ok {
+				t.Fatalf("got ok %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if score <= 0 {
+				t.Errorf("got score %d, want a positive one", score)
+			}
+			if len(ranges) != 1 || tt.s[ranges[0].Start:ranges[0].End] != tt.want {
+				t.Errorf("got ranges %v, want %q", ranges, tt.want)
+			}
+		})
+	}
+
+	// Fewer edits score higher.
+	one, _, _ := Approximate("document", "documnt", 2)
+	two, _, _ := Approximate("document", "docmnt", 2)
+	if one <= two {
+		t.Errorf("score of 1 edit %d, want more than %d of 2 edits", one, two)
+	}
+}
+
+func TestMaxTypos(t *testing.T) {
+	for pattern, want := range map[string]int{
+		"":          0,
+		"abc":       0,
+		"abcd":      1,
+		"äöüß":      1,
+		"abcdefg":   1,
+		"abcdefgh":  2,
+		"abcdefghi": 2,
+	} {
+		if got := MaxTypos(pattern); got != want {
+			t.Errorf("MaxTypos(%q) = %d, want %d", pattern, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	exact, _, _ := Match("foo", "foo")
+	partial, _, _ := Match("foo", "xfxoxo")
+	if got := Normalize(exact, "foo"); got != 1 {
+		t.Errorf("normalized score of an exact match %v, want 1", got)
+	}
+	if got := Normalize(partial, "foo"); got >= Normalize(exact, "foo") || got < 0 {
+		t.Errorf("normalized score of a partial match %v, want less than an exact one", got)
+	}
+	if got := Normalize(10, ""); got != 0 {
+		t.Errorf("normalized score of an empty pattern %v, want 0", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	got := Merge([]Range{{8, 9}, {0, 2}, {2, 4}, {3, 5}, {10, 12}})
+	want := []Range{{0, 5}, {8, 9}, {10, 12}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,495 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectType represents the type of a git object.
+type ObjectType int
+
+// Git object types, values match the packfile encoding.
+const (
+	CommitObject ObjectType = 1
+	TreeObject   ObjectType = 2
+	BlobObject   ObjectType = 3
+	TagObject    ObjectType = 4
+)
+
+// objectTypes maps object type names to types.
+var objectTypes = map[string]ObjectType{
+	"commit": CommitObject,
+	"tree":   TreeObject,
+	"blob":   BlobObject,
+	"tag":    TagObject,
+}
+
+// String returns the name of the object type.
+func (t ObjectType) String() string {
+	for name, ot := range objectTypes {
+		if ot == t {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// Maximum number of cached objects.
+const maxCachedObjects = 512
+
+// object represents a decoded git object.
+type object struct {
+	typ  ObjectType
+	data []byte
+}
+
+// Signature represents the author or committer of a commit or tag.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// String returns the signature in the "Name <email>" form.
+func (s Signature) String() string {
+	return fmt.Sprintf("%s <%s>", s.Name, s.Email)
+}
+
+// Commit represents a parsed commit object.
+type Commit struct {
+	Hash      Hash
+	Tree      Hash
+	Parents   []Hash
+	Author    Signature
+	Committer Signature
+	Message   string
+}
+
+// Subject returns the first line of the commit message.
+func (c *Commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return subject
+}
+
+// TreeEntry represents an entry of a tree object.
+type TreeEntry struct {
+	Mode uint32 // File mode, e.g. 0o100644, 0o40000 for trees, 0o160000 for submodules.
+	Name string
+	Hash Hash
+}
+
+// Tree entry modes.
+const (
+	ModeTree      uint32 = 0o40000
+	ModeSymlink   uint32 = 0o120000
+	ModeSubmodule uint32 = 0o160000
+)
+
+// IsTree reports whether the entry is a subtree.
+func (e TreeEntry) IsTree() bool {
+	return e.Mode == ModeTree
+}
+
+// ReadObject returns the type and the content of the object.
+func (r *Repository) ReadObject(h Hash) (ObjectType, []byte, error) {
+	o, err := r.object(h)
+	if err != nil {
+		return 0, nil, err
+	}
+	return o.typ, o.data, nil
+}
+
+// object reads the object from the cache, loose objects or packfiles.
+func (r *Repository) object(h Hash) (*object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if o, ok := r.cache[h]; ok {
+		return o, nil
+	}
+
+	o, err := r.readLoose(h)
+	if errors.Is(err, fs.ErrNotExist) {
+		o, err = r.readPacked(h)
+		if errors.Is(err, ErrObjectNotFound) {
+			// Packs could have been repacked since the repository was opened.
+			if err = r.reloadPacks(); err == nil {
+				o, err = r.readPacked(h)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.cache) >= maxCachedObjects {
+		clear(r.cache)
+	}
+	r.cache[h] = o
+	return o, nil
+}
+
+// ObjectSize returns the size of the object content without reading it entirely.
+func (r *Repository) ObjectSize(h Hash) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if o, ok := r.cache[h]; ok {
+		return int64(len(o.data)), nil
+	}
+
+	f, err := os.Open(r.loosePath(h))
+	if err == nil {
+		defer f.Close()
+		zr, err := zlib.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("%w %s: %w", ErrBadObject, h, err)
+		}
+		defer zr.Close()
+		_, size, err := readLooseHeader(bufio.NewReader(zr))
+		return size, err
+	}
+
+	for _, p := range r.packs {
+		if off, ok := p.find(h); ok {
+			return p.objectSize(off)
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrObjectNotFound, h)
+}
+
+// loosePath returns the path of the loose object.
+func (r *Repository) loosePath(h Hash) string {
+	s := h.String()
+	return filepath.Join(r.commonDir, "objects", s[:2], s[2:])
+}
+
+// readLoose reads a zlib compressed loose object.
+func (r *Repository) readLoose(h Hash) (*object, error) {
+	f, err := os.Open(r.loosePath(h))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrBadObject, h, err)
+	}
+	defer zr.Close()
+
+	br := bufio.NewReader(zr)
+	typ, size, err := readLooseHeader(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrBadObject, h, err)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrBadObject, h, err)
+	}
+	return &object{typ: typ, data: data}, nil
+}
+
+// readLooseHeader reads the "type size\x00" header of a loose object.
+func readLooseHeader(br *bufio.Reader) (ObjectType, int64, error) {
+	header, err := br.ReadString(0)
+	if err != nil {
+		return 0, 0, err
+	}
+	name, size, ok := strings.Cut(strings.TrimSuffix(header, "\x00"), " ")
+	typ, known := objectTypes[name]
+	if !ok || !known {
+		return 0, 0, fmt.Errorf("bad object header %q", header)
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad object header %q", header)
+	}
+	return typ, n, nil
+}
+
+// Commit reads and parses the commit object.
+func (r *Repository) Commit(h Hash) (*Commit, error) {
+	typ, data, err := r.ReadObject(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != CommitObject {
+		return nil, fmt.Errorf("%w: %s is a %s, not a commit", ErrBadObject, h, typ)
+	}
+	return parseCommit(h, data)
+}
+
+// parseCommit parses the commit object content.
+func parseCommit(h Hash, data []byte) (*Commit, error) {
+	c := &Commit{Hash: h}
+	headers, message, _ := bytes.Cut(data, []byte("\n\n"))
+	c.Message = string(message)
+	for _, line := range strings.Split(string(headers), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		var err error
+		switch key {
+		case "tree":
+			c.Tree, err = ParseHash(value)
+		case "parent":
+			var p Hash
+			if p, err = ParseHash(value); err == nil {
+				c.Parents = append(c.Parents, p)
+			}
+		case "author":
+			c.Author = parseSignature(value)
+		case "committer":
+			c.Committer = parseSignature(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrBadObject, h, err)
+		}
+	}
+	return c, nil
+}
+
+// parseSignature parses a "Name <email> unixtime tz" signature.
+func parseSignature(s string) (sig Signature) {
+	name, rest, ok := strings.Cut(s, " <")
+	if !ok {
+		sig.Name = s
+		return
+	}
+	sig.Name = name
+	email, rest, _ := strings.Cut(rest, "> ")
+	sig.Email = email
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return
+	}
+	sec, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return
+	}
+	loc := time.UTC
+	if len(fields) > 1 && len(fields[1]) == 5 {
+		if tz, err := strconv.Atoi(fields[1]); err == nil {
+			offset := (tz/100*60 + tz%100) * 60
+			loc = time.FixedZone(fields[1], offset)
+		}
+	}
+	sig.When = time.Unix(sec, 0).In(loc)
+	return
+}
+
+// Peel follows annotated tags until a non tag object and returns its name.
+func (r *Repository) Peel(h Hash) (Hash, error) {
+	for i := 0; i < 10; i++ {
+		typ, data, err := r.ReadObject(h)
+		if err != nil {
+			return h, err
+		}
+		if typ != TagObject {
+			return h, nil
+		}
+		line, _, _ := bytes.Cut(data, []byte("\n"))
+		target, ok := bytes.CutPrefix(line, []byte("object "))
+		if !ok {
+			return h, fmt.Errorf("%w: %s", ErrBadObject, h)
+		}
+		if h, err = ParseHash(string(target)); err != nil {
+			return h, err
+		}
+	}
+	return h, fmt.Errorf("%w: %s tag chain is too long", ErrBadObject, h)
+}
+
+// Tree reads and parses the entries of the tree object.
+func (r *Repository) Tree(h Hash) ([]TreeEntry, error) {
+	typ, data, err := r.ReadObject(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != TreeObject {
+		return nil, fmt.Errorf("%w: %s is a %s, not a tree", ErrBadObject, h, typ)
+	}
+
+	var entries []TreeEntry
+	for len(data) > 0 {
+		mode, rest, ok := bytes.Cut(data, []byte(" "))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s bad tree entry", ErrBadObject, h)
+		}
+		name, rest, ok := bytes.Cut(rest, []byte{0})
+		if !ok || len(rest) < HashSize {
+			return nil, fmt.Errorf("%w: %s bad tree entry", ErrBadObject, h)
+		}
+		m, err := strconv.ParseUint(string(mode), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s bad tree entry mode", ErrBadObject, h)
+		}
+		e := TreeEntry{Mode: uint32(m), Name: string(name)}
+		copy(e.Hash[:], rest[:HashSize])
+		entries = append(entries, e)
+		data = rest[HashSize:]
+	}
+	return entries, nil
+}
+
+// WalkTree calls fn for every non tree entry of the tree and its subtrees
+// with the slash separated path relative to the tree.
+func (r *Repository) WalkTree(h Hash, fn func(path string, e TreeEntry) error) error {
+	return r.walkTree(h, "", fn)
+}
+
+func (r *Repository) walkTree(h Hash, dir string, fn func(path string, e TreeEntry) error) error {
+	entries, err := r.Tree(h)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		p := path.Join(dir, e.Name)
+		if e.IsTree() {
+			if err := r.walkTree(e.Hash, p, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(p, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffTree calls fn for every non tree entry that differs between the old and
+// the new tree. Removed entries are passed with a zero hash. Unchanged subtrees
+// are skipped without reading them.
+func (r *Repository) DiffTree(oldTree, newTree Hash, fn func(path string, e TreeEntry) error) error {
+	return r.diffTree(oldTree, newTree, "", fn)
+}
+
+func (r *Repository) diffTree(oldTree, newTree Hash, dir string, fn func(path string, e TreeEntry) error) error {
+	if oldTree == newTree {
+		return nil
+	}
+	var oldEntries, newEntries []TreeEntry
+	var err error
+	if !oldTree.IsZero() {
+		if oldEntries, err = r.Tree(oldTree); err != nil {
+			return err
+		}
+	}
+	if !newTree.IsZero() {
+		if newEntries, err = r.Tree(newTree); err != nil {
+			return err
+		}
+	}
+
+	old := make(map[string]TreeEntry, len(oldEntries))
+	for _, e := range oldEntries {
+		old[e.Name] = e
+	}
+
+	for _, e := range newEntries {
+		p := path.Join(dir, e.Name)
+		o, found := old[e.Name]
+		delete(old, e.Name)
+		if found && o == e {
+			continue
+		}
+		switch {
+		case e.IsTree():
+			var oldSub Hash
+			if found && o.IsTree() {
+				oldSub = o.Hash
+			} else if found {
+				// A file was replaced by a directory.
+				if err := fn(p, TreeEntry{Mode: o.Mode, Name: o.Name}); err != nil {
+					return err
+				}
+			}
+			if err := r.diffTree(oldSub, e.Hash, p, fn); err != nil {
+				return err
+			}
+		default:
+			if found && o.IsTree() {
+				// A directory was replaced by a file.
+				if err := r.diffTree(o.Hash, Hash{}, p, fn); err != nil {
+					return err
+				}
+			}
+			if err := fn(p, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Entries missing from the new tree were removed.
+	names := make([]string, 0, len(old))
+	for name := range old {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		o := old[name]
+		p := path.Join(dir, name)
+		if o.IsTree() {
+			if err := r.diffTree(o.Hash, Hash{}, p, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(p, TreeEntry{Mode: o.Mode, Name: o.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Log returns up to n commits reachable from the commit, most recent first
+// by committer time.
+func (r *Repository) Log(h Hash, n int) ([]*Commit, error) {
+	var log []*Commit
+	seen := map[Hash]bool{h: true}
+	queue := []Hash{h}
+	var pending []*Commit
+
+	for len(log) < n && (len(queue) > 0 || len(pending) > 0) {
+		// Read the queued commits.
+		for _, q := range queue {
+			c, err := r.Commit(q)
+			if err != nil {
+				return log, err
+			}
+			pending = append(pending, c)
+		}
+		queue = queue[:0]
+
+		// Take the most recent pending commit.
+		i := 0
+		for j, c := range pending {
+			if c.Committer.When.After(pending[i].Committer.When) {
+				i = j
+			}
+		}
+		c := pending[i]
+		pending = slices.Delete(pending, i, i+1)
+		log = append(log, c)
+
+		for _, p := range c.Parents {
+			if !seen[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return log, nil
+}
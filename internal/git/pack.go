@@ -0,0 +1,332 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Packfile object types in addition to the base object types.
+const (
+	ofsDeltaObject = 6
+	refDeltaObject = 7
+)
+
+// Maximum depth of delta chains.
+const maxDeltaDepth = 64
+
+// pack represents a packfile with its version 2 index.
+type pack struct {
+	name    string
+	file    *os.File
+	fanout  [256]uint32
+	hashes  []byte // Sorted object names.
+	offsets []byte // 4-byte offsets.
+	large   []byte // 8-byte offsets of packs over 2GiB.
+}
+
+// loadPacks opens all packfiles of the repository.
+func (r *Repository) loadPacks() error {
+	matches, err := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range matches {
+		p, err := openPack(strings.TrimSuffix(idx, ".idx"))
+		if err != nil {
+			// Packs can be removed by a concurrent gc.
+			continue
+		}
+		r.packs = append(r.packs, p)
+	}
+	return nil
+}
+
+// reloadPacks closes and reopens the packfiles.
+func (r *Repository) reloadPacks() error {
+	for _, p := range r.packs {
+		p.close()
+	}
+	r.packs = nil
+	return r.loadPacks()
+}
+
+// readPacked reads the object from the packfiles.
+func (r *Repository) readPacked(h Hash) (*object, error) {
+	for _, p := range r.packs {
+		if off, ok := p.find(h); ok {
+			return p.read(r, off, 0)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, h)
+}
+
+// openPack opens the packfile and reads its index, name is the path without extension.
+func openPack(name string) (*pack, error) {
+	idx, err := os.ReadFile(name + ".idx")
+	if err != nil {
+		return nil, err
+	}
+	if len(idx) < 8+256*4 || !bytes.Equal(idx[:4], []byte("\377tOc")) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, fmt.Errorf("%w: unsupported pack index %s.idx", ErrBadObject, name)
+	}
+
+	p := &pack{name: name}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(idx[8+i*4:])
+	}
+	n := int(p.fanout[255])
+	pos := 8 + 256*4
+	if len(idx) < pos+n*(HashSize+4+4) {
+		return nil, fmt.Errorf("%w: truncated pack index %s.idx", ErrBadObject, name)
+	}
+	p.hashes = idx[pos : pos+n*HashSize]
+	pos += n*HashSize + n*4 // skip CRC32 values
+	p.offsets = idx[pos : pos+n*4]
+	pos += n * 4
+	p.large = idx[pos:]
+
+	if p.file, err = os.Open(name + ".pack"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// close closes the packfile.
+func (p *pack) close() error {
+	return p.file.Close()
+}
+
+// find returns the packfile offset of the object.
+func (p *pack) find(h Hash) (int64, bool) {
+	lo := 0
+	if h[0] > 0 {
+		lo = int(p.fanout[h[0]-1])
+	}
+	hi := int(p.fanout[h[0]])
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch c := bytes.Compare(p.hashes[mid*HashSize:(mid+1)*HashSize], h[:]); {
+		case c == 0:
+			return p.offset(mid), true
+		case c < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, false
+}
+
+// offset returns the packfile offset of the i-th object in the index.
+func (p *pack) offset(i int) int64 {
+	off := binary.BigEndian.Uint32(p.offsets[i*4:])
+	if off&0x80000000 == 0 {
+		return int64(off)
+	}
+	j := int(off & 0x7fffffff)
+	return int64(binary.BigEndian.Uint64(p.large[j*8:]))
+}
+
+// entryHeader represents the header of a packfile entry.
+type entryHeader struct {
+	typ     int
+	size    int64 // Inflated size of the entry data.
+	baseOff int64 // Base object offset for offset deltas.
+	baseRef Hash  // Base object name for reference deltas.
+	data    *bufio.Reader
+}
+
+// header reads the entry header at the offset. The returned reader is
+// positioned at the compressed entry data.
+func (p *pack) header(off int64) (entryHeader, error) {
+	var e entryHeader
+	br := bufio.NewReader(io.NewSectionReader(p.file, off, 1<<62))
+
+	c, err := br.ReadByte()
+	if err != nil {
+		return e, err
+	}
+	e.typ = int(c>>4) & 7
+	e.size = int64(c & 15)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return e, err
+		}
+		e.size |= int64(c&0x7f) << shift
+	}
+
+	switch e.typ {
+	case ofsDeltaObject:
+		c, err = br.ReadByte()
+		if err != nil {
+			return e, err
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return e, err
+			}
+			rel = (rel+1)<<7 | int64(c&0x7f)
+		}
+		e.baseOff = off - rel
+	case refDeltaObject:
+		if _, err = io.ReadFull(br, e.baseRef[:]); err != nil {
+			return e, err
+		}
+	}
+	e.data = br
+	return e, nil
+}
+
+// inflate decompresses the entry data.
+func (e entryHeader) inflate() ([]byte, error) {
+	zr, err := zlib.NewReader(e.data)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	data := make([]byte, e.size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// read reads and resolves the object at the offset.
+func (p *pack) read(r *Repository, off int64, depth int) (*object, error) {
+	if depth > maxDeltaDepth {
+		return nil, fmt.Errorf("%w: delta chain is too long in %s.pack", ErrBadObject, p.name)
+	}
+	e, err := p.header(off)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s.pack: %w", ErrBadObject, p.name, err)
+	}
+	data, err := e.inflate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s.pack: %w", ErrBadObject, p.name, err)
+	}
+
+	var base *object
+	switch e.typ {
+	case int(CommitObject), int(TreeObject), int(BlobObject), int(TagObject):
+		return &object{typ: ObjectType(e.typ), data: data}, nil
+	case ofsDeltaObject:
+		base, err = p.read(r, e.baseOff, depth+1)
+	case refDeltaObject:
+		if o, ok := r.cache[e.baseRef]; ok {
+			base = o
+		} else if boff, ok := p.find(e.baseRef); ok {
+			base, err = p.read(r, boff, depth+1)
+		} else {
+			base, err = r.readLoose(e.baseRef)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown pack entry type %d", ErrBadObject, e.typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := applyDelta(base.data, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s.pack: %w", ErrBadObject, p.name, err)
+	}
+	return &object{typ: base.typ, data: patched}, nil
+}
+
+// objectSize returns the size of the object at the offset, reading only the
+// delta header for deltified objects.
+func (p *pack) objectSize(off int64) (int64, error) {
+	e, err := p.header(off)
+	if err != nil {
+		return 0, err
+	}
+	if e.typ != ofsDeltaObject && e.typ != refDeltaObject {
+		return e.size, nil
+	}
+	zr, err := zlib.NewReader(e.data)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+	if _, err := binary.ReadUvarint(br); err != nil {
+		return 0, err
+	}
+	size, err := binary.ReadUvarint(br)
+	return int64(size), err
+}
+
+// applyDelta applies the git delta to the base object content.
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	r := bytes.NewReader(delta)
+	srcSize, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if srcSize != uint64(len(base)) {
+		return nil, errors.New("delta base size mismatch")
+	}
+	dstSize, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, dstSize)
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+		switch {
+		case op&0x80 != 0:
+			// Copy from the base object.
+			var off, size uint64
+			for i := 0; i < 4; i++ {
+				if op&(1<<i) != 0 {
+					b, err := r.ReadByte()
+					if err != nil {
+						return nil, err
+					}
+					off |= uint64(b) << (8 * i)
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if op&(1<<(4+i)) != 0 {
+					b, err := r.ReadByte()
+					if err != nil {
+						return nil, err
+					}
+					size |= uint64(b) << (8 * i)
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if off+size > uint64(len(base)) {
+				return nil, errors.New("delta copy out of range")
+			}
+			out = append(out, base[off:off+size]...)
+		case op != 0:
+			// Insert literal data.
+			n := int(op)
+			if r.Len() < n {
+				return nil, errors.New("delta insert out of range")
+			}
+			start := len(delta) - r.Len()
+			out = append(out, delta[start:start+n]...)
+			r.Seek(int64(n), io.SeekCurrent)
+		default:
+			return nil, errors.New("bad delta opcode")
+		}
+	}
+	if uint64(len(out)) != dstSize {
+		return nil, errors.New("delta result size mismatch")
+	}
+	return out, nil
+}
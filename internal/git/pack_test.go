@@ -0,0 +1,235 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The testdata packs hold the objects of a repository of 3 commits changing a
+// few lines of a large file, where the last version is the delta base of the
+// previous ones:
+//
+//	git rev-list --objects --all | git pack-objects --delta-base-offset ofs
+//	git rev-list --objects --all | git pack-objects --no-delta-base-offset ref
+//
+// with the packs renamed to ofs.pack and ref.pack, and their indexes.
+const (
+	fixtureHead    = "79de2a1823c5056e8669ab38689ff9aaf1288342"
+	fixtureObjects = 12
+	fixtureDeltas  = 2
+)
+
+// openTestPack opens the testdata pack, closed at the end of the test.
+func openTestPack(t *testing.T, name string) *pack {
+	t.Helper()
+	p, err := openPack(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.close() })
+	return p
+}
+
+// objectHash returns the object name of the object.
+func objectHash(o *object) Hash {
+	return Hash(sha1.Sum(append(fmt.Appendf(nil, "%s %d\x00", o.typ, len(o.data)), o.data...)))
+}
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		name  string
+		delta int
+	}{
+		{"ofs", ofsDeltaObject},
+		{"ref", refDeltaObject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := openTestPack(t, tt.name)
+			r := &Repository{cache: make(map[Hash]*object)}
+			n := int(p.fanout[255])
+			if n != fixtureObjects {
+				t.Fatalf("index has %d objects, want %d", n, fixtureObjects)
+			}
+
+			deltas := 0
+			for i := range n {
+				h := Hash(p.hashes[i*HashSize : (i+1)*HashSize])
+				off, ok := p.find(h)
+				if !ok || off != p.offset(i) {
+					t.Fatalf("find %s: offset %d, %t, want %d", h, off, ok, p.offset(i))
+				}
+				e, err := p.header(off)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if e.typ == tt.delta {
+					deltas++
+				}
+
+				// The resolved object must hash to its name.
+				o, err := p.read(r, off, 0)
+				if err != nil {
+					t.Fatalf("read %s: %v", h, err)
+				}
+				if got := objectHash(o); got != h {
+					t.Errorf("object %s hashes to %s", h, got)
+				}
+				if size, err := p.objectSize(off); err != nil || size != int64(len(o.data)) {
+					t.Errorf("object %s size %d, %v, want %d", h, size, err, len(o.data))
+				}
+			}
+			if deltas != fixtureDeltas {
+				t.Errorf("got %d deltas of type %d, want %d", deltas, tt.delta, fixtureDeltas)
+			}
+			if _, ok := p.find(Hash{0xff}); ok {
+				t.Error("found missing object")
+			}
+		})
+	}
+}
+
+func TestRepositoryPacked(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"HEAD":            "ref: refs/heads/main\n",
+		"refs/heads/main": fixtureHead + "\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "objects", "pack"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{".idx", ".pack"} {
+		data, err := os.ReadFile(filepath.Join("testdata", "ofs"+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "objects", "pack", "pack-fixture"+ext), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := r.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := r.Log(head.Hash, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, c.Subject())
+	}
+	if fmt.Sprint(subjects) != "[v3 v2 v1]" {
+		t.Errorf("log subjects %v, want [v3 v2 v1]", subjects)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.packs) != 0 || len(r.cache) != 0 {
+		t.Errorf("closed repository has %d packs and %d cached objects", len(r.packs), len(r.cache))
+	}
+}
+
+func TestPackHeader(t *testing.T) {
+	const off = 20000
+	var ref Hash
+	for i := range ref {
+		ref[i] = byte(i + 1)
+	}
+	tests := []struct {
+		name  string
+		entry []byte
+		want  entryHeader
+		err   bool
+	}{
+		{"small size", []byte{0x35}, entryHeader{typ: int(BlobObject), size: 5}, false},
+		{"size varint", []byte{0x94, 0xa3, 0x02}, entryHeader{typ: int(CommitObject), size: 0x1234}, false},
+		{"ofs delta", []byte{0x63, 0x48}, entryHeader{typ: ofsDeltaObject, size: 3, baseOff: off - 72}, false},
+		{"ofs delta 2 bytes", []byte{0x63, 0x80, 0x48}, entryHeader{typ: ofsDeltaObject, size: 3, baseOff: off - 200}, false},
+		{"ofs delta max 2 bytes", []byte{0x63, 0xff, 0x7f}, entryHeader{typ: ofsDeltaObject, size: 3, baseOff: off - 16511}, false},
+		{"ref delta", append([]byte{0x73}, ref[:]...), entryHeader{typ: refDeltaObject, size: 3, baseRef: ref}, false},
+		{"truncated size", []byte{0x95}, entryHeader{}, true},
+		{"truncated offset", []byte{0x63, 0x80}, entryHeader{}, true},
+		{"truncated ref", []byte{0x73, 1, 2}, entryHeader{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "test.pack")
+			if err := os.WriteFile(name, append(make([]byte, off), tt.entry...), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			p := &pack{name: "test", file: f}
+
+			e, err := p.header(off)
+			if (err != nil) != tt.err {
+				t.Fatalf("got error %v, want error %t", err, tt.err)
+			}
+			e.data = nil
+			if !tt.err && e != tt.want {
+				t.Errorf("got %+v, want %+v", e, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello, world")
+	long := bytes.Repeat([]byte("0123456789"), 30)
+	huge := bytes.Repeat([]byte{'x'}, 0x10000)
+	tests := []struct {
+		name  string
+		base  []byte
+		delta []byte
+		want  string
+		err   bool
+	}{
+		{"copy", base, []byte{12, 12, 0x90, 12}, "hello, world", false},
+		{"copy and insert", base, []byte{12, 6, 0x91, 7, 5, 0x01, '!'}, "world!", false},
+		{"insert", base, []byte{12, 3, 0x03, 'a', 'b', 'c'}, "abc", false},
+		{"copy offset byte 2", long, []byte{0xac, 0x02, 4, 0x92, 0x01, 4}, "6789", false},
+		{"copy size 0", huge, []byte{0x80, 0x80, 0x04, 0x80, 0x80, 0x04, 0x80}, string(huge), false},
+		{"empty", base, []byte{12, 0}, "", false},
+		{"base size mismatch", base, []byte{11, 12, 0x90, 12}, "", true},
+		{"result size mismatch", base, []byte{12, 5, 0x90, 12}, "", true},
+		{"copy out of range", base, []byte{12, 1, 0x91, 12, 1}, "", true},
+		{"insert out of range", base, []byte{12, 3, 0x03, 'a'}, "", true},
+		{"bad opcode", base, []byte{12, 0, 0x00}, "", true},
+		{"truncated size", base, []byte{0x80}, "", true},
+		{"truncated copy", base, []byte{12, 5, 0x91}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyDelta(tt.base, tt.delta)
+			if (err != nil) != tt.err {
+				t.Fatalf("got error %v, want error %t", err, tt.err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+// Package git implements a read-only reader of on-disk git repositories:
+// references, loose objects and packfiles.
+package git
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Errors related to repository operations.
+var (
+	ErrNotRepository  = errors.New("not a git repository")
+	ErrObjectNotFound = errors.New("git object not found")
+	ErrRefNotFound    = errors.New("git reference not found")
+	ErrBadObject      = errors.New("bad git object")
+)
+
+// HashSize is the size of a SHA-1 object name in bytes.
+const HashSize = 20
+
+// Hash represents a git object name.
+type Hash [HashSize]byte
+
+// ParseHash parses a hex encoded object name.
+func ParseHash(s string) (h Hash, err error) {
+	if len(s) != 2*HashSize {
+		return h, fmt.Errorf("bad object name %q", s)
+	}
+	if _, err = hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("bad object name %q: %w", s, err)
+	}
+	return h, nil
+}
+
+// String returns the hex encoded object name.
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// IsZero reports whether the hash is the zero object name.
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// Ref represents a git reference.
+type Ref struct {
+	Name   string // Full name of the reference, e.g. refs/heads/main.
+	Hash   Hash   // Object the reference points to.
+	Peeled Hash   // Commit an annotated tag points to, zero for other references.
+}
+
+// Target returns the commit the reference points to, peeling annotated tags.
+func (r Ref) Target() Hash {
+	if !r.Peeled.IsZero() {
+		return r.Peeled
+	}
+	return r.Hash
+}
+
+// Repository represents an on-disk git repository.
+type Repository struct {
+	path      string // Work tree path, or the git directory for bare repositories.
+	gitDir    string // Git directory with HEAD.
+	commonDir string // Git directory with objects and refs, differs from gitDir for worktrees.
+
+	mu    sync.Mutex       // Protects packs and cache.
+	packs []*pack          // Opened packfiles.
+	cache map[Hash]*object // Recently read objects.
+}
+
+// Open opens the git repository at path. The path can be a work tree with
+// a .git directory or file, or a bare repository.
+func Open(path string) (*Repository, error) {
+	path = filepath.Clean(path)
+	r := &Repository{path: path, cache: make(map[Hash]*object)}
+
+	dotGit := filepath.Join(path, ".git")
+	fi, err := os.Stat(dotGit)
+	switch {
+	case err == nil && fi.IsDir():
+		r.gitDir = dotGit
+	case err == nil:
+		// A .git file points to the git directory of a linked worktree or submodule.
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return nil, err
+		}
+		dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotRepository, path)
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(path, dir)
+		}
+		r.gitDir = filepath.Clean(dir)
+	default:
+		// Bare repository.
+		r.gitDir = path
+	}
+
+	if _, err := os.Stat(filepath.Join(r.gitDir, "HEAD")); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, path)
+	}
+
+	r.commonDir = r.gitDir
+	if data, err := os.ReadFile(filepath.Join(r.gitDir, "commondir")); err == nil {
+		dir := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(r.gitDir, dir)
+		}
+		r.commonDir = filepath.Clean(dir)
+	}
+
+	if err := r.loadPacks(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the work tree path of the repository.
+func (r *Repository) Path() string {
+	return r.path
+}
+
+// GitDir returns the git directory of the repository.
+func (r *Repository) GitDir() string {
+	return r.gitDir
+}
+
+// CommonDir returns the git directory holding objects and refs.
+func (r *Repository) CommonDir() string {
+	return r.commonDir
+}
+
+// Close closes the opened packfiles.
+func (r *Repository) Close() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.packs {
+		err = errors.Join(err, p.close())
+	}
+	r.packs = nil
+	clear(r.cache)
+	return
+}
+
+// Head returns the reference HEAD points to. For a detached HEAD the
+// reference name is HEAD.
+func (r *Repository) Head() (Ref, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return Ref{}, err
+	}
+	line := strings.TrimSpace(string(data))
+	if name, ok := strings.CutPrefix(line, "ref: "); ok {
+		return r.Ref(name)
+	}
+	h, err := ParseHash(line)
+	return Ref{Name: "HEAD", Hash: h}, err
+}
+
+// Ref resolves the reference by its full name.
+func (r *Repository) Ref(name string) (Ref, error) {
+	refs, err := r.Refs()
+	if err != nil {
+		return Ref{}, err
+	}
+	i, ok := slices.BinarySearchFunc(refs, name, func(ref Ref, name string) int {
+		return strings.Compare(ref.Name, name)
+	})
+	if !ok {
+		return Ref{Name: name}, fmt.Errorf("%w: %s", ErrRefNotFound, name)
+	}
+	return refs[i], nil
+}
+
+// Refs returns all references under refs/ sorted by name. Loose references
+// take precedence over packed ones, symbolic references are resolved and
+// annotated tags are peeled.
+func (r *Repository) Refs() ([]Ref, error) {
+	refs := make(map[string]Ref)
+
+	// Packed references.
+	if err := r.readPackedRefs(refs); err != nil {
+		return nil, err
+	}
+
+	// Loose references, including the per worktree ones.
+	symbolic := make(map[string]string)
+	for _, dir := range slices.Compact([]string{r.commonDir, r.gitDir}) {
+		err := filepath.WalkDir(filepath.Join(dir, "refs"), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, p)
+			name := filepath.ToSlash(rel)
+			line := strings.TrimSpace(string(data))
+			if target, ok := strings.CutPrefix(line, "ref: "); ok {
+				symbolic[name] = target
+				return nil
+			}
+			h, err := ParseHash(line)
+			if err != nil {
+				return nil
+			}
+			refs[name] = Ref{Name: name, Hash: h}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve symbolic references like refs/remotes/origin/HEAD.
+	for name, target := range symbolic {
+		for i := 0; i < 5; i++ {
+			if next, ok := symbolic[target]; ok {
+				target = next
+				continue
+			}
+			break
+		}
+		if ref, ok := refs[target]; ok {
+			ref.Name = name
+			refs[name] = ref
+		}
+	}
+
+	list := make([]Ref, 0, len(refs))
+	for _, ref := range refs {
+		// Peel annotated tags missing from packed-refs.
+		if ref.Peeled.IsZero() && strings.HasPrefix(ref.Name, "refs/tags/") {
+			if h, err := r.Peel(ref.Hash); err == nil && h != ref.Hash {
+				ref.Peeled = h
+			}
+		}
+		list = append(list, ref)
+	}
+	slices.SortFunc(list, func(a, b Ref) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return list, nil
+}
+
+// readPackedRefs reads the packed-refs file into refs.
+func (r *Repository) readPackedRefs(refs map[string]Ref) error {
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var last string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "^"):
+			// Peeled object of the previous annotated tag.
+			if h, err := ParseHash(line[1:]); err == nil && last != "" {
+				ref := refs[last]
+				ref.Peeled = h
+				refs[last] = ref
+			}
+			continue
+		}
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		h, err := ParseHash(hash)
+		if err != nil {
+			continue
+		}
+		refs[name] = Ref{Name: name, Hash: h}
+		last = name
+	}
+	return s.Err()
+}
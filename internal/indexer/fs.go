@@ -1,19 +1,22 @@
 package indexer
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"mime"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/mimeinfo"
 	"github.com/shtirlic/knotidx/internal/store"
 )
 
@@ -33,8 +36,22 @@ type FileSystemIndexer struct {
 	RootPath           string                  // RootPath is the root directory path to be indexed.
 	ExcludeDirFilters  []string                // ExcludeDirFilters contains filters for excluding directories.
 	ExcludeFileFilters []string                // ExcludeFileFilters contains filters for excluding files.
+	IncludeDirFilters  []string                // IncludeDirFilters contains filters for directories to index, empty for all.
+	IncludeFileFilters []string                // IncludeFileFilters contains filters for files to index, empty for all.
 	Store              store.Store             // Store is the data store to index items.
+	Refresh            time.Duration           // Refresh is the interval between polls of directories that can't be watched.
 	watcher            *fsnotify.Watcher       // watcher is used to monitor file system events.
+	addWatch           func(dir string) error  // addWatch adds the directory to the watcher, failing with ENOSPC beyond the inotify watch limit.
+	watches            atomic.Int64            // watches is the number of directories watched with inotify.
+	watchMu            sync.Mutex              // watchMu protects unwatched and watchLimit.
+	watchLimit         bool                    // watchLimit is set once the inotify watch limit is reached.
+	unwatched          map[string]bool         // unwatched directories beyond the inotify watch limit, true if watched with fanotify.
+	fanotify           *fanotify               // fanotify watches unwatched directories if enabled, nil until needed.
+	fanotifyErr        error                   // fanotifyErr is the error creating the fanotify group.
+	fanotifyMu         sync.Mutex              // fanotifyMu protects fanotify and fanotifyErr.
+	fanotifyEvents     chan fsnotify.Event     // fanotifyEvents receives the events of fanotify.
+	ignore             *ignoreMatcher          // ignore evaluates .gitignore and .knotidxignore files, nil if disabled.
+	mime               *mimeinfo.Database      // mime sniffs MIME types by content, nil for extension only detection.
 	config             config.IndexerConfig    // config is the configuration for the indexer.
 	ctx                context.Context         // ctx is the cancel conext.
 	info               info                    // runtime info
@@ -46,60 +63,86 @@ func (idx *FileSystemIndexer) Feedback() chan IndexerRuntimeInfo {
 	return idx.feedback
 }
 
-type info struct {
-	Dirs   int
-	Files  int
-	Failed int
-	Total  int
-	IndexerRuntimeInfo
-}
-
 // Info returns runtime info.
 func (idx *FileSystemIndexer) Info() IndexerRuntimeInfo {
-	return idx.info.IndexerRuntimeInfo
+	info := idx.info.get()
+	info.Watches = int(idx.watches.Load())
+	info.Unwatched = idx.unwatchedDirs()
+	return info
 }
 
 // Watch monitors the file system for events and updates the index accordingly.
+// Events are coalesced per path and handled after a quiet period, or after a
+// maximum delay during bursts like checkouts or extractions. Directories that
+// can't be watched are polled for changes periodically.
 func (idx *FileSystemIndexer) Watch() {
 
-	// Check if the watcher is initialized.
-	if idx.watcher == nil {
+	// Check if notifications are enabled.
+	if !idx.config.Notify {
 		return
 	}
-	// Close the watcher when the function exits.
-	defer idx.watcher.Close()
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if idx.watcher != nil {
+		// Close the watcher when the function exits.
+		defer idx.watcher.Close()
+		events, errs = idx.watcher.Events, idx.watcher.Errors
+	}
+	defer idx.closeFanotify()
 
 	slog.Debug("Watcher events select", "idx RootPath", idx.RootPath)
 
+	batch := newEventBatch(watchDebounce, watchMaxDelay)
+	defer batch.timer.Stop()
+
+	// Ticker polling the unwatched directories.
+	poll := time.NewTicker(idx.Refresh)
+	defer poll.Stop()
+
 	// Infinite loop to continuously monitor file system events.
 	for {
+		var event fsnotify.Event
 		select {
 		// Handle file system events.
-		case event, ok := <-idx.watcher.Events:
+		case e, ok := <-events:
 			if !ok {
 				return
 			}
-			slog.Debug("Watcher", "event", event)
-
-			// Process different types of file system events.
-			switch event.Op {
-			case fsnotify.Chmod, fsnotify.Write, fsnotify.Create:
-				idx.addPath(event.Name) // Add or update the path in the index.
-			case fsnotify.Remove, fsnotify.Rename:
-				idx.removePath(event.Name) // Remove the path from the index.
+			event = e
+		case event = <-idx.fanotifyEvents:
+			if event.Name == "" {
+				// fanotify events were lost.
+				idx.poll(true)
+				continue
 			}
+
+		// Handle the pending events.
+		case <-batch.timer.C:
+			idx.handleEvents(batch.take())
+			idx.countWatches()
+			continue
+
+		// Poll the unwatched directories.
+		case <-poll.C:
+			idx.poll(false)
+			continue
+
 		// Handle errors from the watcher.
-		case err, ok := <-idx.watcher.Errors:
+		case err, ok := <-errs:
 			if !ok {
 				return
 			}
 			slog.Debug("Watcher", "error", err)
+			continue
 
 		// check for contex done channel for cancel.
 		case <-idx.ctx.Done():
 			slog.Debug("Quit watcher", "idx RootPath", idx.RootPath)
 			return
 		}
+
+		slog.Debug("Watcher", "event", event)
+		batch.add(event)
 	}
 }
 
@@ -108,6 +151,11 @@ func (idx *FileSystemIndexer) Type() IndexerType {
 	return FileSystemIndexerType
 }
 
+// Root returns the root path of the FileSystemIndexer.
+func (idx *FileSystemIndexer) Root() string {
+	return idx.RootPath
+}
+
 // Config returns the configuration of the FileSystemIndexer.
 func (idx *FileSystemIndexer) Config() config.IndexerConfig {
 	return idx.config
@@ -133,19 +181,47 @@ func NewFileSystemIndexer(ctx context.Context, store store.Store, rootPath strin
 		RootPath:           filepath.Clean(rootPath),
 		ExcludeDirFilters:  excludeDirFilters,
 		ExcludeFileFilters: excludeFileFilters,
+		IncludeDirFilters:  c.IncludeDirFilters,
+		IncludeFileFilters: c.IncludeFileFilters,
 		Store:              store,
 		config:             c,
 		ctx:                ctx,
-		info: info{Dirs: 0, Files: 0, Failed: 0, Total: 0,
-			IndexerRuntimeInfo: IndexerRuntimeInfo{Status: "Created"}},
-		feedback: make(chan IndexerRuntimeInfo, 1),
+		info:               info{IndexerRuntimeInfo: IndexerRuntimeInfo{Status: "Created"}},
+		feedback:           make(chan IndexerRuntimeInfo, 1),
+		unwatched:          make(map[string]bool),
+		fanotifyEvents:     make(chan fsnotify.Event, 64),
+	}
+	refresh := c.Refresh
+	if refresh == 0 {
+		refresh = DefaultFSRefresh
+	}
+	fsi.Refresh = time.Duration(refresh) * time.Second
+
+	// Honour ignore files if enabled in the configuration.
+	if c.IgnoreFiles {
+		fsi.ignore = newIgnoreMatcher(fsi.RootPath)
+	}
+
+	// Sniff MIME types by content if enabled in the configuration.
+	switch c.MimeDetection {
+	case MimeDetectionContent:
+		fsi.mime = mimeDatabase(c.MimeInfoPaths)
+	case "", MimeDetectionExtension:
+	default:
+		slog.Warn("mime detection mode is unknown, using extensions", "mode", c.MimeDetection)
 	}
 
 	// Enable fsnotify watcher if Notify is true in the configuration.
 	if c.Notify {
-		fsi.watcher, _ = fsnotify.NewWatcher()
+		var err error
+		if fsi.watcher, err = fsnotify.NewWatcher(); err != nil {
+			// Poll the whole tree without inotify, e.g. beyond fs.inotify.max_user_instances.
+			slog.Warn("Can't create watcher, polling for changes", "root", fsi.RootPath, "error", err)
+			fsi.unwatched[fsi.RootPath] = false
+		} else {
+			fsi.addWatch = fsi.watcher.Add
+		}
 	}
-	// TODO: Handle error returned from fsnotify.NewWatcher().
 
 	// Returning the created FileSystemIndexer.
 	return fsi
@@ -180,9 +256,19 @@ func (idx *FileSystemIndexer) CleanIndex(prefix string) error {
 			slog.Debug("CleanIndex", "key", key, "path", path, "err", err)
 			if err = idx.Store.Delete(key); err != nil {
 				return err
-			} else {
-				continue
 			}
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Removed++ })
+			continue
+		}
+
+		// Delete the items of the root skipped by the filters, e.g. after they changed.
+		if Within(path, idx.RootPath) && !idx.indexed(path, store.ItemType(item[1]) == DirItemType) {
+			slog.Debug("CleanIndex filtered", "key", key, "path", path)
+			if err = idx.Store.Delete(key); err != nil {
+				return err
+			}
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Removed++ })
+			continue
 		}
 
 		switch store.ItemType(item[1]) {
@@ -194,6 +280,7 @@ func (idx *FileSystemIndexer) CleanIndex(prefix string) error {
 				if err = idx.Store.Delete(key); err != nil {
 					return err
 				}
+				idx.info.update(func(info *IndexerRuntimeInfo) { info.Removed++ })
 			}
 		case FileItemType:
 			if fi.IsDir() && store.ItemType(item[1]) != DirItemType {
@@ -203,6 +290,7 @@ func (idx *FileSystemIndexer) CleanIndex(prefix string) error {
 				if err = idx.Store.Delete(key); err != nil {
 					return err
 				}
+				idx.info.update(func(info *IndexerRuntimeInfo) { info.Removed++ })
 			}
 		}
 	}
@@ -215,34 +303,155 @@ func (idx *FileSystemIndexer) CleanIndex(prefix string) error {
 }
 
 // UpdateIndex updates the index by first cleaning it to remove stale entries
-// and then adding the paths starting from the root path.
+// and then adding the paths starting from the root path. In incremental mode
+// it only rewrites changed items, checks changed directories for removed
+// entries and skips the subtrees of unchanged directories, unless the filters
+// changed since the last update.
 func (idx *FileSystemIndexer) UpdateIndex() (time.Duration, error) {
 
 	startTime := time.Now()
-	idx.info.StartTime = startTime
-	idx.info.Status = "Started"
+	idx.info.update(func(info *IndexerRuntimeInfo) {
+		info.StartTime = startTime
+		info.Status = "Started"
+		info.Skipped, info.Updated, info.Removed = 0, 0, 0
+	})
 	idx.feedback <- idx.Info()
 
-	// Clean the index to remove stale entries.
-	if err := idx.CleanIndex(""); err != nil {
-		return 0, err
+	// Clean the index to remove stale and filtered entries, the incremental
+	// mode removes them while walking changed directories instead. Items
+	// filtered or included by changed filters can be in unchanged directories,
+	// so the whole index is cleaned and walked again when they change.
+	filters := idx.filtersState()
+	incremental := idx.config.Incremental && bytes.Equal(idx.Store.State(StateName(idx.Type(), idx.RootPath)), filters)
+	if !incremental {
+		if err := idx.CleanIndex(""); err != nil {
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Status = "Failed" })
+			return 0, err
+		}
 	}
 
 	// Add the root path and its subdirectories to the index.
-	if err := idx.addPath(idx.RootPath); err != nil {
+	if err := idx.walkPath(idx.RootPath, incremental); err != nil {
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Status = "Failed" })
 		return 0, err
 	}
+	if err := idx.Store.SetState(StateName(idx.Type(), idx.RootPath), filters); err != nil {
+		slog.Error("Can't save the filters state", "root", idx.RootPath, "error", err)
+	}
+
+	// Report the directories that can't be watched.
+	idx.countWatches()
+	if unwatched := idx.unwatchedDirs(); len(unwatched) > 0 {
+		slog.Warn("Directories can't be watched with inotify", "root", idx.RootPath,
+			"unwatched", unwatched, "watches", idx.watches.Load(), "refresh", idx.Refresh)
+	}
 
-	idx.info.Status = "Finished"
+	idx.info.finish(idx.ctx)
 	idx.feedback <- idx.Info()
 	close(idx.feedback)
 
 	// Return nil to indicate a successful update.
-	idx.info.FinishTime = time.Now()
-	idx.info.Duration = idx.info.FinishTime.Sub(idx.info.StartTime)
 	return time.Since(startTime), nil
 }
 
+// newItemInfo creates the ItemInfo of the file system entry with its MIME type,
+// its media metadata, its extended user attributes and its hash.
+func (idx *FileSystemIndexer) newItemInfo(path string, info os.FileInfo) store.ItemInfo {
+	itemInfo := store.NewItemInfo(
+		info.Name(),
+		path,
+		info.ModTime(),
+		info.Size(),
+		ItemType(info.IsDir()))
+
+	// Get the mimetype for files.
+	if itemInfo.Type == FileItemType {
+		itemInfo.MimeType = idx.mimeType(path, info)
+		if info.Mode().IsRegular() {
+			itemInfo.Media = idx.mediaInfo(path, itemInfo.MimeType)
+		}
+	}
+	itemInfo.XAttrs = readXAttrs(path)
+
+	// Calculate the hash.
+	itemInfo.Hash = itemInfo.XXhash()
+	return itemInfo
+}
+
+// unchanged returns the item stored under the key and reports whether its
+// hash matches the one of the file info, so it doesn't need to be read again.
+// The hash is computed from the file info and the extended attributes, with
+// the MIME type and the metadata of the stored item read from the content.
+// Directories whose modification time changed have their stored entries
+// checked for removals, as entries were added, removed or renamed.
+func (idx *FileSystemIndexer) unchanged(key string, info os.FileInfo) (store.ItemInfo, bool) {
+	stored := idx.Store.Find(key)
+	if stored.Path == "" {
+		return stored, false
+	}
+	if !stored.ModTime.Equal(info.ModTime()) {
+		if info.IsDir() {
+			idx.removeMissing(stored.Path)
+		}
+		return stored, false
+	}
+	item := store.NewItemInfo(info.Name(), stored.Path, info.ModTime(), info.Size(), ItemType(info.IsDir()))
+	item.MimeType, item.Meta, item.Media = stored.MimeType, stored.Meta, stored.Media
+	item.XAttrs = readXAttrs(stored.Path)
+	return stored, item.XXhash() == stored.Hash
+}
+
+// skipSubtree watches the stored subdirectories of the unchanged directory,
+// which isn't walked into, and returns the number of stored directories and
+// files below it.
+func (idx *FileSystemIndexer) skipSubtree(dir string) (dirs int, files int) {
+	dir = strings.TrimSuffix(dir, "/")
+	prefix := fmt.Sprintf("%s_%s_%s/", idx.Type(), DirItemType, dir)
+	idx.watch(dir)
+	for _, key := range idx.Store.Keys(prefix, "", 0) {
+		idx.watch(strings.SplitN(key, "_", 3)[2])
+		dirs++
+	}
+	files = idx.Store.Count(fmt.Sprintf("%s_%s_%s/", idx.Type(), FileItemType, dir))
+	return
+}
+
+// removeMissing removes the index entries of the directory entries that no
+// longer exist or changed their type, with their subtrees.
+func (idx *FileSystemIndexer) removeMissing(dir string) {
+	for _, itemType := range []store.ItemType{DirItemType, FileItemType} {
+		prefix := fmt.Sprintf("%s_%s_%s/", idx.Type(), itemType, strings.TrimSuffix(dir, "/"))
+		for _, key := range idx.Store.Children(prefix) {
+			path := strings.SplitN(key, "_", 3)[2]
+			if fi, err := os.Lstat(path); err == nil && ItemType(fi.IsDir()) == itemType {
+				continue
+			}
+			idx.removePath(path)
+		}
+	}
+}
+
+// updateItem updates the single index entry of the path without walking into
+// directories, e.g. after its attributes changed.
+func (idx *FileSystemIndexer) updateItem(path string) {
+	path = filepath.Clean(path)
+	info, err := os.Lstat(path)
+	if err != nil {
+		slog.Debug("Can't get fileinfo for path:", "error", err, "path", path)
+		return
+	}
+	if idx.filter(path, info.IsDir()) != filterIndex {
+		return
+	}
+
+	itemInfo := idx.newItemInfo(path, info)
+	key := fmt.Sprintf("%s_%s", idx.Type(), itemInfo.KeyName())
+	if err := idx.Store.Add(map[string]store.ItemInfo{key: itemInfo}); err != nil {
+		slog.Error("can't add items to store", "key", key, "error", err)
+	}
+	idx.indexContent(key, itemInfo)
+}
+
 // removePath removes entries from the index associated with the specified path.
 // It cleans both directory and file entries for the given path.
 func (idx *FileSystemIndexer) removePath(path string) {
@@ -255,11 +464,41 @@ func (idx *FileSystemIndexer) removePath(path string) {
 	idx.CleanIndex("file_" + path)
 }
 
+// DeleteFileSystemIndex deletes the items of the fs indexer root and its
+// subtree from the store, except the ones below the kept roots, e.g. of other
+// indexers nested in it. It returns the number of deleted items.
+func DeleteFileSystemIndex(s store.Store, root string, keep []string) (int, error) {
+	root = filepath.Clean(root)
+	deleted := 0
+	for _, t := range []store.ItemType{DirItemType, FileItemType} {
+		prefix := fmt.Sprintf("%s_%s_", FileSystemIndexerType, t)
+		for _, key := range s.Keys(prefix+root, "", 0) {
+			path := strings.TrimPrefix(key, prefix)
+			if !Within(path, root) || slices.ContainsFunc(keep, func(k string) bool { return Within(path, k) }) {
+				continue
+			}
+			if err := s.Delete(key); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	s.Maintenance()
+	return deleted, nil
+}
+
 // addPath recursively traverses the file system starting from the specified path and adds
 // directory and file entries to the index. It skips directories based on exclude directory filters
-// and files based on exclude file filters. The function also handles batch insertion of items into
-// the store to improve efficiency.
-func (idx *FileSystemIndexer) addPath(newPath string) (err error) {
+// and files based on exclude file filters. Directories are read and their entries indexed by
+// concurrent workers. The function also handles batch insertion of items into the store to
+// improve efficiency.
+func (idx *FileSystemIndexer) addPath(newPath string) error {
+	return idx.walkPath(newPath, idx.config.Incremental)
+}
+
+// walkPath adds the path and its subtree to the index like addPath, skipping
+// unchanged items if incremental.
+func (idx *FileSystemIndexer) walkPath(newPath string, incremental bool) (err error) {
 
 	// If newPath is empty, use the root path of the indexer.
 	if newPath == "" {
@@ -268,7 +507,7 @@ func (idx *FileSystemIndexer) addPath(newPath string) (err error) {
 	newPath = filepath.Clean(newPath)
 
 	// Retrieve file info for the specified path.
-	path, err := os.Stat(newPath)
+	path, err := os.Lstat(newPath)
 	if err != nil {
 		slog.Error("Can't get fileinfo for path:", "error", err, "path", path)
 		return
@@ -302,82 +541,77 @@ func (idx *FileSystemIndexer) addPath(newPath string) (err error) {
 	// List for failed file/dir items.
 	var failedItems []string
 
-	// Walk the file system starting from the specified path.
-	err = filepath.Walk(newPath, func(path string, info os.FileInfo, err error) error {
+	// Walk the file system starting from the specified path with concurrent workers.
+	workers := idx.config.Workers
+	if workers <= 0 {
+		workers = DefaultWalkWorkers()
+	}
+	ctx, cancel := context.WithCancel(idx.ctx)
+	defer cancel()
+	w := newWalker(ctx, idx, incremental)
+	go w.walk(newPath, path, workers)
 
-		idx.info.Duration = time.Since(idx.info.StartTime)
+	for r := range w.results {
+		// Drain the results of the canceled walk after errors.
+		if err != nil {
+			continue
+		}
+
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Duration = time.Since(info.StartTime) })
 		// check for contex done channel for cancel.
 		select {
 		case <-idx.ctx.Done():
-			return idx.ctx.Err()
+			err = idx.ctx.Err()
+			continue
 		case <-idx.feedback:
 		default:
 			idx.feedback <- idx.Info()
 		}
 
 		// Skip access denied, etc., and add to the failed list.
-		if err != nil {
-			slog.Debug("Inside Walk", "err", err, "path", path)
-			failedItems = append(failedItems, path)
-			idx.info.Failed = len(failedItems)
-			return filepath.SkipDir
-		}
-
-		// Skip dirs via exclude dir filters.
-		if info.IsDir() && slices.Contains(idx.ExcludeDirFilters, info.Name()) {
-			return filepath.SkipDir
+		if r.failed != "" {
+			failedItems = append(failedItems, r.failed)
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Failed = len(failedItems) })
+			continue
 		}
 
-		// TODO: Try to use Match/Glob for file masks
-		// NOTICE: file masks are not working now
-		// Skip files via exclude file filters.
-		if !info.IsDir() && slices.Contains(idx.ExcludeFileFilters, info.Name()) {
-			return nil
-		}
-
-		// Create ItemInfo for index addition.
-		itemInfo := store.NewItemInfo(
-			info.Name(),
-			path,
-			info.ModTime(),
-			info.Size(),
-			ItemType(info.IsDir()))
-
-		// Get the mimetype for files by extension.
-		if itemInfo.Type == FileItemType {
+		if r.item.Type == FileItemType {
 			idxFileSize++
-			idx.info.Files = idxFileSize
-			itemInfo.MimeType = mime.TypeByExtension(filepath.Ext(path))
 		} else {
-			// Add directories to the file system watcher.
-			if idx.watcher != nil {
-				idx.watcher.Add(path)
-			}
 			idxDirSize++
-			idx.info.Dirs = idxDirSize
 		}
-		// Calculate the hash.
-		itemInfo.Hash = itemInfo.XXhash()
+		idxFileSize += r.skippedFiles
+		idxDirSize += r.skippedDirs
+		idxSize += 1 + r.skippedDirs + r.skippedFiles
+
+		// Skip unchanged items in incremental mode.
+		idx.info.update(func(info *IndexerRuntimeInfo) {
+			info.Files, info.Dirs, info.Total = idxFileSize, idxDirSize, idxSize
+			if r.unchanged {
+				info.Skipped += 1 + r.skippedDirs + r.skippedFiles
+			} else {
+				info.Updated++
+			}
+		})
+		if r.unchanged {
+			continue
+		}
 
-		// Create the key for the item in the format "indexerType_path".
-		key := fmt.Sprintf("%s_%s", idx.Type(), itemInfo.KeyName())
 		// Add the item to the items list.
-		itemList[key] = itemInfo
-		idxSize++
-		idx.info.Total = idxSize
+		itemList[r.key] = r.item
 
 		// Add items to the store in batches.
 		if len(itemList) > store.BatchCount {
-			err = idx.Store.Add(itemList)
-			if err != nil {
+			if err = idx.Store.Add(itemList); err != nil {
 				slog.Error("can't add items to store")
-				return err
+				// Stop the walk.
+				cancel()
+				continue
 			}
 			// Clear the items list after successful batch insertion.
 			clear(itemList)
 		}
-		return err
-	})
+	}
 
 	if err != nil && !errors.Is(err, context.Canceled) {
 		slog.Debug("After Walk", "err", err)
@@ -393,7 +627,7 @@ func (idx *FileSystemIndexer) addPath(newPath string) (err error) {
 		}
 	}
 
-	addInfo := fmt.Sprintf("Total: %d, Files: %d, Dirs: %d, Failed: %d, rootPath: %s", idx.info.Total, idx.info.Files, idx.info.Dirs, idx.info.Failed, idx.RootPath)
+	addInfo := fmt.Sprintf("Total: %d, Files: %d, Dirs: %d, Failed: %d, rootPath: %s", idxSize, idxFileSize, idxDirSize, len(failedItems), idx.RootPath)
 	slog.Debug(addInfo)
 
 	if errors.Is(err, context.Canceled) {
@@ -0,0 +1,46 @@
+package indexer
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/shtirlic/knotidx/internal/fulltext"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// DefaultContentMaxSize is the default maximum size in bytes of files whose
+// content is indexed.
+const DefaultContentMaxSize = 10 << 20
+
+// indexContent updates the full-text index entries of the file item stored
+// under the key if content indexing is enabled. Files whose indexed content is
+// up to date are skipped, files that are no longer indexable lose their entries.
+func (idx *FileSystemIndexer) indexContent(key string, item store.ItemInfo) {
+	if !idx.config.Content || item.Type != FileItemType {
+		return
+	}
+	indexed := idx.Store.ContentHash(key)
+	if indexed == item.Hash {
+		return
+	}
+
+	maxSize := idx.config.ContentMaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultContentMaxSize
+	}
+	var terms []string
+	text, err := fulltext.ExtractFile(item.Path, item.MimeType, maxSize)
+	switch {
+	case err == nil:
+		terms = fulltext.Terms(text)
+	case errors.Is(err, fulltext.ErrNotText):
+	default:
+		slog.Debug("Can't extract content", "path", item.Path, "error", err)
+	}
+	if len(terms) == 0 && indexed == "" {
+		return
+	}
+	if err := idx.Store.SetContent(key, item.Hash, terms); err != nil {
+		slog.Error("can't index content", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,241 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/sys/unix"
+)
+
+// fanotifyMask lists the fanotify events of the marked file systems.
+const fanotifyMask = unix.FAN_CREATE | unix.FAN_DELETE | unix.FAN_MOVED_FROM | unix.FAN_MOVED_TO |
+	unix.FAN_MODIFY | unix.FAN_ATTRIB | unix.FAN_ONDIR
+
+// Sizes of the fanotify event records.
+const (
+	fanotifyMetadataSize   = 24 // struct fanotify_event_metadata.
+	fanotifyInfoHeaderSize = 4  // struct fanotify_event_info_header.
+	fanotifyFsidSize       = 8  // __kernel_fsid_t.
+	fileHandleHeaderSize   = 8  // struct file_handle without f_handle.
+)
+
+// fanotify watches whole file systems with fanotify, reporting the changed
+// entries by their directory handle and name. It requires CAP_SYS_ADMIN.
+type fanotify struct {
+	file   *os.File              // fanotify group.
+	events chan<- fsnotify.Event // Events of the entries, an empty name for lost events.
+	filter func(dir string) bool // Reports whether to send the events of entries of the directory.
+	done   chan struct{}         // Closed when the group is closed.
+	mu     sync.Mutex            // Protects mounts.
+	mounts map[string]int        // Directory descriptors to open handles per file system ID.
+}
+
+// newFanotify creates a fanotify group sending the events of the entries of
+// the directories accepted by the filter.
+func newFanotify(events chan<- fsnotify.Event, filter func(dir string) bool) (*fanotify, error) {
+	fd, err := unix.FanotifyInit(unix.FAN_CLASS_NOTIF|unix.FAN_CLOEXEC|unix.FAN_NONBLOCK|unix.FAN_REPORT_DFID_NAME,
+		unix.O_RDONLY|unix.O_LARGEFILE)
+	if err != nil {
+		return nil, err
+	}
+	f := &fanotify{
+		file:   os.NewFile(uintptr(fd), "fanotify"),
+		events: events,
+		filter: filter,
+		done:   make(chan struct{}),
+		mounts: make(map[string]int),
+	}
+	go f.read()
+	return f, nil
+}
+
+// mark watches the file system of the directory.
+func (f *fanotify) mark(dir string) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return err
+	}
+	fsid := fsidKey(st.Fsid.Val)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.mounts[fsid]; ok {
+		return nil
+	}
+	if err := unix.FanotifyMark(int(f.file.Fd()), unix.FAN_MARK_ADD|unix.FAN_MARK_FILESYSTEM, fanotifyMask, unix.AT_FDCWD, dir); err != nil {
+		return err
+	}
+	fd, err := unix.Open(dir, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	f.mounts[fsid] = fd
+	return nil
+}
+
+// close closes the fanotify group, stopping the reading of events.
+func (f *fanotify) close() {
+	close(f.done)
+	f.file.Close()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for fsid, fd := range f.mounts {
+		unix.Close(fd)
+		delete(f.mounts, fsid)
+	}
+}
+
+// read reads the fanotify events until the group is closed.
+func (f *fanotify) read() {
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := f.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				slog.Debug("Can't read fanotify events", "error", err)
+			}
+			return
+		}
+		for b := buf[:n]; len(b) >= fanotifyMetadataSize; {
+			size := int(binary.NativeEndian.Uint32(b))
+			if size < fanotifyMetadataSize || size > len(b) {
+				break
+			}
+			f.event(b[:size])
+			b = b[size:]
+		}
+	}
+}
+
+// event sends the event of the record, resolving the path of the entry.
+func (f *fanotify) event(b []byte) {
+	if b[4] != unix.FANOTIFY_METADATA_VERSION {
+		return
+	}
+	mask := binary.NativeEndian.Uint64(b[8:])
+	if mask&unix.FAN_Q_OVERFLOW != 0 {
+		f.send(fsnotify.Event{})
+		return
+	}
+	path, ok := f.path(b[binary.NativeEndian.Uint16(b[6:]):])
+	if !ok {
+		return
+	}
+	var op fsnotify.Op
+	if mask&(unix.FAN_CREATE|unix.FAN_MOVED_TO) != 0 {
+		op |= fsnotify.Create
+	}
+	if mask&unix.FAN_DELETE != 0 {
+		op |= fsnotify.Remove
+	}
+	if mask&unix.FAN_MOVED_FROM != 0 {
+		op |= fsnotify.Rename
+	}
+	if mask&unix.FAN_MODIFY != 0 {
+		op |= fsnotify.Write
+	}
+	if mask&unix.FAN_ATTRIB != 0 {
+		op |= fsnotify.Chmod
+	}
+	f.send(fsnotify.Event{Name: path, Op: op})
+}
+
+// send sends the event unless the group is closed.
+func (f *fanotify) send(event fsnotify.Event) {
+	select {
+	case f.events <- event:
+	case <-f.done:
+	}
+}
+
+// path returns the path of the entry named by the directory handle and name
+// info record, ok is false if the directory no longer exists or its events
+// are filtered.
+func (f *fanotify) path(b []byte) (path string, ok bool) {
+	if len(b) < fanotifyInfoHeaderSize+fanotifyFsidSize+fileHandleHeaderSize || b[0] != unix.FAN_EVENT_INFO_TYPE_DFID_NAME {
+		return "", false
+	}
+	b = b[:min(int(binary.NativeEndian.Uint16(b[2:])), len(b))]
+	fsid := string(b[fanotifyInfoHeaderSize : fanotifyInfoHeaderSize+fanotifyFsidSize])
+	b = b[fanotifyInfoHeaderSize+fanotifyFsidSize:]
+	size := int(binary.NativeEndian.Uint32(b))
+	if len(b) < fileHandleHeaderSize+size {
+		return "", false
+	}
+	handle := unix.NewFileHandle(int32(binary.NativeEndian.Uint32(b[4:])), b[fileHandleHeaderSize:fileHandleHeaderSize+size])
+	name := b[fileHandleHeaderSize+size:]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	f.mu.Lock()
+	mount, ok := f.mounts[fsid]
+	f.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	fd, err := unix.OpenByHandleAt(mount, handle, unix.O_PATH|unix.O_CLOEXEC)
+	if err != nil {
+		// The directory was removed.
+		return "", false
+	}
+	defer unix.Close(fd)
+	dir, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(fd))
+	if err != nil || !f.filter(dir) {
+		return "", false
+	}
+	if len(name) == 0 || string(name) == "." {
+		return dir, true
+	}
+	return filepath.Join(dir, string(name)), true
+}
+
+// fsidKey returns the map key of the file system ID.
+func fsidKey(val [2]int32) string {
+	var b [fanotifyFsidSize]byte
+	binary.NativeEndian.PutUint32(b[:], uint32(val[0]))
+	binary.NativeEndian.PutUint32(b[4:], uint32(val[1]))
+	return string(b[:])
+}
+
+// fanotifyMark watches the file system of the unwatched directory with
+// fanotify if enabled, reporting whether it's watched.
+func (idx *FileSystemIndexer) fanotifyMark(dir string) bool {
+	if !idx.config.Fanotify {
+		return false
+	}
+	idx.fanotifyMu.Lock()
+	defer idx.fanotifyMu.Unlock()
+	if idx.fanotify == nil {
+		if idx.fanotifyErr != nil {
+			return false
+		}
+		idx.fanotify, idx.fanotifyErr = newFanotify(idx.fanotifyEvents, idx.fanotifyCovered)
+		if idx.fanotifyErr != nil {
+			slog.Warn("Can't watch with fanotify, polling unwatched directories", "root", idx.RootPath, "error", idx.fanotifyErr)
+			return false
+		}
+	}
+	if err := idx.fanotify.mark(dir); err != nil {
+		slog.Warn("Can't watch with fanotify, polling", "path", dir, "error", err)
+		return false
+	}
+	return true
+}
+
+// closeFanotify closes the fanotify group if any.
+func (idx *FileSystemIndexer) closeFanotify() {
+	idx.fanotifyMu.Lock()
+	defer idx.fanotifyMu.Unlock()
+	if idx.fanotify != nil {
+		idx.fanotify.close()
+		idx.fanotify, idx.fanotifyErr = nil, os.ErrClosed
+	}
+}
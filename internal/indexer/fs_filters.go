@@ -1,5 +1,153 @@
 package indexer
 
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// filterResult represents the decision of the indexer filters for a path.
+type filterResult int
+
+const (
+	filterIndex   filterResult = iota // Index the item.
+	filterSkip                        // Skip the item, but walk into the directory.
+	filterSkipDir                     // Skip the item and the directory contents.
+)
+
+// filter applies the exclude filters, ignore files and include filters to the path.
+func (idx *FileSystemIndexer) filter(p string, isDir bool) filterResult {
+	rel, err := filepath.Rel(idx.RootPath, p)
+	if err != nil || rel == "." {
+		// Never filter the root path itself.
+		return filterIndex
+	}
+	rel = filepath.ToSlash(rel)
+
+	if isDir {
+		if matchFilters(idx.ExcludeDirFilters, rel) {
+			return filterSkipDir
+		}
+		if idx.ignore != nil && idx.ignore.ignored(rel, true) {
+			return filterSkipDir
+		}
+		if !idx.includedDir(rel) {
+			return filterSkip
+		}
+		return filterIndex
+	}
+
+	if matchFilters(idx.ExcludeFileFilters, rel) {
+		return filterSkip
+	}
+	if idx.ignore != nil && idx.ignore.ignored(rel, false) {
+		return filterSkip
+	}
+	if len(idx.IncludeFileFilters) > 0 && !matchFilters(idx.IncludeFileFilters, rel) {
+		return filterSkip
+	}
+	if !idx.includedDir(path.Dir(rel)) {
+		return filterSkip
+	}
+	return filterIndex
+}
+
+// indexed reports whether the filters index the path, checking that none of
+// its parent directories below the root is skipped with its contents.
+func (idx *FileSystemIndexer) indexed(p string, isDir bool) bool {
+	if idx.filter(p, isDir) != filterIndex {
+		return false
+	}
+	for dir := filepath.Dir(p); dir != idx.RootPath && Within(dir, idx.RootPath); dir = filepath.Dir(dir) {
+		if idx.filter(dir, true) == filterSkipDir {
+			return false
+		}
+	}
+	return true
+}
+
+// filtersState returns the state of the filters saved in the store after
+// updates, see StateName, to detect filter changes between them.
+func (idx *FileSystemIndexer) filtersState() []byte {
+	b, _ := json.Marshal(struct {
+		ExcludeDirs, ExcludeFiles, IncludeDirs, IncludeFiles []string
+		IgnoreFiles                                          bool
+	}{idx.ExcludeDirFilters, idx.ExcludeFileFilters, idx.IncludeDirFilters, idx.IncludeFileFilters, idx.ignore != nil})
+	return b
+}
+
+// includedDir reports whether the relative directory path or one of its
+// parents matches the include dir filters. All directories are included
+// if there are no include dir filters, and the root path always is, so the
+// files directly under it are indexed.
+func (idx *FileSystemIndexer) includedDir(rel string) bool {
+	if len(idx.IncludeDirFilters) == 0 || rel == "." {
+		return true
+	}
+	for dir := rel; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matchFilters(idx.IncludeDirFilters, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilters reports whether the relative path matches any of the filters.
+func matchFilters(filters []string, rel string) bool {
+	for _, f := range filters {
+		if matchFilter(f, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter reports whether the slash separated relative path matches the
+// glob filter. Filters without a slash match the base name, e.g. "*.o".
+// Filters with a slash match the whole path relative to the indexer root,
+// e.g. "build/*.log" or "/docs"; "**" matches any number of directories,
+// e.g. "**/testdata/**".
+func matchFilter(filter string, rel string) bool {
+	filter = strings.TrimSuffix(filter, "/")
+	if !strings.Contains(filter, "/") {
+		ok, _ := path.Match(filter, path.Base(rel))
+		return ok
+	}
+	filter = strings.TrimPrefix(filter, "/")
+	return matchSegments(strings.Split(filter, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches path segments against pattern segments,
+// where the "**" segment matches zero or more path segments.
+func matchSegments(pattern []string, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated "**" and try every possible split.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range segs {
+				if matchSegments(pattern, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
 func DefaultExcludeDirFilters() []string {
 	return []string{
 		"po",
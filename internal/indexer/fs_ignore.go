@@ -0,0 +1,191 @@
+package indexer
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// IgnoreFileNames lists the ignore files honoured by the FileSystemIndexer,
+// in order of increasing precedence.
+var IgnoreFileNames = []string{".gitignore", ".knotidxignore"}
+
+// ignoreRule represents a single gitignore pattern.
+type ignoreRule struct {
+	segments []string // Pattern split into slash separated segments.
+	negate   bool     // Pattern starts with "!" and re-includes matches.
+	dirOnly  bool     // Pattern ends with "/" and matches directories only.
+	anchored bool     // Pattern contains a slash and matches relative to the ignore file directory.
+}
+
+// match reports whether the path relative to the ignore file directory matches the rule.
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], path.Base(rel))
+		return ok
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+// parseIgnoreRule parses a gitignore line. It returns false for blank lines and comments.
+func parseIgnoreRule(line string) (r ignoreRule, ok bool) {
+	// Trailing spaces are ignored unless escaped with a backslash.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return r, false
+	}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return r, false
+	}
+	r.anchored = strings.Contains(line, "/")
+	r.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return r, true
+}
+
+// ignoreMatcher evaluates the ignore files found under the indexer root path.
+// Parsed ignore files are cached per directory and can be invalidated when
+// they change.
+type ignoreMatcher struct {
+	root  string                  // Indexer root path.
+	mu    sync.Mutex              // Protects rules.
+	rules map[string][]ignoreRule // Rules of the ignore files per relative directory.
+}
+
+// newIgnoreMatcher creates an ignoreMatcher for the root path.
+func newIgnoreMatcher(root string) *ignoreMatcher {
+	return &ignoreMatcher{root: root, rules: make(map[string][]ignoreRule)}
+}
+
+// dirRules returns the cached rules of the ignore files in the relative directory,
+// reading them on first use.
+func (m *ignoreMatcher) dirRules(dir string) []ignoreRule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rules, ok := m.rules[dir]
+	if ok {
+		return rules
+	}
+	for _, name := range IgnoreFileNames {
+		rules = append(rules, readIgnoreFile(filepath.Join(m.root, filepath.FromSlash(dir), name))...)
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// invalidate drops the cached rules of the directory.
+func (m *ignoreMatcher) invalidate(dir string) {
+	rel, err := filepath.Rel(m.root, dir)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	delete(m.rules, filepath.ToSlash(rel))
+	m.mu.Unlock()
+}
+
+// ignored reports whether the slash separated path relative to the root is
+// ignored by itself or through one of its parent directories.
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	segs := strings.Split(rel, "/")
+	for i := 1; i <= len(segs); i++ {
+		// All but the last segment are parent directories.
+		if m.ignoredEntry(segs[:i], isDir || i < len(segs)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredEntry applies the rules of the ignore files in all parent directories
+// of the path, the last matching rule wins.
+func (m *ignoreMatcher) ignoredEntry(segs []string, isDir bool) (ignored bool) {
+	for i := 0; i < len(segs); i++ {
+		dir := "."
+		if i > 0 {
+			dir = strings.Join(segs[:i], "/")
+		}
+		rel := strings.Join(segs[i:], "/")
+		for _, r := range m.dirRules(dir) {
+			if r.match(rel, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return
+}
+
+// readIgnoreFile reads the rules of the ignore file, missing files have no rules.
+func readIgnoreFile(name string) (rules []ignoreRule) {
+	f, err := os.Open(name)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Debug("Can't read ignore file", "path", name, "error", err)
+		}
+		return
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if r, ok := parseIgnoreRule(s.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return
+}
+
+// isIgnoreFile reports whether the path names an ignore file.
+func isIgnoreFile(p string) bool {
+	base := filepath.Base(p)
+	for _, name := range IgnoreFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reapplyIgnore drops the cached ignore rules of the directory, removes the items
+// below it that became ignored and adds the ones that are no longer ignored.
+func (idx *FileSystemIndexer) reapplyIgnore(dir string) {
+	dir = filepath.Clean(dir)
+	idx.ignore.invalidate(dir)
+
+	for _, t := range []store.ItemType{DirItemType, FileItemType} {
+		prefix := fmt.Sprintf("%s_%s_%s", idx.Type(), t, dir)
+		for _, key := range idx.Store.Keys(prefix, "", 0) {
+			p := strings.SplitN(key, "_", 3)[2]
+			if idx.filter(p, t == DirItemType) == filterIndex {
+				continue
+			}
+			if err := idx.Store.Delete(key); err != nil {
+				slog.Error("Can't delete ignored item", "key", key, "error", err)
+			}
+		}
+	}
+	// The directory is unchanged if the ignore file was rewritten, walk it whole.
+	idx.walkPath(dir, false)
+}
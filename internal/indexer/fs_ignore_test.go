@@ -0,0 +1,108 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shtirlic/knotidx/internal/config"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore": "# comment\n" +
+			"*.log\n" +
+			"!keep.log\n" +
+			"build/\n" +
+			"!build/keep.txt\n" +
+			"/root-only.txt\n" +
+			"docs/*.tmp\n" +
+			"\\#hash\n" +
+			"trailing.txt   \n",
+		"sub/.gitignore":     "!important.log\nlocal.txt\ndata.csv\n",
+		"sub/.knotidxignore": "secret*\n!data.csv\n",
+	})
+	m := newIgnoreMatcher(root)
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"a.txt", false, false},
+		{"comment", false, false},
+
+		// Negation, the last matching rule wins.
+		{"a.log", false, true},
+		{"keep.log", false, false},
+		{"sub/a.log", false, true},
+		{"sub/important.log", false, false},
+		{"important.log", false, true},
+
+		// Directory only rules, and the contents of ignored directories,
+		// which can't be re-included.
+		{"build", true, true},
+		{"build", false, false},
+		{"build/x.txt", false, true},
+		{"build/keep.txt", false, true},
+		{"sub/build", true, true},
+		{"sub/build/deep/x.txt", false, true},
+
+		// Anchoring to the directory of the ignore file.
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"docs/a.tmp", false, true},
+		{"docs/deep/a.tmp", false, false},
+		{"sub/docs/a.tmp", false, false},
+
+		// Escapes and trailing spaces.
+		{"#hash", false, true},
+		{"trailing.txt", false, true},
+
+		// Nested ignore files, .knotidxignore over .gitignore.
+		{"sub/local.txt", false, true},
+		{"local.txt", false, false},
+		{"sub/deep/local.txt", false, true},
+		{"sub/secret.key", false, true},
+		{"secret.key", false, false},
+		{"sub/data.csv", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.ignored(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestReapplyIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"sub/.gitignore": "*.log\n",
+		"sub/a.txt":      "a",
+		"sub/b.log":      "b",
+	})
+	sub := filepath.Join(root, "sub")
+	s := newTestStore(t)
+	c := config.IndexerConfig{Type: string(FileSystemIndexerType), Paths: []string{root}, IgnoreFiles: true, Incremental: true}
+	updateIndex(t, s, c)
+	idx := NewFileSystemIndexer(context.Background(), s, root, c).(*FileSystemIndexer)
+
+	indexed := func(name string) bool {
+		return s.Find("fs_file_"+filepath.Join(sub, name)).Path != ""
+	}
+	if !indexed("a.txt") || indexed("b.log") {
+		t.Fatal("ignore file not applied by the update")
+	}
+
+	// Rewriting the ignore file doesn't change the directory, whose items
+	// are checked again anyway.
+	if err := os.WriteFile(filepath.Join(sub, ".gitignore"), []byte("*.txt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx.reapplyIgnore(sub)
+	if indexed("a.txt") || !indexed("b.log") {
+		t.Errorf("changed ignore file: a.txt indexed %v, b.log indexed %v, want false, true", indexed("a.txt"), indexed("b.log"))
+	}
+}
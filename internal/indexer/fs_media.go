@@ -0,0 +1,24 @@
+package indexer
+
+import (
+	"log/slog"
+
+	"github.com/shtirlic/knotidx/internal/metainfo"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// mediaInfo returns the metadata extracted from the content of the file of the
+// MIME type if metadata extraction is enabled, nil if it has none.
+func (idx *FileSystemIndexer) mediaInfo(path string, mimeType string) *store.MediaInfo {
+	if !idx.config.Metadata {
+		return nil
+	}
+	if _, ok := metainfo.Lookup(mimeType); !ok {
+		return nil
+	}
+	info, err := metainfo.Extract(path, mimeType)
+	if err != nil {
+		slog.Debug("Can't extract metadata", "path", path, "mime", mimeType, "error", err)
+	}
+	return info
+}
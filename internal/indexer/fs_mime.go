@@ -0,0 +1,60 @@
+package indexer
+
+import (
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/shtirlic/knotidx/internal/mimeinfo"
+)
+
+// MIME type detection modes of the FileSystemIndexer.
+const (
+	MimeDetectionExtension = "extension" // Guess MIME types by file extension.
+	MimeDetectionContent   = "content"   // Sniff MIME types with the shared-mime-info database.
+)
+
+// mimeDatabases caches the loaded shared-mime-info databases by package directories,
+// as indexers are recreated for each indexing run.
+var mimeDatabases = struct {
+	sync.Mutex
+	m map[string]*mimeinfo.Database
+}{m: make(map[string]*mimeinfo.Database)}
+
+// mimeDatabase returns the shared-mime-info database of the package directories,
+// the XDG ones if empty, or nil if it can't be loaded.
+func mimeDatabase(dirs []string) *mimeinfo.Database {
+	if len(dirs) == 0 {
+		dirs = mimeinfo.DefaultDirs()
+	}
+	key := strings.Join(dirs, string(filepath.ListSeparator))
+
+	mimeDatabases.Lock()
+	defer mimeDatabases.Unlock()
+	if db, ok := mimeDatabases.m[key]; ok {
+		return db
+	}
+	db, err := mimeinfo.Load(dirs...)
+	if err != nil {
+		slog.Error("Can't load shared-mime-info database, using extensions", "dirs", dirs, "error", err)
+	}
+	mimeDatabases.m[key] = db
+	return db
+}
+
+// mimeType returns the MIME type of the file, sniffing the content of regular
+// files if content detection is enabled.
+func (idx *FileSystemIndexer) mimeType(path string, info os.FileInfo) string {
+	if idx.mime == nil || !info.Mode().IsRegular() {
+		return mime.TypeByExtension(filepath.Ext(path))
+	}
+	t, err := idx.mime.DetectFile(path)
+	if err != nil {
+		slog.Debug("Can't detect mime type", "path", path, "error", err)
+		return mime.TypeByExtension(filepath.Ext(path))
+	}
+	return t
+}
@@ -0,0 +1,143 @@
+package indexer
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultFSRefresh is the default interval in seconds between polls of the
+// directories that can't be watched.
+const DefaultFSRefresh = 60
+
+// watch adds the directory to the watcher. Directories that can't be watched
+// as the inotify watch limit is reached are watched with fanotify if enabled,
+// or polled for changes.
+func (idx *FileSystemIndexer) watch(dir string) {
+	if idx.watcher == nil {
+		return
+	}
+	// Directories below unwatched ones are polled or watched with them.
+	if idx.unwatchedAt(dir) {
+		return
+	}
+	err := idx.addWatch(dir)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, unix.ENOSPC) {
+		slog.Debug("Can't watch directory", "path", dir, "error", err)
+		return
+	}
+
+	fanotify := idx.fanotifyMark(dir)
+	idx.watchMu.Lock()
+	first := !idx.watchLimit
+	idx.watchLimit = true
+	idx.unwatched[dir] = fanotify
+	idx.watchMu.Unlock()
+	if first {
+		slog.Warn("inotify watch limit reached, increase fs.inotify.max_user_watches",
+			"root", idx.RootPath, "path", dir, "fanotify", fanotify)
+	}
+	slog.Debug("Directory is unwatched", "path", dir, "fanotify", fanotify)
+}
+
+// unwatchedAt reports whether the directory or one of its parents can't be
+// watched with inotify.
+func (idx *FileSystemIndexer) unwatchedAt(dir string) bool {
+	_, ok := idx.unwatchedParent(dir)
+	return ok
+}
+
+// fanotifyCovered reports whether the directory or one of its parents is
+// watched with fanotify.
+func (idx *FileSystemIndexer) fanotifyCovered(dir string) bool {
+	fanotify, ok := idx.unwatchedParent(dir)
+	return ok && fanotify
+}
+
+// unwatchedParent returns whether the nearest unwatched directory among the
+// directory and its parents is watched with fanotify, ok is false if there
+// is none.
+func (idx *FileSystemIndexer) unwatchedParent(dir string) (fanotify, ok bool) {
+	idx.watchMu.Lock()
+	defer idx.watchMu.Unlock()
+	if len(idx.unwatched) == 0 {
+		return false, false
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		if fanotify, ok = idx.unwatched[d]; ok || d == "/" || d == "." {
+			return
+		}
+	}
+}
+
+// forgetUnwatched drops the removed directory and its subdirectories from the
+// unwatched ones.
+func (idx *FileSystemIndexer) forgetUnwatched(dir string) {
+	idx.watchMu.Lock()
+	defer idx.watchMu.Unlock()
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	maps.DeleteFunc(idx.unwatched, func(d string, _ bool) bool {
+		return d == dir || strings.HasPrefix(d, prefix)
+	})
+}
+
+// unwatchedDirs returns the sorted directories that can't be watched with
+// inotify, with their subtrees.
+func (idx *FileSystemIndexer) unwatchedDirs() []string {
+	idx.watchMu.Lock()
+	defer idx.watchMu.Unlock()
+	if len(idx.unwatched) == 0 {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(idx.unwatched))
+}
+
+// poll rescans the unwatched directories that aren't watched with fanotify,
+// or all of them if all is set, e.g. after fanotify events were lost. The
+// directories are watched again if inotify watches became available.
+func (idx *FileSystemIndexer) poll(all bool) {
+	idx.watchMu.Lock()
+	var dirs []string
+	for dir, fanotify := range idx.unwatched {
+		if all || !fanotify {
+			dirs = append(dirs, dir)
+		}
+	}
+	// Retry watching the directories while rescanning them.
+	if idx.watcher != nil {
+		for _, dir := range dirs {
+			delete(idx.unwatched, dir)
+		}
+	}
+	idx.watchMu.Unlock()
+
+	slices.Sort(dirs)
+	for _, dir := range dirs {
+		if idx.ctx.Err() != nil {
+			return
+		}
+		slog.Debug("Polling unwatched directory", "path", dir)
+		if _, err := os.Lstat(dir); err != nil {
+			idx.removePath(dir)
+			continue
+		}
+		idx.walkPath(dir, true)
+	}
+	idx.countWatches()
+}
+
+// countWatches updates the number of directories watched with inotify.
+func (idx *FileSystemIndexer) countWatches() {
+	if idx.watcher != nil {
+		idx.watches.Store(int64(len(idx.watcher.WatchList())))
+	}
+}
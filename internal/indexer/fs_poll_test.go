@@ -0,0 +1,126 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+// newLimitedFSIndexer returns a watching indexer of the root whose inotify
+// watches of the directories below it fail with ENOSPC while limit is set,
+// like beyond the inotify watch limit.
+func newLimitedFSIndexer(t *testing.T, root string, c config.IndexerConfig, limit *atomic.Bool) *FileSystemIndexer {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	c.Type, c.Paths, c.Notify = string(FileSystemIndexerType), []string{root}, true
+	idx := NewFileSystemIndexer(ctx, newTestStore(t), root, c).(*FileSystemIndexer)
+	t.Cleanup(func() {
+		idx.closeFanotify()
+		idx.watcher.Close()
+	})
+	add := idx.addWatch
+	idx.addWatch = func(dir string) error {
+		if dir != root && limit.Load() {
+			return unix.ENOSPC
+		}
+		return add(dir)
+	}
+	go func() {
+		for range idx.Feedback() {
+		}
+	}()
+	if _, err := idx.UpdateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestPollUnwatched(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "a", "sub/deep/b.txt": "b", "gone/c.txt": "c"})
+	sub, gone := filepath.Join(root, "sub"), filepath.Join(root, "gone")
+	var limit atomic.Bool
+	limit.Store(true)
+	idx := newLimitedFSIndexer(t, root, config.IndexerConfig{}, &limit)
+	indexed := func(p string) bool {
+		return idx.Store.Find("fs_file_"+filepath.Join(root, p)).Path != ""
+	}
+
+	// Subdirectories of unwatched directories aren't watched separately.
+	if got, want := idx.Info().Unwatched, []string{gone, sub}; !slices.Equal(got, want) {
+		t.Fatalf("unwatched %q, want %q", got, want)
+	}
+	if !indexed("sub/deep/b.txt") {
+		t.Fatal("files of unwatched directories not indexed")
+	}
+
+	// Polling indexes the changes of the unwatched directories.
+	writeFiles(t, root, map[string]string{"sub/d.txt": "d"})
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatal(err)
+	}
+	idx.poll(false)
+	if !indexed("sub/d.txt") || indexed("gone/c.txt") {
+		t.Errorf("after poll: sub/d.txt indexed %v, gone/c.txt indexed %v, want true, false", indexed("sub/d.txt"), indexed("gone/c.txt"))
+	}
+	if got, want := idx.Info().Unwatched, []string{sub}; !slices.Equal(got, want) {
+		t.Errorf("unwatched %q after poll, want %q", got, want)
+	}
+
+	// Directories are watched again once inotify watches are available.
+	limit.Store(false)
+	idx.poll(false)
+	if got := idx.Info().Unwatched; len(got) != 0 {
+		t.Errorf("unwatched %q after the limit is lifted, want none", got)
+	}
+	if watched := idx.watcher.WatchList(); !slices.Contains(watched, filepath.Join(sub, "deep")) {
+		t.Errorf("watched %q, want the subdirectories", watched)
+	}
+}
+
+func TestPollFanotify(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"sub/a.txt": "a"})
+	sub := filepath.Join(root, "sub")
+	var limit atomic.Bool
+	limit.Store(true)
+	idx := newLimitedFSIndexer(t, root, config.IndexerConfig{Fanotify: true}, &limit)
+	if !idx.fanotifyCovered(sub) {
+		t.Skip("fanotify not available:", idx.fanotifyErr)
+	}
+
+	// Directories watched with fanotify are only polled after lost events.
+	writeFiles(t, root, map[string]string{"sub/b.txt": "b"})
+	key := "fs_file_" + filepath.Join(sub, "b.txt")
+	idx.poll(false)
+	if idx.Store.Find(key).Path != "" {
+		t.Error("directory watched with fanotify polled")
+	}
+	idx.poll(true)
+	if idx.Store.Find(key).Path == "" {
+		t.Error("directory watched with fanotify not polled after lost events")
+	}
+
+	// Changes of the directory are reported by fanotify instead.
+	writeFiles(t, root, map[string]string{"sub/c.txt": "c"})
+	want := filepath.Join(sub, "c.txt")
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-idx.fanotifyEvents:
+			if e.Name == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no fanotify event of %s", want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// newTestStore returns an in-memory store closed at the end of the test.
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+	s := store.NewInMemoryBadgerStore()
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// writeFiles creates the files with their contents below the directory.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// updateIndex runs an update of a new indexer of the config and returns its runtime info.
+func updateIndex(t *testing.T, s store.Store, c config.IndexerConfig) IndexerRuntimeInfo {
+	t.Helper()
+	idx := NewIndexers(context.Background(), c, s)[0]
+	go func() {
+		for range idx.Feedback() {
+		}
+	}()
+	if _, err := idx.UpdateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	return idx.Info()
+}
+
+func TestIncrementalSkipsUnchanged(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+	s := newTestStore(t)
+	c := config.IndexerConfig{Type: string(FileSystemIndexerType), Paths: []string{root}, Incremental: true, Workers: 2}
+
+	if info := updateIndex(t, s, c); info.Updated != 4 || info.Skipped != 0 {
+		t.Fatalf("first update: updated %d, skipped %d, want 4, 0", info.Updated, info.Skipped)
+	}
+	if info := updateIndex(t, s, c); info.Updated != 0 || info.Skipped != 4 {
+		t.Fatalf("second update: updated %d, skipped %d, want 0, 4", info.Updated, info.Skipped)
+	}
+
+	// Change the hash of the stored root, which is walked into again while
+	// the subtree of the unchanged sub directory is skipped.
+	rootKey := "fs_dir_" + root
+	item := s.Find(rootKey)
+	item.Hash = "changed"
+	if err := s.Add(map[string]store.ItemInfo{rootKey: item}); err != nil {
+		t.Fatal(err)
+	}
+	if info := updateIndex(t, s, c); info.Updated != 1 || info.Skipped != 3 || info.Total != 4 {
+		t.Fatalf("hash update: updated %d, skipped %d, total %d, want 1, 3, 4", info.Updated, info.Skipped, info.Total)
+	}
+
+	// Change the size and the modification time of a file, and of the
+	// directories above it so they are walked into.
+	path := filepath.Join(root, "sub", "b.txt")
+	writeFiles(t, root, map[string]string{"sub/b.txt": "bb"})
+	mtime := time.Now().Add(time.Minute)
+	for _, p := range []string{path, filepath.Dir(path), root} {
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if info := updateIndex(t, s, c); info.Updated != 3 || info.Skipped != 1 {
+		t.Fatalf("third update: updated %d, skipped %d, want 3, 1", info.Updated, info.Skipped)
+	}
+	if item := s.Find("fs_file_" + path); item.Size != 2 || !item.ModTime.Equal(mtime) {
+		t.Errorf("changed item: size %d, mtime %v, want 2, %v", item.Size, item.ModTime, mtime)
+	}
+
+	// Remove a file, changing the modification time of its directory.
+	if err := os.Remove(filepath.Join(root, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	mtime = mtime.Add(time.Minute)
+	if err := os.Chtimes(root, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if info := updateIndex(t, s, c); info.Updated != 1 || info.Removed != 1 {
+		t.Fatalf("fourth update: updated %d, removed %d, want 1, 1", info.Updated, info.Removed)
+	}
+	if keys := s.Keys("fs_file_", "", 0); len(keys) != 1 {
+		t.Errorf("files after removal: %v, want 1", keys)
+	}
+
+	// Changed filters clean the index and walk the unchanged directories.
+	c.ExcludeFileFilters = []string{"*.txt"}
+	if info := updateIndex(t, s, c); info.Removed != 1 || info.Updated != 2 {
+		t.Fatalf("filters update: removed %d, updated %d, want 1, 2", info.Removed, info.Updated)
+	}
+	if keys := s.Keys("fs_file_", "", 0); len(keys) != 0 {
+		t.Errorf("files after filters change: %v, want none", keys)
+	}
+	if info := updateIndex(t, s, c); info.Updated != 0 || info.Skipped != 2 {
+		t.Fatalf("update after filters change: updated %d, skipped %d, want 0, 2", info.Updated, info.Skipped)
+	}
+}
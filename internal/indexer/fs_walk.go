@@ -0,0 +1,199 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// DefaultWalkWorkers returns the default number of directories read
+// concurrently by the file system walker.
+func DefaultWalkWorkers() int {
+	return runtime.NumCPU()
+}
+
+// walkResult represents an item found by the walker, or a path that failed.
+type walkResult struct {
+	key          string         // Store key of the item.
+	item         store.ItemInfo // Item of the path.
+	unchanged    bool           // The item is stored unchanged, item is the stored one.
+	skippedDirs  int            // Stored directories below the unchanged directory, not walked.
+	skippedFiles int            // Stored files below the unchanged directory, not walked.
+	failed       string         // Path that couldn't be read, empty for items.
+}
+
+// walker walks a file tree with a bounded number of workers reading
+// directories concurrently. The directories pending reading are kept in a
+// stack, so a single worker walks the tree depth first in lexical order. With
+// more workers the order of the results varies, but not the results.
+type walker struct {
+	idx         *FileSystemIndexer
+	ctx         context.Context
+	incremental bool            // Skip building the items stored unchanged.
+	results     chan walkResult // Items and failed paths, closed when the walk is done.
+	mu          sync.Mutex      // Protects dirs and active.
+	cond        *sync.Cond      // Signals pushed directories and the end of the walk.
+	dirs        []string        // Directories pending reading.
+	active      int             // Directories being read.
+}
+
+// newWalker creates a walker of the indexer, stopped by the context. If
+// incremental, the items stored unchanged are sent without being rebuilt.
+func newWalker(ctx context.Context, idx *FileSystemIndexer, incremental bool) *walker {
+	w := &walker{idx: idx, ctx: ctx, incremental: incremental, results: make(chan walkResult, store.BatchCount)}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+// walk visits the path and, if it's a directory, its file tree with the
+// workers, closing the results when done.
+func (w *walker) walk(path string, info os.FileInfo, workers int) {
+	defer close(w.results)
+	if !w.visit(path, fs.FileInfoToDirEntry(info)) {
+		return
+	}
+
+	w.push([]string{path})
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				dir, ok := w.pop()
+				if !ok {
+					return
+				}
+				w.readDir(dir)
+				w.done()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// push adds the directories to the pending ones, the first is read first.
+func (w *walker) push(dirs []string) {
+	if len(dirs) == 0 {
+		return
+	}
+	w.mu.Lock()
+	for _, dir := range slices.Backward(dirs) {
+		w.dirs = append(w.dirs, dir)
+	}
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// pop waits for a pending directory and marks it active. It returns false when
+// no directories are pending or being read, and the walk is done.
+func (w *walker) pop() (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.dirs) == 0 && w.active > 0 {
+		w.cond.Wait()
+	}
+	if len(w.dirs) == 0 {
+		return "", false
+	}
+	dir := w.dirs[len(w.dirs)-1]
+	w.dirs = w.dirs[:len(w.dirs)-1]
+	w.active++
+	return dir, true
+}
+
+// done marks a directory read, waking the waiting workers at the end of the walk.
+func (w *walker) done() {
+	w.mu.Lock()
+	w.active--
+	end := w.active == 0 && len(w.dirs) == 0
+	w.mu.Unlock()
+	if end {
+		w.cond.Broadcast()
+	}
+}
+
+// readDir watches and reads the directory, visits its entries and pushes the
+// subdirectories to walk into.
+func (w *walker) readDir(dir string) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	// Add directories to the file system watcher.
+	w.idx.watch(dir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Skip access denied, etc., and add to the failed list.
+		slog.Debug("Can't read directory", "error", err, "path", dir)
+		w.send(walkResult{failed: dir})
+		return
+	}
+	var subdirs []string
+	for _, d := range entries {
+		if w.ctx.Err() != nil {
+			return
+		}
+		path := filepath.Join(dir, d.Name())
+		if w.visit(path, d) {
+			subdirs = append(subdirs, path)
+		}
+	}
+	w.push(subdirs)
+}
+
+// visit applies the filters to the entry, sends its item to the results and
+// reports whether to walk into it. The entry is only stat'ed to be indexed,
+// and only read if it isn't stored unchanged in incremental mode, where the
+// subtrees of unchanged directories aren't walked either.
+func (w *walker) visit(path string, d fs.DirEntry) bool {
+	isDir := d.IsDir()
+	switch w.idx.filter(path, isDir) {
+	case filterSkipDir:
+		return false
+	case filterSkip:
+		// Keep walking into skipped directories, they may contain included items.
+		return isDir
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		// The entry was removed after reading the directory.
+		slog.Debug("Can't get fileinfo for path:", "error", err, "path", path)
+		w.send(walkResult{failed: path})
+		return false
+	}
+	// Create the key for the item in the format "indexerType_itemType_path".
+	key := fmt.Sprintf("%s_%s_%s", w.idx.Type(), ItemType(isDir), path)
+	if w.incremental {
+		if stored, ok := w.idx.unchanged(key, info); ok {
+			r := walkResult{key: key, item: stored, unchanged: true}
+			if isDir {
+				r.skippedDirs, r.skippedFiles = w.idx.skipSubtree(path)
+			}
+			w.send(r)
+			return false
+		}
+	}
+	itemInfo := w.idx.newItemInfo(path, info)
+	// Add the content of the item to the full-text index.
+	w.idx.indexContent(key, itemInfo)
+	w.send(walkResult{key: key, item: itemInfo})
+	return isDir
+}
+
+// send sends the result unless the walk is canceled.
+func (w *walker) send(r walkResult) {
+	select {
+	case w.results <- r:
+	case <-w.ctx.Done():
+	}
+}
@@ -0,0 +1,206 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+)
+
+// newTestFSIndexer returns a file system indexer of the root with the config.
+func newTestFSIndexer(t *testing.T, ctx context.Context, root string, c config.IndexerConfig) *FileSystemIndexer {
+	t.Helper()
+	return NewFileSystemIndexer(ctx, newTestStore(t), root, c).(*FileSystemIndexer)
+}
+
+// walkPaths walks the indexer root with the workers and returns the sorted
+// paths of the items relative to the root, directories with a trailing slash.
+func walkPaths(t *testing.T, idx *FileSystemIndexer, workers int) []string {
+	t.Helper()
+	info, err := os.Stat(idx.RootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newWalker(idx.ctx, idx, false)
+	go w.walk(idx.RootPath, info, workers)
+
+	var paths []string
+	for r := range w.results {
+		if r.failed != "" {
+			t.Errorf("failed path %s", r.failed)
+			continue
+		}
+		rel, err := filepath.Rel(idx.RootPath, r.item.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.item.Type == DirItemType {
+			rel += "/"
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestWalkWorkers(t *testing.T) {
+	root := t.TempDir()
+	files := make(map[string]string)
+	for i := range 10 {
+		for j := range 5 {
+			files[fmt.Sprintf("d%d/e%d/f.txt", i, j)] = "f"
+		}
+		files[fmt.Sprintf("d%d/g.txt", i)] = "g"
+	}
+	writeFiles(t, root, files)
+	idx := newTestFSIndexer(t, context.Background(), root, config.IndexerConfig{})
+
+	want := walkPaths(t, idx, 1)
+	if n := 1 + 10*(1+5+5+1); len(want) != n {
+		t.Fatalf("walk with 1 worker: %d items, want %d", len(want), n)
+	}
+	for _, workers := range []int{2, 8} {
+		if got := walkPaths(t, idx, workers); !slices.Equal(got, want) {
+			t.Errorf("walk with %d workers:\n got %v\nwant %v", workers, got, want)
+		}
+	}
+}
+
+func TestWalkFilters(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":      "*.log\n/tmp/\n",
+		"a.txt":           "a",
+		"a.o":             "a",
+		"a.log":           "a",
+		"build/b.txt":     "b",
+		"tmp/t.txt":       "t",
+		"src/c.go":        "c",
+		"src/c.txt":       "c",
+		"src/lib/d.go":    "d",
+		"docs/src/e.go":   "e",
+		"docs/readme.txt": "r",
+	})
+
+	tests := []struct {
+		name string
+		c    config.IndexerConfig
+		want []string
+	}{
+		{
+			name: "exclude",
+			c: config.IndexerConfig{
+				ExcludeDirFilters:  []string{"build"},
+				ExcludeFileFilters: []string{"*.o"},
+			},
+			want: []string{
+				"./", ".gitignore", "a.log", "a.txt",
+				"docs/", "docs/readme.txt", "docs/src/", "docs/src/e.go",
+				"src/", "src/c.go", "src/c.txt", "src/lib/", "src/lib/d.go",
+				"tmp/", "tmp/t.txt",
+			},
+		},
+		{
+			name: "ignore files",
+			c: config.IndexerConfig{
+				ExcludeDirFilters:  []string{"build"},
+				ExcludeFileFilters: []string{"*.o"},
+				IgnoreFiles:        true,
+			},
+			want: []string{
+				"./", ".gitignore", "a.txt",
+				"docs/", "docs/readme.txt", "docs/src/", "docs/src/e.go",
+				"src/", "src/c.go", "src/c.txt", "src/lib/", "src/lib/d.go",
+			},
+		},
+		{
+			name: "include files",
+			c: config.IndexerConfig{
+				ExcludeDirFilters:  []string{"build"},
+				IncludeFileFilters: []string{"*.go"},
+			},
+			want: []string{
+				"./", "docs/", "docs/src/", "docs/src/e.go",
+				"src/", "src/c.go", "src/lib/", "src/lib/d.go", "tmp/",
+			},
+		},
+		{
+			name: "include dirs",
+			c: config.IndexerConfig{
+				IncludeDirFilters: []string{"/src"},
+			},
+			// The files directly under the root are included.
+			want: []string{
+				"./", ".gitignore", "a.log", "a.txt",
+				"src/", "src/c.go", "src/c.txt", "src/lib/", "src/lib/d.go",
+			},
+		},
+		{
+			name: "include dirs and files",
+			c: config.IndexerConfig{
+				IncludeDirFilters:  []string{"src"},
+				IncludeFileFilters: []string{"*.go"},
+			},
+			want: []string{"./", "docs/src/", "docs/src/e.go", "src/", "src/c.go", "src/lib/", "src/lib/d.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := newTestFSIndexer(t, context.Background(), root, tt.c)
+			if got := walkPaths(t, idx, 4); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkCancel(t *testing.T) {
+	root := t.TempDir()
+	files := make(map[string]string)
+	for i := range 50 {
+		for j := range 20 {
+			files[fmt.Sprintf("d%d/e%d/f.txt", i, j)] = "f"
+		}
+	}
+	writeFiles(t, root, files)
+	info, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			idx := newTestFSIndexer(t, ctx, root, config.IndexerConfig{})
+			w := newWalker(ctx, idx, false)
+			done := make(chan struct{})
+			go func() {
+				w.walk(root, info, workers)
+				close(done)
+			}()
+
+			// Cancel after the first result, leaving the results unread so the
+			// workers block sending until they see the cancellation.
+			<-w.results
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("walk didn't stop after cancel")
+			}
+			n := 1
+			for range w.results {
+				n++
+			}
+			if total := 1 + 50*(1+20+20); n >= total {
+				t.Errorf("canceled walk sent %d results, want less than %d", n, total)
+			}
+		})
+	}
+}
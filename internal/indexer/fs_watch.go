@@ -0,0 +1,148 @@
+package indexer
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Delays of the coalesced handling of watcher events.
+const (
+	watchDebounce = 100 * time.Millisecond // Quiet period after the last event before handling the pending ones.
+	watchMaxDelay = time.Second            // Maximum delay of the pending events during bursts.
+)
+
+// eventBatch coalesces the watcher events per path, with their combined
+// operations, until they are handled once its timer fires: after a quiet
+// period following the last event, or after a maximum delay from the first
+// one during bursts.
+type eventBatch struct {
+	pending  map[string]fsnotify.Op // Pending operations per path.
+	first    time.Time              // Time of the first pending event.
+	timer    *time.Timer            // Fires when the pending events are due.
+	debounce time.Duration          // Quiet period after the last event.
+	maxDelay time.Duration          // Maximum delay of the first event.
+}
+
+// newEventBatch returns an empty batch with the delays, its timer stopped.
+func newEventBatch(debounce, maxDelay time.Duration) *eventBatch {
+	b := &eventBatch{
+		pending:  make(map[string]fsnotify.Op),
+		timer:    time.NewTimer(debounce),
+		debounce: debounce,
+		maxDelay: maxDelay,
+	}
+	b.timer.Stop()
+	return b
+}
+
+// add adds the event to the pending ones and delays the timer.
+func (b *eventBatch) add(event fsnotify.Event) {
+	if len(b.pending) == 0 {
+		b.first = time.Now()
+	}
+	b.pending[filepath.Clean(event.Name)] |= event.Op
+	b.timer.Reset(max(min(b.debounce, b.maxDelay-time.Since(b.first)), 0))
+}
+
+// take returns the pending events and empties the batch.
+func (b *eventBatch) take() map[string]fsnotify.Op {
+	events := b.pending
+	b.pending = make(map[string]fsnotify.Op)
+	return events
+}
+
+// handleEvents updates the index for the coalesced watcher events of the
+// paths. Whether a path was removed, renamed, created or changed is decided
+// by its current state: paths that no longer exist are removed with their
+// subtrees and watches first, so that renamed directories are watched again
+// under their new path. Paths below removed directories and directories
+// walked again are skipped.
+func (idx *FileSystemIndexer) handleEvents(events map[string]fsnotify.Op) {
+	paths := slices.Sorted(maps.Keys(events))
+	removed := make(map[string]bool)
+	walked := make(map[string]bool)
+	ignoreDirs := make(map[string]bool)
+
+	// Remove the paths that no longer exist, e.g. the old path of a rename.
+	var watched []string
+	for _, p := range paths {
+		if below(p, removed) {
+			continue
+		}
+		if _, err := os.Lstat(p); err == nil {
+			continue
+		}
+		if watched == nil {
+			watched = slices.Sorted(slices.Values(idx.watcher.WatchList()))
+		}
+		idx.unwatch(watched, p)
+		idx.forgetUnwatched(p)
+		idx.removePath(p)
+		removed[p] = true
+	}
+
+	for _, p := range paths {
+		op := events[p]
+		if idx.ignore != nil && isIgnoreFile(p) {
+			ignoreDirs[filepath.Dir(p)] = true
+		}
+		if removed[p] || below(p, removed) || below(p, walked) {
+			continue
+		}
+		switch {
+		case op.Has(fsnotify.Create), op.Has(fsnotify.Rename), op.Has(fsnotify.Remove):
+			// New path of a rename, or a replaced item: remove the stale
+			// entries and add the path with its subtree and watches.
+			if op.Has(fsnotify.Rename) || op.Has(fsnotify.Remove) {
+				idx.removePath(p)
+			}
+			idx.addPath(p)
+			walked[p] = true
+		case op.Has(fsnotify.Write), op.Has(fsnotify.Chmod):
+			// Update the content, attributes and extended attributes of the item.
+			idx.updateItem(p)
+		}
+	}
+
+	// Reapply the ignore rules of the directories whose ignore file changed.
+	for _, dir := range slices.Sorted(maps.Keys(ignoreDirs)) {
+		if !removed[dir] && !below(dir, removed) {
+			idx.reapplyIgnore(dir)
+		}
+	}
+}
+
+// below reports whether one of the parent directories of the path is in the set.
+func below(p string, dirs map[string]bool) bool {
+	if len(dirs) == 0 {
+		return false
+	}
+	for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+		if dirs[dir] {
+			return true
+		}
+		if dir == "/" || dir == "." {
+			return false
+		}
+	}
+}
+
+// unwatch removes the watches of the directory and its subdirectories from
+// the sorted watched paths. Watches of renamed directories keep their old
+// paths until removed.
+func (idx *FileSystemIndexer) unwatch(watched []string, dir string) {
+	if _, ok := slices.BinarySearch(watched, dir); ok {
+		idx.watcher.Remove(dir)
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	i, _ := slices.BinarySearch(watched, prefix)
+	for ; i < len(watched) && strings.HasPrefix(watched[i], prefix); i++ {
+		idx.watcher.Remove(watched[i])
+	}
+}
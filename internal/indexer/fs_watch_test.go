@@ -0,0 +1,115 @@
+package indexer
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/shtirlic/knotidx/internal/config"
+)
+
+func TestEventBatch(t *testing.T) {
+	// Events of the same path are combined.
+	b := newEventBatch(10*time.Millisecond, time.Minute)
+	b.add(fsnotify.Event{Name: "/r/a", Op: fsnotify.Create})
+	b.add(fsnotify.Event{Name: "/r/a", Op: fsnotify.Write})
+	b.add(fsnotify.Event{Name: "/r/b/../c/", Op: fsnotify.Chmod})
+	<-b.timer.C
+	want := map[string]fsnotify.Op{"/r/a": fsnotify.Create | fsnotify.Write, "/r/c": fsnotify.Chmod}
+	if got := b.take(); !maps.Equal(got, want) {
+		t.Errorf("got batch %v, want %v", got, want)
+	}
+	if got := b.take(); len(got) != 0 {
+		t.Errorf("got batch %v after take, want none", got)
+	}
+
+	// A burst of events is handled after the maximum delay, without waiting
+	// for a quiet period.
+	b = newEventBatch(time.Minute, 100*time.Millisecond)
+	start := time.Now()
+	tick := time.NewTicker(5 * time.Millisecond)
+	defer tick.Stop()
+	n := 0
+burst:
+	for {
+		select {
+		case <-b.timer.C:
+			break burst
+		case <-tick.C:
+			n++
+			b.add(fsnotify.Event{Name: "/r/" + strings.Repeat("x", n%3+1), Op: fsnotify.Write})
+		case <-time.After(10 * time.Second):
+			t.Fatal("burst not handled")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("burst handled after %v, before the maximum delay", elapsed)
+	}
+	if got := b.take(); len(got) != min(n, 3) {
+		t.Errorf("got batch %v of %d events, want 3 paths", got, n)
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "a", "old/b.txt": "b", "gone.txt": "g"})
+	s := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := config.IndexerConfig{Type: string(FileSystemIndexerType), Paths: []string{root}, Notify: true}
+	idx := NewFileSystemIndexer(ctx, s, root, c).(*FileSystemIndexer)
+	defer idx.watcher.Close()
+	go func() {
+		for range idx.Feedback() {
+		}
+	}()
+	if _, err := idx.UpdateIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rename a directory, add a file to it, remove a file and change another.
+	if err := os.Rename(filepath.Join(root, "old"), filepath.Join(root, "new")); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{"new/c.txt": "c", "a.txt": "changed"})
+	if err := os.Remove(filepath.Join(root, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	idx.handleEvents(map[string]fsnotify.Op{
+		filepath.Join(root, "old"):       fsnotify.Rename,
+		filepath.Join(root, "old/b.txt"): fsnotify.Write,
+		filepath.Join(root, "new"):       fsnotify.Create,
+		filepath.Join(root, "new/c.txt"): fsnotify.Create | fsnotify.Write,
+		filepath.Join(root, "gone.txt"):  fsnotify.Remove,
+		filepath.Join(root, "a.txt"):     fsnotify.Write,
+	})
+
+	var got []string
+	for _, k := range s.Keys("fs_", "", 0) {
+		got = append(got, strings.TrimPrefix(k, "fs_"))
+	}
+	want := []string{
+		"dir_" + root,
+		"dir_" + filepath.Join(root, "new"),
+		"file_" + filepath.Join(root, "a.txt"),
+		"file_" + filepath.Join(root, "new/b.txt"),
+		"file_" + filepath.Join(root, "new/c.txt"),
+	}
+	slices.Sort(got)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("got keys %q, want %q", got, want)
+	}
+	if size := s.Find("fs_file_" + filepath.Join(root, "a.txt")).Size; size != int64(len("changed")) {
+		t.Errorf("changed file size %d, want %d", size, len("changed"))
+	}
+	if watched := idx.watcher.WatchList(); !slices.Contains(watched, filepath.Join(root, "new")) || slices.Contains(watched, filepath.Join(root, "old")) {
+		t.Errorf("watched %q, want the new directory instead of the old one", watched)
+	}
+}
@@ -0,0 +1,68 @@
+package indexer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// xattrUserPrefix is the namespace of the indexed extended attributes.
+const xattrUserPrefix = "user."
+
+// maxXAttrSize limits the size of the extended attribute values read.
+const maxXAttrSize = 4096
+
+// readXAttrs returns the extended user attributes of the path without following
+// symbolic links, nil if it has none or the file system doesn't support them.
+func readXAttrs(path string) map[string]string {
+	names, err := listXAttrs(path)
+	if err != nil || len(names) == 0 {
+		return nil
+	}
+
+	attrs := make(map[string]string)
+	buf := make([]byte, maxXAttrSize)
+	for _, name := range names {
+		if !strings.HasPrefix(name, xattrUserPrefix) {
+			continue
+		}
+		n, err := unix.Lgetxattr(path, name, buf)
+		if err != nil {
+			// Skip removed and oversized values.
+			continue
+		}
+		attrs[name] = strings.TrimRight(string(buf[:n]), "\x00")
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// listXAttrs returns the extended attribute names of the path.
+func listXAttrs(path string) ([]string, error) {
+	for {
+		size, err := unix.Llistxattr(path, nil)
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		buf := make([]byte, size)
+		n, err := unix.Llistxattr(path, buf)
+		if errors.Is(err, unix.ERANGE) {
+			// Attributes were added since the size was queried.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, name := range bytes.Split(buf[:n], []byte{0}) {
+			if len(name) > 0 {
+				names = append(names, string(name))
+			}
+		}
+		return names, nil
+	}
+}
@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+// setXAttr sets the extended attribute of the path, skipping the test on file
+// systems without user attributes.
+func setXAttr(t *testing.T, path string, name string, value string) {
+	t.Helper()
+	err := unix.Lsetxattr(path, name, []byte(value), 0)
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EPERM) {
+		t.Skip("file system without user extended attributes:", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadXAttrs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "a", "b.txt": "b"})
+	a := filepath.Join(root, "a.txt")
+	setXAttr(t, a, "user.xdg.tags", "work,todo")
+	setXAttr(t, a, "user.xdg.comment", "note\x00")
+
+	want := map[string]string{"user.xdg.tags": "work,todo", "user.xdg.comment": "note"}
+	if got := readXAttrs(a); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := readXAttrs(filepath.Join(root, "b.txt")); got != nil {
+		t.Errorf("got %v for a file without attributes, want nil", got)
+	}
+	if got := readXAttrs(filepath.Join(root, "missing")); got != nil {
+		t.Errorf("got %v for a missing file, want nil", got)
+	}
+
+	// Symbolic links are not followed.
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(a, link); err != nil {
+		t.Fatal(err)
+	}
+	if got := readXAttrs(link); got != nil {
+		t.Errorf("got %v for a symbolic link, want nil", got)
+	}
+}
+
+func TestXAttrsIndexed(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "a"})
+	a := filepath.Join(root, "a.txt")
+	setXAttr(t, a, "user.xdg.tags", "work")
+	s := newTestStore(t)
+	c := config.IndexerConfig{Type: string(FileSystemIndexerType), Paths: []string{root}, Incremental: true}
+	key := "fs_file_" + a
+
+	updateIndex(t, s, c)
+	if got := s.Find(key).XAttrs["user.xdg.tags"]; got != "work" {
+		t.Fatalf("indexed tags %q, want work", got)
+	}
+
+	// Changed attributes don't change the modification time of the file, but
+	// its hash, checked once its directory is walked again.
+	setXAttr(t, a, "user.xdg.tags", "done")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(root, later, later); err != nil {
+		t.Fatal(err)
+	}
+	updateIndex(t, s, c)
+	if got := s.Find(key).XAttrs["user.xdg.tags"]; got != "done" {
+		t.Errorf("indexed tags %q after the change, want done", got)
+	}
+}
@@ -0,0 +1,520 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/fulltext"
+	"github.com/shtirlic/knotidx/internal/git"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+const (
+	// GitFileItemType represents the item type for files tracked at HEAD.
+	GitFileItemType store.ItemType = "gitfile"
+
+	// GitBranchItemType represents the item type for branches.
+	GitBranchItemType store.ItemType = "branch"
+
+	// GitTagItemType represents the item type for tags.
+	GitTagItemType store.ItemType = "tag"
+
+	// GitCommitItemType represents the item type for commits.
+	GitCommitItemType store.ItemType = "commit"
+
+	// GitIndexerType represents the type of the GitIndexer.
+	GitIndexerType IndexerType = "git"
+
+	// DefaultGitCommits is the default number of recent commits to index.
+	DefaultGitCommits = 100
+
+	// gitWatchDelay is the delay to coalesce reference changes before updating the index.
+	gitWatchDelay = 500 * time.Millisecond
+)
+
+// GitIndexer represents an indexer implementation for git repositories.
+// It indexes the files tracked at HEAD, branches, tags and recent commits
+// by reading the repository objects directly.
+type GitIndexer struct {
+	RootPath string      // RootPath is the repository path to be indexed.
+	Commits  int         // Commits is the number of recent commits to index.
+	Store    store.Store // Store is the data store to index items.
+
+	repo     *git.Repository         // repo is the opened repository, closed when UpdateIndex and Watch return.
+	watcher  *fsnotify.Watcher       // watcher is used to monitor reference changes.
+	config   config.IndexerConfig    // config is the configuration for the indexer.
+	ctx      context.Context         // ctx is the cancel conext.
+	info     info                    // runtime info
+	feedback chan IndexerRuntimeInfo // feedback channel
+
+	mu sync.Mutex // mu serializes index updates.
+}
+
+// NewGitIndexer creates a new instance of GitIndexer with the provided store,
+// repository path, and configuration. It returns an Indexer interface.
+func NewGitIndexer(ctx context.Context, store store.Store, rootPath string, c config.IndexerConfig) Indexer {
+	commits := c.GitCommits
+	if commits == 0 {
+		commits = DefaultGitCommits
+	}
+
+	gi := &GitIndexer{
+		RootPath: filepath.Clean(rootPath),
+		Commits:  commits,
+		Store:    store,
+		config:   c,
+		ctx:      ctx,
+		info:     info{IndexerRuntimeInfo: IndexerRuntimeInfo{Status: "Created"}},
+		feedback: make(chan IndexerRuntimeInfo, 1),
+	}
+
+	// Enable fsnotify watcher for reference changes if Notify is true in the configuration.
+	if c.Notify {
+		var err error
+		if gi.watcher, err = fsnotify.NewWatcher(); err != nil {
+			slog.Error("Can't create git watcher", "path", gi.RootPath, "error", err)
+		}
+	}
+	return gi
+}
+
+// Type returns the type of the GitIndexer.
+func (idx *GitIndexer) Type() IndexerType {
+	return GitIndexerType
+}
+
+// Root returns the root path of the GitIndexer.
+func (idx *GitIndexer) Root() string {
+	return idx.RootPath
+}
+
+// Config returns the configuration of the GitIndexer.
+func (idx *GitIndexer) Config() config.IndexerConfig {
+	return idx.config
+}
+
+// Feedback returns feeback channel.
+func (idx *GitIndexer) Feedback() chan IndexerRuntimeInfo {
+	return idx.feedback
+}
+
+// Info returns runtime info.
+func (idx *GitIndexer) Info() IndexerRuntimeInfo {
+	return idx.info.get()
+}
+
+// UpdateIndex indexes the repository and reports progress to the feedback channel.
+func (idx *GitIndexer) UpdateIndex() (time.Duration, error) {
+	startTime := time.Now()
+	idx.info.update(func(info *IndexerRuntimeInfo) {
+		info.StartTime = startTime
+		info.Status = "Started"
+	})
+	idx.feedback <- idx.Info()
+	defer close(idx.feedback)
+	defer idx.close()
+
+	if err := idx.update(true); err != nil && !errors.Is(err, context.Canceled) {
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Status = "Failed" })
+		return 0, err
+	}
+
+	idx.info.finish(idx.ctx)
+	idx.feedback <- idx.Info()
+	return time.Since(startTime), nil
+}
+
+// CleanIndex removes the items of the repository with the specified prefix,
+// e.g. "commit_" for all indexed commits.
+func (idx *GitIndexer) CleanIndex(prefix string) error {
+	for _, key := range idx.Store.Keys(string(idx.Type())+"_"+prefix, "", 0) {
+		if !idx.ownsKey(key) {
+			continue
+		}
+		if err := idx.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+	idx.Store.Maintenance()
+	return nil
+}
+
+// Watch monitors the repository references and updates the index when they change.
+func (idx *GitIndexer) Watch() {
+	if idx.watcher == nil {
+		return
+	}
+	defer idx.watcher.Close()
+	defer idx.close()
+
+	idx.mu.Lock()
+	repo, err := idx.open()
+	idx.mu.Unlock()
+	if err != nil {
+		slog.Error("Can't watch git repository", "path", idx.RootPath, "error", err)
+		return
+	}
+	for _, dir := range []string{repo.GitDir(), repo.CommonDir()} {
+		idx.watcher.Add(dir)
+		idx.watchRefDirs(filepath.Join(dir, "refs"))
+	}
+
+	slog.Debug("Git watcher events select", "idx RootPath", idx.RootPath)
+
+	timer := time.NewTimer(gitWatchDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-idx.watcher.Events:
+			if !ok {
+				return
+			}
+			if !idx.refEvent(repo, event) {
+				continue
+			}
+			slog.Debug("Git watcher", "event", event)
+			// Watch new reference directories.
+			if event.Has(fsnotify.Create) {
+				idx.watchRefDirs(event.Name)
+			}
+			// Coalesce bursts of reference updates.
+			timer.Reset(gitWatchDelay)
+		case <-timer.C:
+			if err := idx.update(false); err != nil {
+				slog.Error("Git index update failed", "path", idx.RootPath, "error", err)
+			}
+		case err, ok := <-idx.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Debug("Git watcher", "error", err)
+		case <-idx.ctx.Done():
+			slog.Debug("Quit git watcher", "idx RootPath", idx.RootPath)
+			return
+		}
+	}
+}
+
+// watchRefDirs adds the reference directory and its subdirectories to the watcher.
+func (idx *GitIndexer) watchRefDirs(dir string) {
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			idx.watcher.Add(p)
+		}
+		return nil
+	})
+}
+
+// refEvent reports whether the event changes HEAD or references.
+func (idx *GitIndexer) refEvent(repo *git.Repository, event fsnotify.Event) bool {
+	if strings.HasSuffix(event.Name, ".lock") {
+		return false
+	}
+	for _, dir := range []string{repo.GitDir(), repo.CommonDir()} {
+		rel, err := filepath.Rel(dir, event.Name)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "HEAD" || rel == "packed-refs" || strings.HasPrefix(rel, "refs/") {
+			return true
+		}
+	}
+	return false
+}
+
+// open opens the repository on first use.
+func (idx *GitIndexer) open() (*git.Repository, error) {
+	if idx.repo == nil {
+		repo, err := git.Open(idx.RootPath)
+		if err != nil {
+			return nil, err
+		}
+		idx.repo = repo
+	}
+	return idx.repo, nil
+}
+
+// close closes the repository opened by updates, releasing its packfiles.
+// Later updates reopen it.
+func (idx *GitIndexer) close() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.repo == nil {
+		return
+	}
+	if err := idx.repo.Close(); err != nil {
+		slog.Debug("Can't close git repository", "path", idx.RootPath, "error", err)
+	}
+	idx.repo = nil
+}
+
+// update indexes the changes since the last update: files changed between the
+// indexed and the current HEAD trees, and changed references and commits.
+// The first update indexes everything and removes stale items of the repository.
+func (idx *GitIndexer) update(report bool) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	repo, err := idx.open()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	var commit *git.Commit
+	switch {
+	case errors.Is(err, git.ErrRefNotFound):
+		// Unborn branch of an empty repository.
+		commit = &git.Commit{}
+	case err != nil:
+		return err
+	default:
+		if commit, err = repo.Commit(head.Target()); err != nil {
+			return err
+		}
+	}
+
+	if err := idx.updateFiles(repo, commit, report); err != nil {
+		return err
+	}
+	if err := idx.updateRefs(repo); err != nil {
+		return err
+	}
+	if err := idx.updateCommits(repo, commit); err != nil {
+		return err
+	}
+
+	info := idx.Info()
+	addInfo := fmt.Sprintf("Total: %d, Files: %d, Failed: %d, rootPath: %s", info.Total, info.Files, info.Failed, idx.RootPath)
+	slog.Debug(addInfo)
+	return nil
+}
+
+// updateFiles indexes the files of the HEAD tree that changed since the last update.
+// The last indexed tree is saved as state, so later updates only visit the
+// differences from it, also after the daemon restarts.
+func (idx *GitIndexer) updateFiles(repo *git.Repository, commit *git.Commit, report bool) error {
+	name := StateName(idx.Type(), idx.RootPath)
+	tree, err := git.ParseHash(string(idx.Store.State(name)))
+	if err != nil {
+		tree = git.Hash{}
+	}
+	if commit.Tree == tree && !tree.IsZero() {
+		return nil
+	}
+
+	itemList := make(map[string]store.ItemInfo)
+	seen := make(map[string]bool)
+	first := tree.IsZero()
+
+	visit := func(p string, e git.TreeEntry) error {
+		if err := idx.ctx.Err(); err != nil {
+			return err
+		}
+		if report {
+			select {
+			case <-idx.feedback:
+			default:
+				idx.feedback <- idx.Info()
+			}
+		}
+
+		key := idx.key(GitFileItemType, filepath.Join(idx.RootPath, filepath.FromSlash(p)))
+
+		// Removed file.
+		if e.Hash.IsZero() {
+			return idx.Store.Delete(key)
+		}
+		// Submodules are commits of other repositories.
+		if e.Mode == git.ModeSubmodule {
+			return nil
+		}
+
+		size, err := repo.ObjectSize(e.Hash)
+		if err != nil {
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Failed++ })
+			slog.Debug("Can't get git object size", "path", p, "error", err)
+		}
+		itemInfo := store.NewItemInfo(path.Base(p), filepath.Join(idx.RootPath, filepath.FromSlash(p)),
+			commit.Committer.When, size, GitFileItemType)
+		itemInfo.MimeType = mime.TypeByExtension(path.Ext(p))
+		itemInfo.Meta = map[string]string{"blob": e.Hash.String()}
+		itemInfo.Hash = itemInfo.XXhash()
+
+		itemList[key] = itemInfo
+		seen[key] = true
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Files++; info.Total++ })
+
+		// Add items to the store in batches.
+		if len(itemList) > store.BatchCount {
+			if err := idx.Store.Add(itemList); err != nil {
+				return err
+			}
+			clear(itemList)
+		}
+		return nil
+	}
+
+	switch {
+	case first && !commit.Tree.IsZero():
+		err = repo.WalkTree(commit.Tree, visit)
+	case !first:
+		err = repo.DiffTree(tree, commit.Tree, visit)
+	}
+	if err != nil {
+		return err
+	}
+	if len(itemList) > 0 {
+		if err := idx.Store.Add(itemList); err != nil {
+			return err
+		}
+	}
+
+	// Remove files indexed without a saved tree that are gone from HEAD.
+	if first {
+		if err := idx.prune(GitFileItemType, seen); err != nil {
+			return err
+		}
+	}
+	if commit.Tree.IsZero() {
+		return idx.Store.SetState(name, nil)
+	}
+	return idx.Store.SetState(name, []byte(commit.Tree.String()))
+}
+
+// updateRefs indexes branches and tags, removing deleted ones.
+func (idx *GitIndexer) updateRefs(repo *git.Repository) error {
+	refs, err := repo.Refs()
+	if err != nil {
+		return err
+	}
+
+	branches := make(map[string]store.ItemInfo)
+	tags := make(map[string]store.ItemInfo)
+	for _, ref := range refs {
+		var t store.ItemType
+		var name string
+		switch {
+		case strings.HasPrefix(ref.Name, "refs/heads/"):
+			t, name = GitBranchItemType, strings.TrimPrefix(ref.Name, "refs/heads/")
+		case strings.HasPrefix(ref.Name, "refs/remotes/") && !strings.HasSuffix(ref.Name, "/HEAD"):
+			t, name = GitBranchItemType, strings.TrimPrefix(ref.Name, "refs/")
+		case strings.HasPrefix(ref.Name, "refs/tags/"):
+			t, name = GitTagItemType, strings.TrimPrefix(ref.Name, "refs/tags/")
+		default:
+			continue
+		}
+
+		var modTime time.Time
+		if c, err := repo.Commit(ref.Target()); err == nil {
+			modTime = c.Committer.When
+		}
+		itemInfo := store.NewItemInfo(name, idx.RootPath+"@"+ref.Name, modTime, 0, t)
+		itemInfo.Meta = map[string]string{"ref": ref.Name, "commit": ref.Target().String()}
+		itemInfo.Hash = itemInfo.XXhash()
+
+		if t == GitBranchItemType {
+			branches[idx.key(t, itemInfo.Path)] = itemInfo
+		} else {
+			tags[idx.key(t, itemInfo.Path)] = itemInfo
+		}
+	}
+
+	if err := idx.replace(GitBranchItemType, branches); err != nil {
+		return err
+	}
+	return idx.replace(GitTagItemType, tags)
+}
+
+// updateCommits indexes the recent commits reachable from HEAD, removing older
+// ones. Commits are named by their subject, with their message as content.
+func (idx *GitIndexer) updateCommits(repo *git.Repository, head *git.Commit) error {
+	commits := make(map[string]store.ItemInfo)
+	messages := make(map[string]string)
+	if !head.Hash.IsZero() {
+		log, err := repo.Log(head.Hash, idx.Commits)
+		if err != nil {
+			return err
+		}
+		for _, c := range log {
+			itemInfo := store.NewItemInfo(c.Subject(), idx.RootPath+"@"+c.Hash.String(), c.Committer.When, 0, GitCommitItemType)
+			itemInfo.Meta = map[string]string{"author": c.Author.String(), "commit": c.Hash.String()}
+			itemInfo.Hash = itemInfo.XXhash()
+			key := idx.key(GitCommitItemType, itemInfo.Path)
+			commits[key] = itemInfo
+			messages[key] = c.Message
+		}
+	}
+	if err := idx.replace(GitCommitItemType, commits); err != nil {
+		return err
+	}
+
+	// Index the messages as the content of the commits, found by content searches.
+	for key, item := range commits {
+		if idx.Store.ContentHash(key) == item.Hash {
+			continue
+		}
+		if err := idx.Store.SetContent(key, item.Hash, fulltext.Terms(messages[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replace stores the items of the item type and removes the indexed ones of the
+// repository missing from items.
+func (idx *GitIndexer) replace(t store.ItemType, items map[string]store.ItemInfo) error {
+	if len(items) > 0 {
+		if err := idx.Store.Add(items); err != nil {
+			return err
+		}
+	}
+
+	keep := make(map[string]bool, len(items))
+	for k := range items {
+		keep[k] = true
+	}
+	return idx.prune(t, keep)
+}
+
+// prune removes the repository items of the item type missing from keep.
+func (idx *GitIndexer) prune(t store.ItemType, keep map[string]bool) error {
+	for _, key := range idx.Store.Keys(idx.key(t, idx.RootPath), "", 0) {
+		if keep[key] || !idx.ownsKey(key) {
+			continue
+		}
+		if err := idx.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key returns the store key of the item in the format "indexerType_itemType_path".
+func (idx *GitIndexer) key(t store.ItemType, p string) string {
+	return fmt.Sprintf("%s_%s_%s", idx.Type(), t, p)
+}
+
+// ownsKey reports whether the store key belongs to the repository of the indexer.
+func (idx *GitIndexer) ownsKey(key string) bool {
+	item := strings.SplitN(key, "_", 3)
+	if len(item) < 3 {
+		return false
+	}
+	p, ok := strings.CutPrefix(item[2], idx.RootPath)
+	return ok && (p == "" || p[0] == '/' || p[0] == '@')
+}
@@ -0,0 +1,175 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+)
+
+// newGitRepo creates a repository with a packed commit of the files.
+func newGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	root := t.TempDir()
+	writeFiles(t, root, files)
+	runGit(t, root, "init", "-q")
+	commitAll(t, root, "init")
+	runGit(t, root, "gc", "-q")
+	return root
+}
+
+// runGit runs the git command in the repository.
+func runGit(t *testing.T, root string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v: %s", args[0], err, out)
+	}
+}
+
+// commitAll commits all the changes of the work tree with the message.
+func commitAll(t *testing.T, root string, message string) {
+	t.Helper()
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", message)
+}
+
+// openPacks returns the number of packfiles below the root opened by the process.
+func openPacks(t *testing.T, root string) int {
+	t.Helper()
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("can't list open files:", err)
+	}
+	n := 0
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name()))
+		if err == nil && strings.HasPrefix(target, root) && strings.HasSuffix(target, ".pack") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGitClosesRepository(t *testing.T) {
+	root := newGitRepo(t, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+	s := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := config.IndexerConfig{Type: string(GitIndexerType), Paths: []string{root}, Notify: true}
+	idx := NewGitIndexer(ctx, s, root, c)
+
+	go func() {
+		for range idx.Feedback() {
+		}
+	}()
+	if _, err := idx.UpdateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if keys := s.Keys("git_gitfile_", "", 0); len(keys) != 2 {
+		t.Errorf("indexed files %v, want 2", keys)
+	}
+	if n := openPacks(t, root); n != 0 {
+		t.Errorf("%d packfiles open after UpdateIndex, want 0", n)
+	}
+
+	// The watcher keeps the repository open until it returns.
+	done := make(chan struct{})
+	go func() {
+		idx.Watch()
+		close(done)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); openPacks(t, root) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher didn't open the repository")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if n := openPacks(t, root); n != 0 {
+		t.Errorf("%d packfiles open after Watch, want 0", n)
+	}
+}
+
+func TestGitCommitItems(t *testing.T) {
+	root := newGitRepo(t, map[string]string{"a.txt": "a"})
+	s := newTestStore(t)
+	c := config.IndexerConfig{Type: string(GitIndexerType), Paths: []string{root}}
+	idx := NewGitIndexer(context.Background(), s, root, c)
+	go func() {
+		for range idx.Feedback() {
+		}
+	}()
+	if _, err := idx.UpdateIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := s.Keys("git_commit_", "", 0)
+	if len(keys) != 1 {
+		t.Fatalf("indexed commits %v, want 1", keys)
+	}
+	item := s.Find(keys[0])
+	if want := root + "@" + item.Meta["commit"]; item.Path != want || keys[0] != "git_commit_"+want {
+		t.Errorf("commit key %q, path %q, want path %q", keys[0], item.Path, want)
+	}
+	if item.Name != "init" {
+		t.Errorf("commit name %q, want init", item.Name)
+	}
+	if !s.MatchPhrase(keys[0], []string{"init"}) || s.MatchPhrase(keys[0], []string{"missing"}) {
+		t.Error("commit message not indexed as content")
+	}
+}
+
+func TestGitSavedTree(t *testing.T) {
+	root := newGitRepo(t, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+	s := newTestStore(t)
+	c := config.IndexerConfig{Type: string(GitIndexerType), Paths: []string{root}}
+	// update runs a new indexer, like the daemon does every run, and returns
+	// the number of visited files.
+	update := func() int {
+		t.Helper()
+		idx := NewGitIndexer(context.Background(), s, root, c)
+		go func() {
+			for range idx.Feedback() {
+			}
+		}()
+		if _, err := idx.UpdateIndex(); err != nil {
+			t.Fatal(err)
+		}
+		return idx.Info().Files
+	}
+	if n := update(); n != 3 {
+		t.Fatalf("first update visited %d files, want 3", n)
+	}
+
+	// A new indexer only visits the changes from the saved tree.
+	writeFiles(t, root, map[string]string{"a.txt": "a2"})
+	if err := os.Remove(filepath.Join(root, "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, root, "change")
+	if n := update(); n != 1 {
+		t.Errorf("update visited %d files, want the changed one", n)
+	}
+	if keys := s.Keys("git_gitfile_", "", 0); len(keys) != 2 {
+		t.Errorf("indexed files %v, want 2", keys)
+	}
+
+	// A forgotten tree is walked again.
+	if err := Forget(s, GitIndexerType, root); err != nil {
+		t.Fatal(err)
+	}
+	if n := update(); n != 2 {
+		t.Errorf("update after Forget visited %d files, want 2", n)
+	}
+}
@@ -2,7 +2,11 @@ package indexer
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/shtirlic/knotidx/internal/config"
@@ -15,6 +19,7 @@ type Indexer interface {
 	UpdateIndex() (time.Duration, error) // UpdateIndex updates the index.
 	CleanIndex(prefix string) error      // CleanIndex cleans the index based on the provided prefix.
 	Type() IndexerType                   // Type returns the type of the indexer.
+	Root() string                        // Root returns the indexed root path.
 	Watch()                              // Watch monitors for changes in the index.
 	Info() IndexerRuntimeInfo            // Get information about the Indexer runtime stutus.
 	Feedback() chan IndexerRuntimeInfo
@@ -25,11 +30,82 @@ type IndexerRuntimeInfo struct {
 	FinishTime time.Time
 	Duration   time.Duration
 	Status     string
+	Dirs       int      // Directories and other containers indexed by the update.
+	Files      int      // Files and other leaf items indexed by the update.
+	Failed     int      // Items that couldn't be read by the update.
+	Total      int      // Items indexed by the update.
+	Skipped    int      // Unchanged items not rewritten by the update.
+	Updated    int      // New and changed items written by the update.
+	Removed    int      // Stale items removed by the update.
+	Watches    int      // Directories watched for changes with inotify.
+	Unwatched  []string // Directories beyond the inotify watch limit, with their subtrees.
+}
+
+// info holds the runtime info of an indexer, updated by its goroutines and
+// read by status requests.
+type info struct {
+	mu sync.Mutex
+	IndexerRuntimeInfo
+}
+
+// update applies the change to the runtime info.
+func (i *info) update(f func(info *IndexerRuntimeInfo)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	f(&i.IndexerRuntimeInfo)
+}
+
+// get returns a copy of the runtime info.
+func (i *info) get() IndexerRuntimeInfo {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.IndexerRuntimeInfo
+}
+
+// finish marks the update finished, or canceled if the context is done.
+func (i *info) finish(ctx context.Context) {
+	i.update(func(info *IndexerRuntimeInfo) {
+		info.Status = "Finished"
+		if ctx.Err() != nil {
+			info.Status = "Canceled"
+		}
+		info.FinishTime = time.Now()
+		info.Duration = info.FinishTime.Sub(info.StartTime)
+	})
 }
 
 // IndexerType represents the type of an indexer.
 type IndexerType string
 
+// RootPath returns the root path of the indexer of the type for the
+// configured path, as returned by its Root method.
+func RootPath(t IndexerType, path string) string {
+	if t == S3IndexerType {
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(path, "s3://"), "/")
+		return "s3://" + bucket + "/" + prefix
+	}
+	return filepath.Clean(path)
+}
+
+// Within reports whether the path is the root or below it.
+func Within(path, root string) bool {
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/")
+}
+
+// StateName returns the name of the state saved in the store across runs by
+// the indexers of the type and root, e.g. the marker of an interrupted s3
+// listing or the last indexed git tree.
+func StateName(t IndexerType, root string) string {
+	return fmt.Sprintf("%s_%s", t, root)
+}
+
+// Forget deletes the state saved in the store by the indexers of the type and
+// root, when they are removed or their config changes, so later indexers of
+// the root start over.
+func Forget(s store.Store, t IndexerType, root string) error {
+	return s.SetState(StateName(t, root), nil)
+}
+
 // NewIndexers creates a slice of indexers based on the provided configuration and store.
 func NewIndexers(ctx context.Context, c config.IndexerConfig, s store.Store) []Indexer {
 	var indexers []Indexer
@@ -38,6 +114,15 @@ func NewIndexers(ctx context.Context, c config.IndexerConfig, s store.Store) []I
 		case FileSystemIndexerType:
 			// Create a new FileSystemIndexer for each path.
 			indexers = append(indexers, NewFileSystemIndexer(ctx, s, path, c))
+		case GitIndexerType:
+			// Create a new GitIndexer for each repository path.
+			indexers = append(indexers, NewGitIndexer(ctx, s, path, c))
+		case SysfsIndexerType:
+			// Create a new SysfsIndexer for each sysfs mount point.
+			indexers = append(indexers, NewSysfsIndexer(ctx, s, path, c))
+		case S3IndexerType:
+			// Create a new S3Indexer for each bucket prefix.
+			indexers = append(indexers, NewS3Indexer(ctx, s, path, c))
 		default:
 			slog.Warn("indexer type is unknown", "type", c.Type)
 		}
@@ -0,0 +1,294 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/s3"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+const (
+	// ObjectItemType represents the item type for object storage objects.
+	ObjectItemType store.ItemType = "object"
+
+	// S3IndexerType represents the type of the S3Indexer.
+	S3IndexerType IndexerType = "s3"
+
+	// DefaultS3Refresh is the default relisting interval in seconds.
+	DefaultS3Refresh = 300
+)
+
+// S3Indexer represents an indexer implementation for S3 compatible object storages.
+// It lists the objects of a bucket prefix with the ListObjectsV2 API.
+type S3Indexer struct {
+	RootPath string        // RootPath is the root URL in the format "s3://bucket/prefix".
+	Bucket   string        // Bucket is the name of the listed bucket.
+	Prefix   string        // Prefix is the key prefix of the listed objects.
+	Refresh  time.Duration // Refresh is the interval between relistings.
+	Store    store.Store   // Store is the data store to index items.
+
+	client   *s3.Client              // client is the S3 API client.
+	config   config.IndexerConfig    // config is the configuration for the indexer.
+	ctx      context.Context         // ctx is the cancel conext.
+	info     info                    // runtime info
+	feedback chan IndexerRuntimeInfo // feedback channel
+	mu       sync.Mutex              // mu serializes index updates.
+}
+
+// NewS3Indexer creates a new instance of S3Indexer with the provided store,
+// "bucket/prefix" path, and configuration. It returns an Indexer interface.
+func NewS3Indexer(ctx context.Context, store store.Store, bucketPath string, c config.IndexerConfig) Indexer {
+	refresh := c.Refresh
+	if refresh == 0 {
+		refresh = DefaultS3Refresh
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(bucketPath, "s3://"), "/")
+
+	return &S3Indexer{
+		RootPath: RootPath(S3IndexerType, bucketPath),
+		Bucket:   bucket,
+		Prefix:   prefix,
+		Refresh:  time.Duration(refresh) * time.Second,
+		Store:    store,
+		client:   s3.NewClient(c.Endpoint, c.Region, c.AccessKey, c.SecretKey, c.SessionToken),
+		config:   c,
+		ctx:      ctx,
+		info:     info{IndexerRuntimeInfo: IndexerRuntimeInfo{Status: "Created"}},
+		feedback: make(chan IndexerRuntimeInfo, 1),
+	}
+}
+
+// Type returns the type of the S3Indexer.
+func (idx *S3Indexer) Type() IndexerType {
+	return S3IndexerType
+}
+
+// Root returns the root path of the S3Indexer.
+func (idx *S3Indexer) Root() string {
+	return idx.RootPath
+}
+
+// Config returns the configuration of the S3Indexer.
+func (idx *S3Indexer) Config() config.IndexerConfig {
+	return idx.config
+}
+
+// Feedback returns feeback channel.
+func (idx *S3Indexer) Feedback() chan IndexerRuntimeInfo {
+	return idx.feedback
+}
+
+// Info returns runtime info.
+func (idx *S3Indexer) Info() IndexerRuntimeInfo {
+	return idx.info.get()
+}
+
+// UpdateIndex lists the bucket prefix and reports progress to the feedback channel.
+func (idx *S3Indexer) UpdateIndex() (time.Duration, error) {
+	startTime := time.Now()
+	idx.info.update(func(info *IndexerRuntimeInfo) {
+		info.StartTime = startTime
+		info.Status = "Started"
+	})
+	idx.feedback <- idx.Info()
+	defer close(idx.feedback)
+
+	if err := idx.update(true); err != nil && !errors.Is(err, context.Canceled) {
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Status = "Failed" })
+		return 0, err
+	}
+
+	idx.info.finish(idx.ctx)
+	idx.feedback <- idx.Info()
+	return time.Since(startTime), nil
+}
+
+// CleanIndex removes the objects of the bucket prefix with the specified prefix,
+// e.g. "object_" for all indexed objects.
+func (idx *S3Indexer) CleanIndex(prefix string) error {
+	for _, key := range idx.Store.Keys(string(idx.Type())+"_"+prefix, "", 0) {
+		if !idx.ownsKey(key) {
+			continue
+		}
+		if err := idx.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+	idx.Store.Maintenance()
+	return nil
+}
+
+// Watch relists the bucket prefix periodically if notifications are enabled.
+func (idx *S3Indexer) Watch() {
+	if !idx.config.Notify || idx.Refresh <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(idx.Refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := idx.update(false); err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("S3 relisting failed", "url", idx.RootPath, "error", err)
+			}
+		case <-idx.ctx.Done():
+			slog.Debug("Quit s3 relisting", "idx RootPath", idx.RootPath)
+			return
+		}
+	}
+}
+
+// update lists the objects after the saved marker, stores the new and changed
+// ones and removes the deleted ones page by page.
+func (idx *S3Indexer) update(report bool) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	// The last stored key of an interrupted listing is saved as state, so the
+	// listing resumes after it instead of starting over.
+	name := StateName(idx.Type(), idx.RootPath)
+	marker := string(idx.Store.State(name))
+	if marker != "" {
+		slog.Debug("Resume s3 listing", "url", idx.RootPath, "marker", marker)
+	}
+
+	in := s3.ListInput{Bucket: idx.Bucket, Prefix: idx.Prefix, StartAfter: marker}
+	for {
+		out, err := idx.client.ListObjects(idx.ctx, in)
+		if err != nil {
+			return err
+		}
+
+		listed := make(map[string]bool, len(out.Objects))
+		itemList := make(map[string]store.ItemInfo)
+		for _, o := range out.Objects {
+			if report {
+				select {
+				case <-idx.feedback:
+				default:
+					idx.feedback <- idx.Info()
+				}
+			}
+			// Skip the empty "directory" objects.
+			if strings.HasSuffix(o.Key, "/") {
+				continue
+			}
+
+			itemInfo := idx.object(o)
+			key := fmt.Sprintf("%s_%s", idx.Type(), itemInfo.KeyName())
+			listed[key] = true
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Files++; info.Total++ })
+
+			// Skip unchanged objects.
+			old := idx.Store.Find(key)
+			if old.Meta["etag"] == o.ETag && old.Size == o.Size && old.ModTime.Equal(o.LastModified) {
+				continue
+			}
+			if idx.config.HeadObjects {
+				if ct, err := idx.client.ContentType(idx.ctx, idx.Bucket, o.Key); err == nil && ct != "" {
+					itemInfo.MimeType = ct
+				} else if err != nil {
+					idx.info.update(func(info *IndexerRuntimeInfo) { info.Failed++ })
+					slog.Debug("Can't get s3 object content type", "key", o.Key, "error", err)
+				}
+			}
+			itemInfo.Hash = itemInfo.XXhash()
+			itemList[key] = itemInfo
+		}
+		if len(itemList) > 0 {
+			if err := idx.Store.Add(itemList); err != nil {
+				return err
+			}
+		}
+
+		// Objects are listed in key order, so the stored objects after the
+		// previous page up to the last key of this one, or to the end of the
+		// bucket prefix for the last page, missing from the page were deleted.
+		done := !out.IsTruncated || out.NextContinuationToken == ""
+		last := marker
+		if n := len(out.Objects); n > 0 {
+			last = out.Objects[n-1].Key
+		}
+		if done || last != marker {
+			upTo := last
+			if done {
+				upTo = ""
+			}
+			if err := idx.removeUnlisted(marker, upTo, listed); err != nil {
+				return err
+			}
+		}
+		if done {
+			break
+		}
+
+		// Save the last stored key to resume an interrupted listing.
+		marker = last
+		if err := idx.Store.SetState(name, []byte(marker)); err != nil {
+			return err
+		}
+		in.StartAfter, in.ContinuationToken = "", out.NextContinuationToken
+	}
+	if err := idx.Store.SetState(name, nil); err != nil {
+		return err
+	}
+
+	info := idx.Info()
+	slog.Debug(fmt.Sprintf("Total: %d, Failed: %d, rootPath: %s", info.Total, info.Failed, idx.RootPath))
+	return nil
+}
+
+// removeUnlisted removes the stored objects of the root after the object key
+// and up to the last one, or to the end if empty, missing from the listed keys.
+func (idx *S3Indexer) removeUnlisted(after string, last string, listed map[string]bool) error {
+	prefix := fmt.Sprintf("%s_%s_s3://%s/", idx.Type(), ObjectItemType, idx.Bucket)
+	so := store.SearchOptions{Prefix: prefix + idx.Prefix}
+	if after != "" {
+		so.After = prefix + after
+	}
+	var missing []string
+	idx.Store.Each(so, func(r store.SearchResult) bool {
+		if last != "" && r.Key > prefix+last {
+			return false
+		}
+		if !listed[r.Key] && idx.ownsKey(r.Key) {
+			missing = append(missing, r.Key)
+		}
+		return true
+	})
+	for _, key := range missing {
+		if err := idx.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// object returns the item of the listed object with the content type guessed
+// by extension and the ETag and storage class as metadata.
+func (idx *S3Indexer) object(o s3.Object) store.ItemInfo {
+	itemInfo := store.NewItemInfo(path.Base(o.Key), "s3://"+idx.Bucket+"/"+o.Key, o.LastModified, o.Size, ObjectItemType)
+	itemInfo.MimeType = mime.TypeByExtension(path.Ext(o.Key))
+	itemInfo.Meta = map[string]string{"etag": o.ETag}
+	if o.StorageClass != "" {
+		itemInfo.Meta["storage_class"] = o.StorageClass
+	}
+	return itemInfo
+}
+
+// ownsKey reports whether the store key belongs to the bucket prefix of the indexer.
+func (idx *S3Indexer) ownsKey(key string) bool {
+	item := strings.SplitN(key, "_", 3)
+	return len(item) == 3 && strings.HasPrefix(item[2], idx.RootPath)
+}
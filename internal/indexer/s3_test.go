@@ -0,0 +1,179 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shtirlic/knotidx/internal/config"
+)
+
+// fakeS3 is an in-process S3 server of a single bucket, listing its objects
+// in pages of two with continuation tokens.
+type fakeS3 struct {
+	mu         sync.Mutex
+	objects    map[string]string // ETags by object key.
+	heads      []string          // Keys of the HEAD requests.
+	startAfter []string          // start-after parameters of the first page requests.
+	failPage   bool              // Fail the next request of a second page.
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Method == http.MethodHead {
+		f.heads = append(f.heads, strings.TrimPrefix(r.URL.Path, "/bucket/"))
+		w.Header().Set("Content-Type", "text/x-test")
+		return
+	}
+	q := r.URL.Query()
+	after := q.Get("continuation-token")
+	if after == "" {
+		after = q.Get("start-after")
+		f.startAfter = append(f.startAfter, after)
+	} else if f.failPage {
+		f.failPage = false
+		http.Error(w, "<Error><Code>InternalError</Code><Message>failed</Message></Error>", http.StatusInternalServerError)
+		return
+	}
+
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, q.Get("prefix")) && k > after {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	next := ""
+	if len(keys) > 2 {
+		keys, next = keys[:2], keys[1]
+	}
+	fmt.Fprintf(w, `<ListBucketResult><IsTruncated>%v</IsTruncated><NextContinuationToken>%s</NextContinuationToken>`, next != "", next)
+	for _, k := range keys {
+		fmt.Fprintf(w, `<Contents><Key>%s</Key><Size>1</Size><ETag>"%s"</ETag><LastModified>2024-01-02T03:04:05Z</LastModified></Contents>`, k, f.objects[k])
+	}
+	fmt.Fprint(w, `</ListBucketResult>`)
+}
+
+// newFakeS3 starts a fake S3 server of the objects with their ETags, and
+// returns the config of an s3 indexer of the docs/ prefix of its bucket.
+func newFakeS3(t *testing.T, objects map[string]string) (*fakeS3, config.IndexerConfig) {
+	f := &fakeS3{objects: objects}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, config.IndexerConfig{
+		Type:        string(S3IndexerType),
+		Paths:       []string{"bucket/docs/"},
+		Endpoint:    srv.URL,
+		AccessKey:   "key",
+		SecretKey:   "secret",
+		HeadObjects: true,
+	}
+}
+
+func TestS3Update(t *testing.T) {
+	f, c := newFakeS3(t, map[string]string{
+		"docs/a.txt": "a1",
+		"docs/b.txt": "b1",
+		"docs/c.txt": "c1",
+		"docs/sub/":  "d1",
+		"other/x":    "x1",
+	})
+	s := newTestStore(t)
+	objectKeys := func() (keys []string) {
+		for _, k := range s.Keys("s3_object_", "", 0) {
+			keys = append(keys, strings.TrimPrefix(k, "s3_object_s3://bucket/"))
+		}
+		return
+	}
+
+	updateIndex(t, s, c)
+	if got, want := objectKeys(), []string{"docs/a.txt", "docs/b.txt", "docs/c.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("objects after first update = %q, want %q", got, want)
+	}
+	if got := f.heads; len(got) != 3 {
+		t.Fatalf("HEAD requests of first update = %q, want 3", got)
+	}
+	if item := s.Find("s3_object_s3://bucket/docs/a.txt"); item.MimeType != "text/x-test" || item.Meta["etag"] != "a1" {
+		t.Errorf("item %+v, want the content type and ETag of the object", item)
+	}
+
+	// Change an object and delete another.
+	f.mu.Lock()
+	f.objects["docs/b.txt"] = "b2"
+	delete(f.objects, "docs/c.txt")
+	f.heads = nil
+	f.mu.Unlock()
+
+	updateIndex(t, s, c)
+	if got, want := objectKeys(), []string{"docs/a.txt", "docs/b.txt"}; !slices.Equal(got, want) {
+		t.Errorf("objects after second update = %q, want %q", got, want)
+	}
+	if got, want := f.heads, []string{"docs/b.txt"}; !slices.Equal(got, want) {
+		t.Errorf("HEAD requests of second update = %q, want only the changed object %q", got, want)
+	}
+	if item := s.Find("s3_object_s3://bucket/docs/b.txt"); item.Meta["etag"] != "b2" {
+		t.Errorf("changed item ETag = %q, want b2", item.Meta["etag"])
+	}
+
+	// Delete an object of the first page, removed before the listing completes.
+	f.mu.Lock()
+	delete(f.objects, "docs/a.txt")
+	f.objects["docs/e.txt"] = "e1"
+	f.mu.Unlock()
+
+	updateIndex(t, s, c)
+	if got, want := objectKeys(), []string{"docs/b.txt", "docs/e.txt"}; !slices.Equal(got, want) {
+		t.Errorf("objects after third update = %q, want %q", got, want)
+	}
+}
+
+func TestS3ResumeAndForget(t *testing.T) {
+	f, c := newFakeS3(t, map[string]string{
+		"docs/a.txt": "a1",
+		"docs/b.txt": "b1",
+		"docs/c.txt": "c1",
+	})
+	s := newTestStore(t)
+	update := func() error {
+		idx := NewIndexers(context.Background(), c, s)[0]
+		go func() {
+			for range idx.Feedback() {
+			}
+		}()
+		_, err := idx.UpdateIndex()
+		return err
+	}
+
+	// An interrupted listing resumes after the last stored key.
+	f.failPage = true
+	if err := update(); err == nil {
+		t.Fatal("update with a failed page succeeded")
+	}
+	if err := update(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.startAfter, []string{"", "docs/b.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("start-after of the listings = %q, want %q", got, want)
+	}
+
+	// A forgotten interrupted listing starts over.
+	f.failPage = true
+	if err := update(); err == nil {
+		t.Fatal("update with a failed page succeeded")
+	}
+	if err := Forget(s, S3IndexerType, RootPath(S3IndexerType, c.Paths[0])); err != nil {
+		t.Fatal(err)
+	}
+	if err := update(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.startAfter[2:], []string{"", ""}; !slices.Equal(got, want) {
+		t.Errorf("start-after of the listings after Forget = %q, want %q", got, want)
+	}
+}
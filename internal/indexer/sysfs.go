@@ -0,0 +1,365 @@
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/config"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+const (
+	// DeviceItemType represents the item type for sysfs device nodes.
+	DeviceItemType store.ItemType = "device"
+
+	// SysfsIndexerType represents the type of the SysfsIndexer.
+	SysfsIndexerType IndexerType = "sysfs"
+
+	// DefaultSysfsRefresh is the default refresh interval in seconds.
+	DefaultSysfsRefresh = 60
+
+	// maxSysfsDepth limits the depth of the devices tree walk.
+	maxSysfsDepth = 32
+
+	// maxSysfsAttrSize limits the size of the attribute files read.
+	maxSysfsAttrSize = 4096
+)
+
+// sysfsClassDirs lists the directories indexed besides the devices tree, their
+// entries are usually symbolic links into the devices tree.
+var sysfsClassDirs = []string{"block", "class/net", "class/power_supply"}
+
+// sysfsAttrs lists the device attributes stored as item metadata.
+var sysfsAttrs = []string{
+	"vendor", "device", "model", "serial", "name", // pci, block, input
+	"idVendor", "idProduct", "manufacturer", "product", // usb
+	"address", "operstate", // net
+	"type", "status", "capacity", "model_name", // power_supply
+}
+
+// SysfsIndexer represents an indexer implementation for the sysfs hardware
+// and kernel objects. It indexes device nodes with their key attributes and
+// refreshes them periodically, as sysfs doesn't support notifications.
+type SysfsIndexer struct {
+	RootPath string        // RootPath is the sysfs mount point, e.g. /sys.
+	Refresh  time.Duration // Refresh is the interval between index refreshes.
+	Store    store.Store   // Store is the data store to index items.
+
+	config   config.IndexerConfig    // config is the configuration for the indexer.
+	ctx      context.Context         // ctx is the cancel conext.
+	info     info                    // runtime info
+	feedback chan IndexerRuntimeInfo // feedback channel
+	mu       sync.Mutex              // mu serializes index updates.
+}
+
+// NewSysfsIndexer creates a new instance of SysfsIndexer with the provided store,
+// sysfs root path, and configuration. It returns an Indexer interface.
+func NewSysfsIndexer(ctx context.Context, store store.Store, rootPath string, c config.IndexerConfig) Indexer {
+	refresh := c.Refresh
+	if refresh == 0 {
+		refresh = DefaultSysfsRefresh
+	}
+	return &SysfsIndexer{
+		RootPath: filepath.Clean(rootPath),
+		Refresh:  time.Duration(refresh) * time.Second,
+		Store:    store,
+		config:   c,
+		ctx:      ctx,
+		info:     info{IndexerRuntimeInfo: IndexerRuntimeInfo{Status: "Created"}},
+		feedback: make(chan IndexerRuntimeInfo, 1),
+	}
+}
+
+// Type returns the type of the SysfsIndexer.
+func (idx *SysfsIndexer) Type() IndexerType {
+	return SysfsIndexerType
+}
+
+// Root returns the root path of the SysfsIndexer.
+func (idx *SysfsIndexer) Root() string {
+	return idx.RootPath
+}
+
+// Config returns the configuration of the SysfsIndexer.
+func (idx *SysfsIndexer) Config() config.IndexerConfig {
+	return idx.config
+}
+
+// Feedback returns feeback channel.
+func (idx *SysfsIndexer) Feedback() chan IndexerRuntimeInfo {
+	return idx.feedback
+}
+
+// Info returns runtime info.
+func (idx *SysfsIndexer) Info() IndexerRuntimeInfo {
+	return idx.info.get()
+}
+
+// UpdateIndex indexes the device nodes and reports progress to the feedback channel.
+func (idx *SysfsIndexer) UpdateIndex() (time.Duration, error) {
+	startTime := time.Now()
+	idx.info.update(func(info *IndexerRuntimeInfo) {
+		info.StartTime = startTime
+		info.Status = "Started"
+	})
+	idx.feedback <- idx.Info()
+	defer close(idx.feedback)
+
+	if err := idx.update(true); err != nil && !errors.Is(err, context.Canceled) {
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Status = "Failed" })
+		return 0, err
+	}
+
+	idx.info.finish(idx.ctx)
+	idx.feedback <- idx.Info()
+	return time.Since(startTime), nil
+}
+
+// CleanIndex removes the device items with the specified prefix whose sysfs
+// directories are gone.
+func (idx *SysfsIndexer) CleanIndex(prefix string) error {
+	for _, key := range idx.Store.Keys(string(idx.Type())+"_"+prefix, "", 0) {
+		item := strings.SplitN(key, "_", 3)
+		if len(item) < 3 || !idx.ownsPath(item[2]) {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(item[2], "uevent")); err == nil {
+			continue
+		}
+		if err := idx.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+	idx.Store.Maintenance()
+	return nil
+}
+
+// Watch refreshes the index periodically if notifications are enabled,
+// since sysfs doesn't support fsnotify.
+func (idx *SysfsIndexer) Watch() {
+	if !idx.config.Notify || idx.Refresh <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(idx.Refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := idx.update(false); err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("Sysfs index refresh failed", "path", idx.RootPath, "error", err)
+			}
+		case <-idx.ctx.Done():
+			slog.Debug("Quit sysfs refresh", "idx RootPath", idx.RootPath)
+			return
+		}
+	}
+}
+
+// update walks the devices tree and the class directories, stores the changed
+// device items and removes the ones that are gone.
+func (idx *SysfsIndexer) update(report bool) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	devices := make(map[string]store.ItemInfo)
+	classes := make(map[string][]string)
+	idx.info.update(func(info *IndexerRuntimeInfo) { info.Total, info.Failed = 0, 0 })
+
+	// The devices tree has no symbolic links to directories, so walking it can't loop.
+	devicesPath := filepath.Join(idx.RootPath, "devices")
+	depth := strings.Count(devicesPath, string(filepath.Separator))
+	err := filepath.WalkDir(devicesPath, func(p string, d fs.DirEntry, err error) error {
+		if err := idx.ctx.Err(); err != nil {
+			return err
+		}
+		if report {
+			select {
+			case <-idx.feedback:
+			default:
+				idx.feedback <- idx.Info()
+			}
+		}
+		if err != nil {
+			slog.Debug("Sysfs walk", "path", p, "error", err)
+			idx.info.update(func(info *IndexerRuntimeInfo) { info.Failed++ })
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if strings.Count(p, string(filepath.Separator))-depth > maxSysfsDepth {
+			return filepath.SkipDir
+		}
+		if isRegular(filepath.Join(p, "uevent")) {
+			devices[p] = idx.device(p)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// Class entries link to device nodes, only the links resolving inside the
+	// sysfs root are followed.
+	for _, class := range sysfsClassDirs {
+		entries, err := os.ReadDir(filepath.Join(idx.RootPath, filepath.FromSlash(class)))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			p, err := filepath.EvalSymlinks(filepath.Join(idx.RootPath, filepath.FromSlash(class), e.Name()))
+			if err != nil || !idx.ownsPath(p) || !isRegular(filepath.Join(p, "uevent")) {
+				continue
+			}
+			if _, ok := devices[p]; !ok {
+				devices[p] = idx.device(p)
+			}
+			classes[p] = append(classes[p], filepath.Base(class))
+		}
+	}
+
+	// Store the new and changed devices only.
+	itemList := make(map[string]store.ItemInfo)
+	seen := make(map[string]bool, len(devices))
+	for p, itemInfo := range devices {
+		if c, ok := classes[p]; ok {
+			itemInfo.Meta["class"] = strings.Join(c, ",")
+		}
+		itemInfo.Hash = itemInfo.XXhash()
+
+		key := fmt.Sprintf("%s_%s", idx.Type(), itemInfo.KeyName())
+		seen[key] = true
+		idx.info.update(func(info *IndexerRuntimeInfo) { info.Total++ })
+		if idx.Store.Find(key).Hash == itemInfo.Hash {
+			continue
+		}
+		itemList[key] = itemInfo
+		if len(itemList) > store.BatchCount {
+			if err := idx.Store.Add(itemList); err != nil {
+				return err
+			}
+			clear(itemList)
+		}
+	}
+	if len(itemList) > 0 {
+		if err := idx.Store.Add(itemList); err != nil {
+			return err
+		}
+	}
+
+	// Remove the devices that are gone.
+	for _, key := range idx.Store.Keys(fmt.Sprintf("%s_%s_%s", idx.Type(), DeviceItemType, idx.RootPath), "", 0) {
+		item := strings.SplitN(key, "_", 3)
+		if seen[key] || !idx.ownsPath(item[2]) {
+			continue
+		}
+		if err := idx.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	info := idx.Info()
+	slog.Debug(fmt.Sprintf("Total: %d, Failed: %d, rootPath: %s", info.Total, info.Failed, idx.RootPath))
+	return nil
+}
+
+// device returns the item of the device node directory with its uevent,
+// subsystem, driver and key attributes as metadata.
+func (idx *SysfsIndexer) device(p string) store.ItemInfo {
+	var modTime time.Time
+	if fi, err := os.Stat(filepath.Join(p, "uevent")); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	meta := make(map[string]string)
+	if uevent := readUevent(filepath.Join(p, "uevent")); len(uevent) > 0 {
+		meta["uevent"] = strings.Join(uevent, " ")
+	}
+	for _, link := range []string{"subsystem", "driver"} {
+		if target, err := os.Readlink(filepath.Join(p, link)); err == nil {
+			meta[link] = filepath.Base(target)
+		}
+	}
+	for _, attr := range sysfsAttrs {
+		if v, ok := readAttr(filepath.Join(p, attr)); ok && v != "" {
+			meta[attr] = v
+		}
+	}
+
+	// Block device sizes are in 512 byte sectors.
+	var size int64
+	if meta["subsystem"] == "block" {
+		if v, ok := readAttr(filepath.Join(p, "size")); ok {
+			sectors, _ := strconv.ParseInt(v, 10, 64)
+			size = sectors * 512
+		}
+	}
+
+	itemInfo := store.NewItemInfo(filepath.Base(p), p, modTime, size, DeviceItemType)
+	itemInfo.Meta = meta
+	return itemInfo
+}
+
+// ownsPath reports whether the path is inside the sysfs root of the indexer.
+func (idx *SysfsIndexer) ownsPath(p string) bool {
+	rel, ok := strings.CutPrefix(p, idx.RootPath)
+	return ok && (rel == "" || rel[0] == filepath.Separator || idx.RootPath == string(filepath.Separator))
+}
+
+// readAttr reads a single line sysfs attribute. Unreadable attributes, like the
+// write only ones, are reported as missing.
+func readAttr(name string) (string, bool) {
+	if !isRegular(name) {
+		return "", false
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxSysfsAttrSize))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// readUevent reads the KEY=VALUE lines of the uevent file sorted by key.
+func readUevent(name string) (lines []string) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	s := bufio.NewScanner(io.LimitReader(f, maxSysfsAttrSize))
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); strings.Contains(line, "=") {
+			lines = append(lines, line)
+		}
+	}
+	slices.Sort(lines)
+	return
+}
+
+// isRegular reports whether the path is a regular file, without following symbolic links.
+func isRegular(name string) bool {
+	fi, err := os.Lstat(name)
+	return err == nil && fi.Mode().IsRegular()
+}
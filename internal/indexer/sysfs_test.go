@@ -0,0 +1,110 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shtirlic/knotidx/internal/config"
+)
+
+// symlink creates the symbolic link with its target.
+func symlink(t *testing.T, target, name string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newSysfsTree creates a fake sysfs tree with a PCI network device, its
+// interface and the loopback interface, class links to the interfaces, a link
+// outside the tree and a link loop.
+func newSysfsTree(t *testing.T) string {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pci := "devices/pci0000:00/0000:00:1f.6"
+	writeFiles(t, root, map[string]string{
+		pci + "/uevent":                  "DRIVER=e1000e\nPCI_SLOT_NAME=0000:00:1f.6\n",
+		pci + "/vendor":                  "0x8086\n",
+		pci + "/net/eth0/uevent":         "INTERFACE=eth0\nIFINDEX=2\n",
+		pci + "/net/eth0/address":        "aa:bb:cc:dd:ee:ff\n",
+		pci + "/net/eth0/operstate":      "up\n",
+		"devices/virtual/net/lo/uevent":  "INTERFACE=lo\nIFINDEX=1\n",
+		"devices/platform/no-device/foo": "not a device\n",
+		"bus/pci/drivers/e1000e/bind":    "",
+	})
+	symlink(t, "../../../bus/pci", filepath.Join(root, pci, "subsystem"))
+	symlink(t, "../../../bus/pci/drivers/e1000e", filepath.Join(root, pci, "driver"))
+	symlink(t, "../../"+pci+"/net/eth0", filepath.Join(root, "class/net/eth0"))
+	symlink(t, "../../devices/virtual/net/lo", filepath.Join(root, "class/net/lo"))
+
+	// A device outside the sysfs root isn't indexed.
+	outside := t.TempDir()
+	writeFiles(t, outside, map[string]string{"uevent": "INTERFACE=outside\n"})
+	symlink(t, outside, filepath.Join(root, "class/net/outside"))
+
+	// Link loops are skipped.
+	symlink(t, "loop2", filepath.Join(root, "class/net/loop1"))
+	symlink(t, "loop1", filepath.Join(root, "class/net/loop2"))
+	return root
+}
+
+func TestSysfsUpdate(t *testing.T) {
+	root := newSysfsTree(t)
+	s := newTestStore(t)
+	c := config.IndexerConfig{Type: string(SysfsIndexerType), Paths: []string{root}}
+	devices := func() (paths []string) {
+		for _, k := range s.Keys("sysfs_device_", "", 0) {
+			paths = append(paths, strings.TrimPrefix(k, "sysfs_device_"+root+"/"))
+		}
+		return
+	}
+
+	if info := updateIndex(t, s, c); info.Total != 3 {
+		t.Errorf("Total = %d, want 3", info.Total)
+	}
+	want := []string{
+		"devices/pci0000:00/0000:00:1f.6",
+		"devices/pci0000:00/0000:00:1f.6/net/eth0",
+		"devices/virtual/net/lo",
+	}
+	if got := devices(); !slices.Equal(got, want) {
+		t.Fatalf("devices = %q, want %q", got, want)
+	}
+
+	pci := s.Find("sysfs_device_" + filepath.Join(root, want[0]))
+	for k, v := range map[string]string{
+		"uevent":    "DRIVER=e1000e PCI_SLOT_NAME=0000:00:1f.6",
+		"subsystem": "pci",
+		"driver":    "e1000e",
+		"vendor":    "0x8086",
+		"class":     "",
+	} {
+		if pci.Meta[k] != v {
+			t.Errorf("PCI device %s = %q, want %q", k, pci.Meta[k], v)
+		}
+	}
+	eth0 := s.Find("sysfs_device_" + filepath.Join(root, want[1]))
+	if eth0.Meta["class"] != "net" || eth0.Meta["address"] != "aa:bb:cc:dd:ee:ff" || eth0.Meta["operstate"] != "up" {
+		t.Errorf("eth0 meta = %v, want net class, address and operstate", eth0.Meta)
+	}
+	if lo := s.Find("sysfs_device_" + filepath.Join(root, want[2])); lo.Meta["class"] != "net" {
+		t.Errorf("lo class = %q, want net", lo.Meta["class"])
+	}
+
+	// Devices are removed with their directory.
+	if err := os.RemoveAll(filepath.Join(root, "devices/virtual")); err != nil {
+		t.Fatal(err)
+	}
+	updateIndex(t, s, c)
+	if got := devices(); !slices.Equal(got, want[:2]) {
+		t.Errorf("devices after removal = %q, want %q", got, want[:2])
+	}
+}
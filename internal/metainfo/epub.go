@@ -0,0 +1,94 @@
+package metainfo
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func init() {
+	Register(extractEPUB, "application/epub+zip")
+}
+
+// Maximum size of the EPUB XML files read.
+const maxEPUBXMLSize = 1 << 20
+
+// epubContainer is the XML form of META-INF/container.xml.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the XML form of the Dublin Core metadata of the OPF package.
+type epubPackage struct {
+	Titles     []string `xml:"metadata>title"`
+	Creators   []string `xml:"metadata>creator"`
+	Publishers []string `xml:"metadata>publisher"`
+	Languages  []string `xml:"metadata>language"`
+	Dates      []string `xml:"metadata>date"`
+	Subjects   []string `xml:"metadata>subject"`
+}
+
+// extractEPUB extracts the title, author, publisher, language, year and
+// subject of the OPF package of an EPUB file.
+func extractEPUB(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	var container epubContainer
+	if err := decodeZipXML(z, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	opf := ""
+	for _, f := range container.Rootfiles {
+		if f.MediaType == "" || f.MediaType == "application/oebps-package+xml" {
+			opf = path.Clean(f.FullPath)
+			break
+		}
+	}
+	if opf == "" {
+		return nil, fmt.Errorf("%w: no EPUB package", ErrFormat)
+	}
+
+	var pkg epubPackage
+	if err := decodeZipXML(z, opf, &pkg); err != nil {
+		return nil, err
+	}
+	first := func(s []string) string {
+		for _, v := range s {
+			if v = cleanString(v); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	return &store.MediaInfo{
+		Title:     first(pkg.Titles),
+		Author:    first(pkg.Creators),
+		Publisher: first(pkg.Publishers),
+		Language:  first(pkg.Languages),
+		Genre:     first(pkg.Subjects),
+		Year:      parseYear(first(pkg.Dates)),
+	}, nil
+}
+
+// decodeZipXML decodes the XML file of the archive into v.
+func decodeZipXML(z *zip.Reader, name string, v any) error {
+	f, err := z.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(io.LimitReader(f, maxEPUBXMLSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrFormat, name, err)
+	}
+	return nil
+}
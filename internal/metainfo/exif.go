@@ -0,0 +1,214 @@
+package metainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func init() {
+	Register(extractJPEG, "image/jpeg", "image/pjpeg")
+	Register(extractTIFF, "image/tiff")
+}
+
+// TIFF and EXIF tags.
+const (
+	tagImageWidth       = 0x0100
+	tagImageLength      = 0x0101
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xa002
+	tagPixelYDimension  = 0xa003
+)
+
+// Maximum number of IFD entries read.
+const maxIFDEntries = 1024
+
+// extractJPEG extracts the dimensions of the JPEG frame and the EXIF metadata
+// of its APP1 segment.
+func extractJPEG(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	info := &store.MediaInfo{}
+	soi, err := readAt(r, 0, 2)
+	if err != nil || !bytes.Equal(soi, []byte{0xff, 0xd8}) {
+		return nil, fmt.Errorf("%w: not a JPEG file", ErrFormat)
+	}
+
+	for off := int64(2); off+4 <= size; {
+		h, err := readAt(r, off, 4)
+		if err != nil {
+			return info, err
+		}
+		if h[0] != 0xff {
+			return info, fmt.Errorf("%w: bad JPEG marker", ErrFormat)
+		}
+		marker := h[1]
+		switch {
+		case marker == 0xff:
+			// Fill byte.
+			off++
+			continue
+		case marker == 0xd8 || marker >= 0xd0 && marker <= 0xd7 || marker == 0x01:
+			// Markers without a segment.
+			off += 2
+			continue
+		case marker == 0xd9 || marker == 0xda:
+			// End of image or start of the scan data.
+			return info, nil
+		}
+		length := int64(binary.BigEndian.Uint16(h[2:]))
+		seg := io.NewSectionReader(r, off+4, length-2)
+
+		switch {
+		case marker == 0xe1 && length > 8:
+			hdr, err := readAt(seg, 0, 6)
+			if err == nil && bytes.Equal(hdr, []byte("Exif\x00\x00")) {
+				readTIFF(io.NewSectionReader(seg, 6, length-8), info)
+			}
+		case isSOF(marker) && length >= 7:
+			// Frame header: precision, height, width.
+			b, err := readAt(seg, 0, 5)
+			if err == nil && info.Width == 0 {
+				info.Height = int(binary.BigEndian.Uint16(b[1:]))
+				info.Width = int(binary.BigEndian.Uint16(b[3:]))
+			}
+		}
+		off += 2 + length
+	}
+	return info, nil
+}
+
+// isSOF reports whether the JPEG marker starts a frame.
+func isSOF(marker byte) bool {
+	return marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc
+}
+
+// extractTIFF extracts the dimensions and EXIF metadata of a TIFF file.
+func extractTIFF(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	info := &store.MediaInfo{}
+	if err := readTIFF(io.NewSectionReader(r, 0, size), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// tiff reads the image file directories of a TIFF structure.
+type tiff struct {
+	r     *io.SectionReader
+	order binary.ByteOrder
+}
+
+// readTIFF reads the camera, date and dimensions from the first IFD of the
+// TIFF structure and its EXIF IFD.
+func readTIFF(r *io.SectionReader, info *store.MediaInfo) error {
+	h, err := readAt(r, 0, 8)
+	if err != nil {
+		return err
+	}
+	t := tiff{r: r}
+	switch string(h[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return fmt.Errorf("%w: bad TIFF byte order", ErrFormat)
+	}
+	if t.order.Uint16(h[2:]) != 42 {
+		return fmt.Errorf("%w: bad TIFF magic", ErrFormat)
+	}
+
+	var maker, model, date string
+	ifd0, err := t.ifd(int64(t.order.Uint32(h[4:])))
+	if err != nil {
+		return err
+	}
+	for tag, e := range ifd0 {
+		switch tag {
+		case tagMake:
+			maker = t.ascii(e)
+		case tagModel:
+			model = t.ascii(e)
+		case tagDateTime:
+			date = t.ascii(e)
+		case tagImageWidth:
+			info.Width = t.uint(e)
+		case tagImageLength:
+			info.Height = t.uint(e)
+		}
+	}
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if exif, err := t.ifd(int64(t.uint(e))); err == nil {
+			if e, ok := exif[tagDateTimeOriginal]; ok {
+				date = t.ascii(e)
+			}
+			if e, ok := exif[tagPixelXDimension]; ok {
+				info.Width = t.uint(e)
+			}
+			if e, ok := exif[tagPixelYDimension]; ok {
+				info.Height = t.uint(e)
+			}
+		}
+	}
+
+	// Models usually repeat the make, e.g. "Canon" and "Canon EOS 5D".
+	if maker != "" && !strings.HasPrefix(strings.ToLower(model), strings.ToLower(maker)) {
+		model = strings.TrimSpace(maker + " " + model)
+	}
+	info.Camera = model
+	info.Year = parseYear(date)
+	return nil
+}
+
+// ifd reads the entries of the IFD at the offset by tag.
+func (t tiff) ifd(off int64) (map[uint16][]byte, error) {
+	n, err := readAt(t.r, off, 2)
+	if err != nil {
+		return nil, err
+	}
+	count := min(int(t.order.Uint16(n)), maxIFDEntries)
+	b, err := readAt(t.r, off+2, count*12)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[uint16][]byte, count)
+	for i := 0; i < count; i++ {
+		e := b[i*12 : (i+1)*12]
+		entries[t.order.Uint16(e)] = e
+	}
+	return entries, nil
+}
+
+// uint returns the value of a SHORT or LONG entry.
+func (t tiff) uint(e []byte) int {
+	switch t.order.Uint16(e[2:]) {
+	case 3: // SHORT
+		return int(t.order.Uint16(e[8:]))
+	case 4: // LONG
+		return int(t.order.Uint32(e[8:]))
+	}
+	return 0
+}
+
+// ascii returns the value of an ASCII entry, stored in the entry if it fits
+// in 4 bytes or at the offset of the entry otherwise.
+func (t tiff) ascii(e []byte) string {
+	if t.order.Uint16(e[2:]) != 2 {
+		return ""
+	}
+	count := int(t.order.Uint32(e[4:]))
+	if count <= 4 {
+		return cleanString(string(e[8 : 8+count]))
+	}
+	b, err := readAt(t.r, int64(t.order.Uint32(e[8:])), min(count, 1024))
+	if err != nil {
+		return ""
+	}
+	return cleanString(string(b))
+}
@@ -0,0 +1,330 @@
+package metainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func init() {
+	Register(extractMP3, "audio/mpeg", "audio/mp3", "audio/x-mp3")
+}
+
+// Maximum number of bytes searched for the first MPEG audio frame.
+const maxFrameSearch = 64 << 10
+
+// id3Frames maps the ID3v2.2 and ID3v2.3+ frame IDs to the metadata fields.
+var id3Frames = map[string]string{
+	"TT2": "title", "TIT2": "title",
+	"TP1": "artist", "TPE1": "artist",
+	"TAL": "album", "TALB": "album",
+	"TCO": "genre", "TCON": "genre",
+	"TYE": "year", "TYER": "year", "TDRC": "year",
+	"TLE": "length", "TLEN": "length",
+}
+
+// extractMP3 extracts the ID3v2 or ID3v1 tags of an MP3 file and its duration
+// from the Xing/VBRI header of the first frame or the bitrate.
+func extractMP3(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	info := &store.MediaInfo{}
+	var audio int64
+	if h, err := readAt(r, 0, 10); err == nil && bytes.Equal(h[:3], []byte("ID3")) {
+		audio = 10 + int64(syncsafe(h[6:10]))
+		if h[5]&0x10 != 0 {
+			// Footer.
+			audio += 10
+		}
+		if err := readID3v2(r, h, info); err != nil {
+			return nil, err
+		}
+	}
+	if info.Title == "" && info.Artist == "" && size >= 128 {
+		readID3v1(r, size, info)
+	}
+	if info.Duration == 0 {
+		info.Duration = mpegDuration(r, audio, size)
+	}
+	return info, nil
+}
+
+// syncsafe decodes a 28 bit integer stored in 7 bits per byte.
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// readID3v2 reads the text frames of the ID3v2 tag with the header h.
+func readID3v2(r io.ReaderAt, h []byte, info *store.MediaInfo) error {
+	version, flags := h[3], h[5]
+	if version < 2 || version > 4 {
+		return fmt.Errorf("%w: ID3v2.%d", ErrFormat, version)
+	}
+	tag, err := readAt(r, 10, syncsafe(h[6:10]))
+	if err != nil {
+		return err
+	}
+	if flags&0x80 != 0 && version < 4 {
+		// Tag level unsynchronisation.
+		tag = bytes.ReplaceAll(tag, []byte{0xff, 0x00}, []byte{0xff})
+	}
+	if flags&0x40 != 0 && version > 2 && len(tag) >= 4 {
+		// Extended header, its size excludes itself in ID3v2.3.
+		n := syncsafe(tag)
+		if version == 3 {
+			n = int(binary.BigEndian.Uint32(tag)) + 4
+		}
+		tag = tag[min(n, len(tag)):]
+	}
+
+	idLen, hdrLen := 4, 10
+	if version == 2 {
+		idLen, hdrLen = 3, 6
+	}
+	for len(tag) >= hdrLen && tag[0] != 0 {
+		id := string(tag[:idLen])
+		var n int
+		switch version {
+		case 2:
+			n = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 3:
+			n = int(binary.BigEndian.Uint32(tag[4:]))
+		default:
+			n = syncsafe(tag[4:])
+		}
+		if n < 0 || n > len(tag)-hdrLen {
+			break
+		}
+		data := tag[hdrLen : hdrLen+n]
+		var frameFlags byte
+		if version > 2 {
+			frameFlags = tag[9]
+		}
+		tag = tag[hdrLen+n:]
+
+		field, ok := id3Frames[id]
+		switch {
+		case !ok:
+			continue
+		case version == 3 && frameFlags&0xc0 != 0, version == 4 && frameFlags&0x0c != 0:
+			// Compressed or encrypted.
+			continue
+		case version == 4 && frameFlags&0x01 != 0 && len(data) >= 4:
+			// Data length indicator.
+			data = data[4:]
+		}
+		if version == 4 && frameFlags&0x02 != 0 {
+			data = bytes.ReplaceAll(data, []byte{0xff, 0x00}, []byte{0xff})
+		}
+		if len(data) == 0 {
+			continue
+		}
+		value := id3Text(data)
+		switch field {
+		case "title":
+			info.Title = value
+		case "artist":
+			info.Artist = value
+		case "album":
+			info.Album = value
+		case "genre":
+			info.Genre = id3Genre(value)
+		case "year":
+			info.Year = parseYear(value)
+		case "length":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				info.Duration = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return nil
+}
+
+// id3Text decodes the first value of a text frame with its encoding byte.
+func id3Text(data []byte) string {
+	enc, b := data[0], data[1:]
+	var s string
+	switch enc {
+	case 0: // ISO-8859-1
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		s = string(r)
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		var order binary.ByteOrder = binary.BigEndian
+		if len(b) >= 2 && b[0] == 0xff && b[1] == 0xfe {
+			order, b = binary.LittleEndian, b[2:]
+		} else if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+			b = b[2:]
+		}
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			u = append(u, order.Uint16(b[i:]))
+		}
+		s = string(utf16.Decode(u))
+	default: // UTF-8
+		s = string(b)
+	}
+	// Multiple values are separated by NUL.
+	s, _, _ = strings.Cut(s, "\x00")
+	return cleanString(s)
+}
+
+// id3Genres are the genres of the ID3v1 genre numbers.
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge",
+	"Hip-Hop", "Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B",
+	"Rap", "Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska",
+	"Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient",
+	"Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance", "Classical",
+	"Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative",
+	"Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic", "Darkwave",
+	"Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap",
+	"Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave",
+	"Psychadelic", "Rave", "Showtunes", "Trailer", "Lo-Fi", "Tribal",
+	"Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll",
+	"Hard Rock",
+}
+
+// id3Genre resolves genre references like "(17)", "(17)Rock" or "17".
+func id3Genre(s string) string {
+	ref := s
+	if rest, ok := strings.CutPrefix(s, "("); ok {
+		var name string
+		ref, name, _ = strings.Cut(rest, ")")
+		if name != "" {
+			return name
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n >= 0 && n < len(id3Genres) {
+			return id3Genres[n]
+		}
+		return ""
+	}
+	return s
+}
+
+// readID3v1 reads the ID3v1 tag at the end of the file.
+func readID3v1(r io.ReaderAt, size int64, info *store.MediaInfo) {
+	b, err := readAt(r, size-128, 128)
+	if err != nil || !bytes.Equal(b[:3], []byte("TAG")) {
+		return
+	}
+	latin1 := func(b []byte) string {
+		return id3Text(append([]byte{0}, b...))
+	}
+	info.Title = latin1(b[3:33])
+	info.Artist = latin1(b[33:63])
+	info.Album = latin1(b[63:93])
+	info.Year = parseYear(latin1(b[93:97]))
+	if int(b[127]) < len(id3Genres) {
+		info.Genre = id3Genres[b[127]]
+	}
+}
+
+// MPEG audio bitrates in kbit/s by version (1, 2 and 2.5) and layer.
+var (
+	mpeg1Bitrates = [3][15]int{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	}
+	mpeg2Bitrates = [3][15]int{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mpegSampleRates = map[int][3]int{
+		1: {44100, 48000, 32000},
+		2: {22050, 24000, 16000},
+		3: {11025, 12000, 8000},
+	}
+)
+
+// mpegDuration returns the duration of the MPEG audio stream starting at the
+// offset, from the frame count of a Xing or VBRI header or from the bitrate of
+// the first frame.
+func mpegDuration(r io.ReaderAt, off, size int64) time.Duration {
+	b, err := readAt(r, off, int(min(maxFrameSearch, size-off)))
+	if err != nil {
+		return 0
+	}
+	for i := 0; i+4 <= len(b); i++ {
+		if b[i] != 0xff || b[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		h := binary.BigEndian.Uint32(b[i:])
+		var version int // 1, 2 or 3 for 2.5.
+		switch h >> 19 & 3 {
+		case 3:
+			version = 1
+		case 2:
+			version = 2
+		case 0:
+			version = 3
+		default:
+			continue
+		}
+		layer := 4 - int(h>>17&3) // 1, 2 or 3.
+		bitrateIdx, rateIdx := int(h>>12&0xf), int(h>>10&3)
+		if layer == 4 || bitrateIdx == 0 || bitrateIdx == 15 || rateIdx == 3 {
+			continue
+		}
+		bitrate := mpeg1Bitrates[layer-1][bitrateIdx]
+		if version > 1 {
+			bitrate = mpeg2Bitrates[layer-1][bitrateIdx]
+		}
+		rate := mpegSampleRates[version][rateIdx]
+		samples := 1152
+		switch {
+		case layer == 1:
+			samples = 384
+		case layer == 3 && version > 1:
+			samples = 576
+		}
+
+		frame := b[i:]
+		if frames := vbrFrames(frame, version, h>>6&3 == 3); frames > 0 {
+			return samplesDuration(uint64(frames)*uint64(samples), uint64(rate))
+		}
+		audio := size - off - int64(i)
+		if t, err := readAt(r, size-128, 3); err == nil && bytes.Equal(t, []byte("TAG")) {
+			audio -= 128
+		}
+		return time.Duration(audio * 8 * int64(time.Second) / int64(bitrate*1000))
+	}
+	return 0
+}
+
+// vbrFrames returns the frame count of the Xing/Info or VBRI header of the
+// first frame, or 0 if none is found.
+func vbrFrames(frame []byte, version int, mono bool) int {
+	xing := 36
+	switch {
+	case version == 1 && mono:
+		xing = 21
+	case version > 1 && !mono:
+		xing = 21
+	case version > 1 && mono:
+		xing = 13
+	}
+	if len(frame) >= xing+12 {
+		tag := string(frame[xing : xing+4])
+		if (tag == "Xing" || tag == "Info") && frame[xing+7]&1 != 0 {
+			return int(binary.BigEndian.Uint32(frame[xing+8:]))
+		}
+	}
+	if len(frame) >= 36+18 && string(frame[36:40]) == "VBRI" {
+		return int(binary.BigEndian.Uint32(frame[36+14:]))
+	}
+	return 0
+}
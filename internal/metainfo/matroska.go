@@ -0,0 +1,210 @@
+package metainfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func init() {
+	Register(extractMatroska, "video/x-matroska", "video/webm", "audio/x-matroska", "audio/webm")
+}
+
+// Matroska element IDs.
+const (
+	mkvEBML          = 0x1a45dfa3
+	mkvSegment       = 0x18538067
+	mkvInfo          = 0x1549a966
+	mkvTimecodeScale = 0x2ad7b1
+	mkvDuration      = 0x4489
+	mkvTitle         = 0x7ba9
+	mkvDateUTC       = 0x4461
+	mkvTracks        = 0x1654ae6b
+	mkvTrackEntry    = 0xae
+	mkvVideo         = 0xe0
+	mkvPixelWidth    = 0xb0
+	mkvPixelHeight   = 0xba
+	mkvCluster       = 0x1f43b675
+	mkvTags          = 0x1254c367
+	mkvTag           = 0x7373
+	mkvSimpleTag     = 0x67c8
+	mkvTagName       = 0x45a3
+	mkvTagString     = 0x4487
+)
+
+// mkvEpoch is the origin of the Matroska dates.
+var mkvEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Size of elements of unknown size.
+const mkvUnknownSize = -1
+
+// extractMatroska extracts the title, duration, date, tags and dimensions of
+// the first video track of a Matroska or WebM file.
+func extractMatroska(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	if id, _, _, err := readElement(r, 0); err != nil || id != mkvEBML {
+		return nil, fmt.Errorf("%w: not a Matroska file", ErrFormat)
+	}
+	info := &store.MediaInfo{}
+	scale := uint64(time.Millisecond)
+	var duration float64
+	var tagName string
+
+	err := walkElements(r, 0, size, func(id uint64, off, n int64) (bool, error) {
+		switch id {
+		case mkvSegment, mkvInfo, mkvTracks, mkvTrackEntry, mkvVideo, mkvTags, mkvTag, mkvSimpleTag:
+			return true, nil
+		case mkvCluster:
+			// Clusters of unknown size can't be skipped.
+			if n == mkvUnknownSize {
+				return false, errStopWalk
+			}
+			return false, nil
+		}
+		if n < 0 || n > 1024 {
+			return false, nil
+		}
+		b, err := readAt(r, off, int(n))
+		if err != nil {
+			return false, err
+		}
+		switch id {
+		case mkvTimecodeScale:
+			scale = ebmlUint(b)
+		case mkvDuration:
+			duration = ebmlFloat(b)
+		case mkvTitle:
+			info.Title = cleanString(string(b))
+		case mkvDateUTC:
+			if len(b) == 8 {
+				date := mkvEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(b))))
+				info.Year = date.Year()
+			}
+		case mkvPixelWidth:
+			if info.Width == 0 {
+				info.Width = int(ebmlUint(b))
+			}
+		case mkvPixelHeight:
+			if info.Height == 0 {
+				info.Height = int(ebmlUint(b))
+			}
+		case mkvTagName:
+			tagName = strings.ToUpper(string(b))
+		case mkvTagString:
+			value := cleanString(string(b))
+			switch tagName {
+			case "TITLE":
+				if info.Title == "" {
+					info.Title = value
+				}
+			case "ARTIST":
+				info.Artist = value
+			case "ALBUM":
+				info.Album = value
+			case "GENRE":
+				info.Genre = value
+			case "DATE_RELEASED", "DATE_RECORDED":
+				info.Year = parseYear(value)
+			}
+		}
+		return false, nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return info, err
+	}
+	info.Duration = time.Duration(duration * float64(scale))
+	return info, nil
+}
+
+// errStopWalk stops walking the elements without an error.
+var errStopWalk = errors.New("stop walking")
+
+// walkElements calls fn with the ID, data offset and data size of the EBML
+// elements between off and end, descending into the master elements fn
+// returns true for.
+func walkElements(r io.ReaderAt, off, end int64, fn func(id uint64, off, n int64) (bool, error)) error {
+	for off < end {
+		id, hdr, n, err := readElement(r, off)
+		if err != nil {
+			return err
+		}
+		descend, err := fn(id, off+hdr, n)
+		if err != nil {
+			return err
+		}
+		childEnd := off + hdr + n
+		if n == mkvUnknownSize {
+			childEnd = end
+		}
+		if descend {
+			if err := walkElements(r, off+hdr, min(childEnd, end), fn); err != nil {
+				return err
+			}
+		}
+		if n == mkvUnknownSize {
+			return nil
+		}
+		off = childEnd
+	}
+	return nil
+}
+
+// readElement reads the ID, header size and data size of the element at the
+// offset, the data size is mkvUnknownSize for elements of unknown size.
+func readElement(r io.ReaderAt, off int64) (id uint64, hdr, n int64, err error) {
+	b, err := readAt(r, off, 1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	idLen := bits.LeadingZeros8(b[0]) + 1
+	if idLen > 4 {
+		return 0, 0, 0, fmt.Errorf("%w: bad EBML ID", ErrFormat)
+	}
+	if b, err = readAt(r, off, idLen+1); err != nil {
+		return 0, 0, 0, err
+	}
+	id = ebmlUint(b[:idLen])
+	sizeLen := bits.LeadingZeros8(b[idLen]) + 1
+	if sizeLen > 8 {
+		return 0, 0, 0, fmt.Errorf("%w: bad EBML size", ErrFormat)
+	}
+	if b, err = readAt(r, off+int64(idLen), sizeLen); err != nil {
+		return 0, 0, 0, err
+	}
+	// Strip the length marker, all ones means an unknown size.
+	b[0] &= 0xff >> sizeLen
+	size := ebmlUint(b)
+	if size == 1<<(7*sizeLen)-1 {
+		return id, int64(idLen + sizeLen), mkvUnknownSize, nil
+	}
+	if size > math.MaxInt64/2 {
+		return 0, 0, 0, fmt.Errorf("%w: bad EBML size", ErrFormat)
+	}
+	return id, int64(idLen + sizeLen), int64(size), nil
+}
+
+// ebmlUint decodes a big endian unsigned integer of up to 8 bytes.
+func ebmlUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// ebmlFloat decodes a 4 or 8 byte float.
+func ebmlFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+// Package metainfo extracts metadata from the content of images, audio,
+// e-books and videos with a registry of extractors keyed by MIME type.
+package metainfo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// Errors related to metadata extraction.
+var (
+	ErrNoExtractor = errors.New("no metadata extractor")
+	ErrFormat      = errors.New("unsupported or malformed file")
+)
+
+// Extractor extracts the metadata of a file of the given size.
+type Extractor func(r io.ReaderAt, size int64) (*store.MediaInfo, error)
+
+// registry holds the extractors by MIME type.
+var registry = struct {
+	sync.RWMutex
+	m map[string]Extractor
+}{m: make(map[string]Extractor)}
+
+// Register registers the extractor for the MIME types, replacing previously
+// registered ones.
+func Register(e Extractor, mimeTypes ...string) {
+	registry.Lock()
+	defer registry.Unlock()
+	for _, t := range mimeTypes {
+		registry.m[t] = e
+	}
+}
+
+// Lookup returns the extractor registered for the MIME type, ignoring its parameters.
+func Lookup(mimeType string) (Extractor, bool) {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	registry.RLock()
+	defer registry.RUnlock()
+	e, ok := registry.m[strings.TrimSpace(mimeType)]
+	return e, ok
+}
+
+// Extract extracts the metadata of the file with the extractor of the MIME type.
+// Malformed files are reported as errors, files without metadata as nil.
+func Extract(path string, mimeType string) (info *store.MediaInfo, err error) {
+	e, ok := Lookup(mimeType)
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoExtractor, mimeType)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Guard against parser bugs on malformed files.
+	defer func() {
+		if r := recover(); r != nil {
+			info, err = nil, fmt.Errorf("%w: %s: %v", ErrFormat, path, r)
+		}
+	}()
+	if info, err = e(f, fi.Size()); isEmpty(info) {
+		info = nil
+	}
+	return info, err
+}
+
+// readAt reads n bytes at the offset.
+func readAt(r io.ReaderAt, off int64, n int) ([]byte, error) {
+	if n < 0 || off < 0 {
+		return nil, ErrFormat
+	}
+	b := make([]byte, n)
+	if _, err := r.ReadAt(b, off); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: unexpected end of file", ErrFormat)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// parseYear returns the leading year of a date like 2006, 2006-01-02 or 2006:01:02 15:04:05.
+func parseYear(s string) int {
+	s = strings.TrimSpace(s)
+	if len(s) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(s[:4])
+	if err != nil || y <= 0 {
+		return 0
+	}
+	return y
+}
+
+// cleanString trims spaces and NUL padding of a metadata string.
+func cleanString(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return r == 0 || unicode.IsSpace(r)
+	})
+}
+
+// isEmpty reports whether no metadata was extracted.
+func isEmpty(info *store.MediaInfo) bool {
+	return info == nil || *info == store.MediaInfo{}
+}
@@ -0,0 +1,233 @@
+package metainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// tiffFile returns a TIFF structure with the camera in the first IFD and the
+// date and dimensions in its EXIF IFD.
+func tiffFile(order binary.AppendByteOrder) []byte {
+	b := []byte("II")
+	if order == binary.BigEndian {
+		b = []byte("MM")
+	}
+	b = order.AppendUint16(b, 42)
+	b = order.AppendUint32(b, 8)
+	entry := func(b []byte, tag, typ uint16, count, value uint32) []byte {
+		b = order.AppendUint16(b, tag)
+		b = order.AppendUint16(b, typ)
+		b = order.AppendUint32(b, count)
+		if typ == 3 {
+			return append(order.AppendUint16(b, uint16(value)), 0, 0)
+		}
+		return order.AppendUint32(b, value)
+	}
+
+	// IFD0 at 8, 54 bytes, followed by its strings and the EXIF IFD.
+	b = order.AppendUint16(b, 4)
+	b = entry(b, tagMake, 2, 6, 62)
+	b = entry(b, tagModel, 2, 13, 68)
+	b = entry(b, tagDateTime, 2, 20, 81)
+	b = entry(b, tagExifIFD, 4, 1, 101)
+	b = order.AppendUint32(b, 0)
+	b = append(b, "Canon\x00Canon EOS 5D\x002019:05:04 10:00:00\x00"...)
+
+	// EXIF IFD at 101, 42 bytes, followed by its string.
+	b = order.AppendUint16(b, 3)
+	b = entry(b, tagDateTimeOriginal, 2, 20, 143)
+	b = entry(b, tagPixelXDimension, 4, 1, 640)
+	b = entry(b, tagPixelYDimension, 3, 1, 480)
+	b = order.AppendUint32(b, 0)
+	return append(b, "2018:01:02 03:04:05\x00"...)
+}
+
+// jpegFile returns a JPEG file with an EXIF segment and a frame header.
+func jpegFile() []byte {
+	exif := append([]byte("Exif\x00\x00"), tiffFile(binary.BigEndian)...)
+	b := []byte{0xff, 0xd8, 0xff, 0xe1}
+	b = binary.BigEndian.AppendUint16(b, uint16(2+len(exif)))
+	b = append(b, exif...)
+	b = append(b, 0xff, 0xc0, 0x00, 0x0b, 8, 0x01, 0xe0, 0x02, 0x80, 1, 1, 0x11, 0)
+	return append(b, 0xff, 0xd9)
+}
+
+// id3Frame returns an ID3v2.3 frame.
+func id3Frame(id string, data []byte) []byte {
+	b := binary.BigEndian.AppendUint32([]byte(id), uint32(len(data)))
+	return append(append(b, 0, 0), data...)
+}
+
+// mp3File returns an ID3v2.3 tagged MP3 file of one second at 128 kbit/s.
+func mp3File() []byte {
+	var tag []byte
+	tag = append(tag, id3Frame("TIT2", []byte("\x00Title"))...)
+	tag = append(tag, id3Frame("TPE1", []byte("\x01\xff\xfeA\x00r\x00t\x00i\x00s\x00t\x00"))...)
+	tag = append(tag, id3Frame("TCON", []byte("\x00(17)"))...)
+	tag = append(tag, id3Frame("TYER", []byte("\x001999"))...)
+	n := len(tag)
+	b := append([]byte("ID3\x03\x00\x00"), byte(n>>21&0x7f), byte(n>>14&0x7f), byte(n>>7&0x7f), byte(n&0x7f))
+	b = append(b, tag...)
+	frame := make([]byte, 16000)
+	copy(frame, []byte{0xff, 0xfb, 0x90, 0x00})
+	return append(b, frame...)
+}
+
+// box returns an ISO base media box.
+func box(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	return append(binary.BigEndian.AppendUint32(nil, uint32(8+len(data))), append([]byte(typ), data...)...)
+}
+
+// mp4File returns an MP4 file with a movie header of 90 seconds, a 1280x720
+// video track and iTunes metadata.
+func mp4File() []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 90000)
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 1280<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 720<<16)
+	tag := func(typ, value string) []byte {
+		return box(typ, box("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(value)))
+	}
+	ilst := box("ilst", tag("\xa9nam", "Song"), tag("\xa9ART", "Band"), tag("\xa9day", "2020-01-01"))
+	return append(box("ftyp", []byte("isom\x00\x00\x02\x00")),
+		box("moov", box("mvhd", mvhd), box("trak", box("tkhd", tkhd)), box("udta", box("meta", make([]byte, 4), ilst)))...)
+}
+
+// ebml returns an EBML element with the data.
+func ebml(id uint32, data ...[]byte) []byte {
+	d := bytes.Join(data, nil)
+	var b []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if c := byte(id >> shift); c != 0 || len(b) > 0 {
+			b = append(b, c)
+		}
+	}
+	if len(d) < 0x7f {
+		b = append(b, 0x80|byte(len(d)))
+	} else {
+		b = append(b, 0x40|byte(len(d)>>8), byte(len(d)))
+	}
+	return append(b, d...)
+}
+
+// matroskaFile returns a Matroska file with the info, a 1920x1080 video track
+// and tags, followed by a cluster of unknown size.
+func matroskaFile() []byte {
+	date := binary.BigEndian.AppendUint64(nil, uint64(time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC).Sub(mkvEpoch)))
+	segment := bytes.Join([][]byte{
+		ebml(mkvInfo,
+			ebml(mkvTimecodeScale, []byte{0x0f, 0x42, 0x40}),
+			ebml(mkvDuration, binary.BigEndian.AppendUint32(nil, math.Float32bits(5000))),
+			ebml(mkvTitle, []byte("Movie")),
+			ebml(mkvDateUTC, date)),
+		ebml(mkvTracks, ebml(mkvTrackEntry, ebml(mkvVideo,
+			ebml(mkvPixelWidth, []byte{0x07, 0x80}),
+			ebml(mkvPixelHeight, []byte{0x04, 0x38})))),
+		ebml(mkvTags, ebml(mkvTag, ebml(mkvSimpleTag,
+			ebml(mkvTagName, []byte("artist")),
+			ebml(mkvTagString, []byte("Band"))))),
+		{0x1f, 0x43, 0xb6, 0x75, 0xff, 0xa3, 0x80},
+	}, nil)
+	return append(ebml(mkvEBML, ebml(0x4282, []byte("matroska"))), ebml(mkvSegment, segment)...)
+}
+
+// fixtures are the test files of the extractors with their metadata.
+var fixtures = []struct {
+	name    string
+	extract Extractor
+	data    []byte
+	want    store.MediaInfo
+}{
+	{"tiff le", extractTIFF, tiffFile(binary.LittleEndian), store.MediaInfo{Camera: "Canon EOS 5D", Year: 2018, Width: 640, Height: 480}},
+	{"tiff be", extractTIFF, tiffFile(binary.BigEndian), store.MediaInfo{Camera: "Canon EOS 5D", Year: 2018, Width: 640, Height: 480}},
+	{"jpeg", extractJPEG, jpegFile(), store.MediaInfo{Camera: "Canon EOS 5D", Year: 2018, Width: 640, Height: 480}},
+	{"mp3", extractMP3, mp3File(), store.MediaInfo{Title: "Title", Artist: "Artist", Genre: "Rock", Year: 1999, Duration: time.Second}},
+	{"mp4", extractMP4, mp4File(), store.MediaInfo{Title: "Song", Artist: "Band", Year: 2020, Width: 1280, Height: 720, Duration: 90 * time.Second}},
+	{"matroska", extractMatroska, matroskaFile(), store.MediaInfo{Title: "Movie", Artist: "Band", Year: 2010, Width: 1920, Height: 1080, Duration: 5 * time.Second}},
+}
+
+func TestExtractors(t *testing.T) {
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			info, err := f.extract(bytes.NewReader(f.data), int64(len(f.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *info != f.want {
+				t.Errorf("got %+v, want %+v", *info, f.want)
+			}
+		})
+	}
+}
+
+// TestMalformed checks that the extractors don't panic on truncated and
+// corrupted files. Extract recovers from panics, but the extractors are
+// called directly to catch them.
+func TestMalformed(t *testing.T) {
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			extract := func(data []byte, size int64) {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("panic on %d bytes of %q with size %d: %v", len(data), data[:min(len(data), 64)], size, r)
+					}
+				}()
+				f.extract(bytes.NewReader(data), size)
+			}
+
+			// Truncated files, also with the size of the whole file.
+			for n := range len(f.data) {
+				if n > 1024 && n%97 != 0 {
+					continue
+				}
+				extract(f.data[:n], int64(n))
+				extract(f.data[:n], int64(len(f.data)))
+			}
+
+			// Corrupted bytes of the headers.
+			data := bytes.Clone(f.data)
+			for i := range min(len(data), 1024) {
+				for _, c := range []byte{0x00, 0x01, 0x7f, 0x80, 0xff} {
+					old := data[i]
+					data[i] = c
+					extract(data, int64(len(data)))
+					data[i] = old
+				}
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.mkv")
+	if err := os.WriteFile(path, matroskaFile(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := Extract(path, "video/x-matroska; codecs=vp9")
+	if err != nil || info == nil || info.Title != "Movie" {
+		t.Errorf("Extract = %+v, %v, want the title", info, err)
+	}
+	if _, err := Extract(path, "text/plain"); err == nil {
+		t.Error("Extract without an extractor: got no error")
+	}
+
+	// Files without metadata have none.
+	empty := filepath.Join(dir, "empty.mp4")
+	if err := os.WriteFile(empty, box("ftyp", []byte("isom")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := Extract(empty, "video/mp4"); info != nil || err != nil {
+		t.Errorf("Extract of a file without metadata = %+v, %v, want nil", info, err)
+	}
+}
@@ -0,0 +1,164 @@
+package metainfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func init() {
+	Register(extractMP4, "video/mp4", "audio/mp4", "audio/x-m4a", "audio/m4a", "video/quicktime", "video/x-m4v", "video/3gpp")
+}
+
+// Maximum nesting of the boxes walked.
+const maxBoxDepth = 8
+
+// mp4Tags maps the iTunes metadata item boxes to the metadata fields.
+var mp4Tags = map[string]string{
+	"\xa9nam": "title",
+	"\xa9ART": "artist",
+	"\xa9alb": "album",
+	"\xa9gen": "genre",
+	"\xa9day": "year",
+}
+
+// extractMP4 extracts the duration of the movie header, the dimensions of the
+// first video track and the iTunes metadata of an ISO base media file.
+func extractMP4(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	h, err := readAt(r, 4, 4)
+	if err != nil || string(h) != "ftyp" && string(h) != "moov" && string(h) != "mdat" && string(h) != "wide" {
+		return nil, fmt.Errorf("%w: not an MP4 file", ErrFormat)
+	}
+	info := &store.MediaInfo{}
+	err = walkBoxes(r, 0, size, 0, func(typ string, off, n int64) (bool, error) {
+		switch typ {
+		case "moov", "trak", "mdia", "udta", "meta", "ilst":
+			return true, nil
+		case "mvhd":
+			return false, readMvhd(r, off, n, info)
+		case "tkhd":
+			return false, readTkhd(r, off, n, info)
+		}
+		if field, ok := mp4Tags[typ]; ok {
+			readMP4Tag(r, off, n, field, info)
+		}
+		return false, nil
+	})
+	return info, err
+}
+
+// walkBoxes calls fn with the type, payload offset and payload size of the
+// boxes between off and end, descending into the boxes fn returns true for.
+func walkBoxes(r io.ReaderAt, off, end int64, depth int, fn func(typ string, off, n int64) (bool, error)) error {
+	if depth > maxBoxDepth {
+		return nil
+	}
+	for off+8 <= end {
+		h, err := readAt(r, off, 8)
+		if err != nil {
+			return err
+		}
+		size, typ, hdr := int64(binary.BigEndian.Uint32(h)), string(h[4:]), int64(8)
+		switch size {
+		case 0:
+			// Box extends to the end.
+			size = end - off
+		case 1:
+			ext, err := readAt(r, off+8, 8)
+			if err != nil {
+				return err
+			}
+			size, hdr = int64(binary.BigEndian.Uint64(ext)), 16
+		}
+		if size < hdr || off+size > end {
+			return fmt.Errorf("%w: bad box %q", ErrFormat, typ)
+		}
+		descend, err := fn(typ, off+hdr, size-hdr)
+		if err != nil {
+			return err
+		}
+		if descend {
+			start := off + hdr
+			if typ == "meta" {
+				// A full box with version and flags in MP4, a plain box in QuickTime.
+				if b, err := readAt(r, start+4, 4); err == nil && string(b) != "hdlr" {
+					start += 4
+				}
+			}
+			if err := walkBoxes(r, start, off+size, depth+1, fn); err != nil {
+				return err
+			}
+		}
+		off += size
+	}
+	return nil
+}
+
+// readMvhd reads the duration of the movie header box.
+func readMvhd(r io.ReaderAt, off, n int64, info *store.MediaInfo) error {
+	b, err := readAt(r, off, int(min(n, 32)))
+	if err != nil {
+		return err
+	}
+	var scale, duration uint64
+	switch {
+	case len(b) >= 32 && b[0] == 1:
+		scale, duration = uint64(binary.BigEndian.Uint32(b[20:])), binary.BigEndian.Uint64(b[24:])
+	case len(b) >= 20:
+		scale, duration = uint64(binary.BigEndian.Uint32(b[12:])), uint64(binary.BigEndian.Uint32(b[16:]))
+	}
+	if scale > 0 {
+		info.Duration = time.Duration(float64(duration) / float64(scale) * float64(time.Second))
+	}
+	return nil
+}
+
+// readTkhd reads the dimensions of the first track header box having them,
+// audio tracks have none.
+func readTkhd(r io.ReaderAt, off, n int64, info *store.MediaInfo) error {
+	if info.Width != 0 {
+		return nil
+	}
+	b, err := readAt(r, off, int(min(n, 96)))
+	if err != nil {
+		return err
+	}
+	// Width and height are 16.16 fixed point numbers at the end of the box.
+	at := 76
+	if len(b) > 0 && b[0] == 1 {
+		at = 88
+	}
+	if len(b) >= at+8 {
+		info.Width = int(binary.BigEndian.Uint32(b[at:]) >> 16)
+		info.Height = int(binary.BigEndian.Uint32(b[at+4:]) >> 16)
+	}
+	return nil
+}
+
+// readMP4Tag reads the text of the data box of an iTunes metadata item.
+func readMP4Tag(r io.ReaderAt, off, n int64, field string, info *store.MediaInfo) {
+	b, err := readAt(r, off, int(min(n, 4096)))
+	if err != nil || len(b) < 16 || string(b[4:8]) != "data" {
+		return
+	}
+	size := min(int(binary.BigEndian.Uint32(b)), len(b))
+	if size < 16 {
+		return
+	}
+	value := cleanString(string(b[16:size]))
+	switch field {
+	case "title":
+		info.Title = value
+	case "artist":
+		info.Artist = value
+	case "album":
+		info.Album = value
+	case "genre":
+		info.Genre = value
+	case "year":
+		info.Year = parseYear(value)
+	}
+}
@@ -0,0 +1,205 @@
+package metainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func init() {
+	Register(extractFLAC, "audio/flac", "audio/x-flac")
+	Register(extractOgg, "audio/ogg", "audio/x-vorbis+ogg", "audio/x-opus+ogg", "audio/opus", "audio/vorbis")
+}
+
+// Limits of the Ogg pages read.
+const (
+	maxOggHeaderPages = 64
+	oggTailSize       = 64 << 10
+)
+
+// extractFLAC extracts the STREAMINFO duration and Vorbis comments of a FLAC file.
+func extractFLAC(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	magic, err := readAt(r, 0, 4)
+	if err != nil || !bytes.Equal(magic, []byte("fLaC")) {
+		return nil, fmt.Errorf("%w: not a FLAC file", ErrFormat)
+	}
+	info := &store.MediaInfo{}
+	for off := int64(4); off+4 <= size; {
+		h, err := readAt(r, off, 4)
+		if err != nil {
+			return info, err
+		}
+		last, typ := h[0]&0x80 != 0, h[0]&0x7f
+		n := int(h[1])<<16 | int(h[2])<<8 | int(h[3])
+		switch typ {
+		case 0: // STREAMINFO
+			b, err := readAt(r, off+4, 18)
+			if err != nil {
+				return info, err
+			}
+			v := binary.BigEndian.Uint64(b[10:])
+			rate, samples := v>>44, v&(1<<36-1)
+			if rate > 0 {
+				info.Duration = samplesDuration(samples, rate)
+			}
+		case 4: // VORBIS_COMMENT
+			b, err := readAt(r, off+4, n)
+			if err != nil {
+				return info, err
+			}
+			readVorbisComments(b, info)
+		}
+		if last {
+			break
+		}
+		off += 4 + int64(n)
+	}
+	return info, nil
+}
+
+// readVorbisComments reads the little endian vendor string and KEY=value
+// comments of a Vorbis comment block.
+func readVorbisComments(b []byte, info *store.MediaInfo) {
+	next := func() (string, bool) {
+		if len(b) < 4 {
+			return "", false
+		}
+		n := binary.LittleEndian.Uint32(b)
+		if uint64(n) > uint64(len(b)-4) {
+			return "", false
+		}
+		s := string(b[4 : 4+n])
+		b = b[4+n:]
+		return s, true
+	}
+	if _, ok := next(); !ok {
+		return
+	}
+	if len(b) < 4 {
+		return
+	}
+	count := binary.LittleEndian.Uint32(b)
+	b = b[4:]
+	for range count {
+		c, ok := next()
+		if !ok {
+			return
+		}
+		key, value, _ := strings.Cut(c, "=")
+		value = cleanString(value)
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			info.Title = value
+		case "ARTIST":
+			info.Artist = value
+		case "ALBUM":
+			info.Album = value
+		case "GENRE":
+			info.Genre = value
+		case "DATE", "YEAR":
+			info.Year = parseYear(value)
+		}
+	}
+}
+
+// extractOgg extracts the comments of the first Vorbis or Opus stream of an
+// Ogg file and its duration from the granule position of the last page.
+func extractOgg(r io.ReaderAt, size int64) (*store.MediaInfo, error) {
+	info := &store.MediaInfo{}
+	var packets [][]byte
+	var packet []byte
+	var serial uint32
+	off := int64(0)
+	for page := 0; page < maxOggHeaderPages && len(packets) < 2; page++ {
+		h, err := readAt(r, off, 27)
+		if err != nil || !bytes.Equal(h[:4], []byte("OggS")) {
+			return nil, fmt.Errorf("%w: bad Ogg page", ErrFormat)
+		}
+		segments, err := readAt(r, off+27, int(h[26]))
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		for _, s := range segments {
+			n += int(s)
+		}
+		data, err := readAt(r, off+27+int64(len(segments)), n)
+		if err != nil {
+			return nil, err
+		}
+		off += 27 + int64(len(segments)) + int64(n)
+
+		if page == 0 {
+			serial = binary.LittleEndian.Uint32(h[14:])
+		} else if binary.LittleEndian.Uint32(h[14:]) != serial {
+			// Page of another multiplexed stream.
+			continue
+		}
+		// Packets end at segments shorter than 255 bytes.
+		for _, s := range segments {
+			packet = append(packet, data[:s]...)
+			data = data[s:]
+			if s < 255 {
+				packets = append(packets, packet)
+				packet = nil
+			}
+		}
+	}
+	if len(packets) < 2 {
+		return nil, fmt.Errorf("%w: missing Ogg headers", ErrFormat)
+	}
+
+	var rate, skip uint64
+	ident, comments := packets[0], packets[1]
+	switch {
+	case bytes.HasPrefix(ident, []byte("\x01vorbis")) && len(ident) >= 16:
+		rate = uint64(binary.LittleEndian.Uint32(ident[12:]))
+		if c, ok := bytes.CutPrefix(comments, []byte("\x03vorbis")); ok {
+			readVorbisComments(c, info)
+		}
+	case bytes.HasPrefix(ident, []byte("OpusHead")) && len(ident) >= 12:
+		// Opus granule positions always count 48 kHz samples.
+		rate, skip = 48000, uint64(binary.LittleEndian.Uint16(ident[10:]))
+		if c, ok := bytes.CutPrefix(comments, []byte("OpusTags")); ok {
+			readVorbisComments(c, info)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown Ogg codec", ErrFormat)
+	}
+
+	if granule := lastGranule(r, size, serial); rate > 0 && granule > skip {
+		info.Duration = samplesDuration(granule-skip, rate)
+	}
+	return info, nil
+}
+
+// lastGranule returns the granule position of the last page of the stream.
+func lastGranule(r io.ReaderAt, size int64, serial uint32) uint64 {
+	start := max(0, size-oggTailSize)
+	tail, err := readAt(r, start, int(size-start))
+	if err != nil {
+		return 0
+	}
+	for i := len(tail) - 27; i >= 0; i-- {
+		if i = bytes.LastIndex(tail[:i+4], []byte("OggS")); i < 0 {
+			return 0
+		}
+		h := tail[i:]
+		if binary.LittleEndian.Uint32(h[14:]) == serial {
+			if g := binary.LittleEndian.Uint64(h[6:]); g != ^uint64(0) {
+				return g
+			}
+		}
+	}
+	return 0
+}
+
+// samplesDuration returns the duration of the samples at the sample rate.
+func samplesDuration(samples, rate uint64) time.Duration {
+	return time.Duration(float64(samples) / float64(rate) * float64(time.Second))
+}
@@ -0,0 +1,327 @@
+// Package mimeinfo implements MIME type detection with the freedesktop.org
+// shared-mime-info database: glob patterns with weights, magic byte rules,
+// aliases and subclasses.
+package mimeinfo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Errors related to database operations.
+var (
+	ErrNoPackages = errors.New("no shared-mime-info packages found")
+)
+
+// Well known MIME types used when no rule matches.
+const (
+	OctetStream = "application/octet-stream"
+	PlainText   = "text/plain"
+	ZeroSize    = "application/x-zerosize"
+)
+
+// DefaultDirs returns the shared-mime-info package directories of the XDG base
+// directories, in order of increasing precedence.
+func DefaultDirs() (dirs []string) {
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range slices.Backward(filepath.SplitList(dataDirs)) {
+		dirs = append(dirs, filepath.Join(dir, "mime", "packages"))
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		dirs = append(dirs, filepath.Join(dataHome, "mime", "packages"))
+	}
+	return
+}
+
+// Database represents a loaded shared-mime-info database.
+type Database struct {
+	aliases map[string]string   // Canonical types by alias.
+	parents map[string][]string // Parent types by type.
+	globs   globs               // File name patterns.
+	magic   []magic             // Magic rules sorted by decreasing priority.
+	magicOf map[string]bool     // Types having magic rules.
+	extent  int                 // Number of bytes needed by the magic rules.
+}
+
+// Load loads the XML package files of the directories, later directories
+// override earlier ones.
+func Load(dirs ...string) (*Database, error) {
+	db := &Database{
+		aliases: make(map[string]string),
+		parents: make(map[string][]string),
+		globs:   newGlobs(),
+		magicOf: make(map[string]bool),
+	}
+
+	types := make(map[string]*xmlType)
+	var found bool
+	for _, dir := range dirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.xml"))
+		for _, name := range files {
+			if err := readPackage(name, types); err != nil {
+				return nil, err
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w in %s", ErrNoPackages, strings.Join(dirs, ", "))
+	}
+
+	for name, t := range types {
+		for _, a := range t.Aliases {
+			db.aliases[a.Type] = name
+		}
+		for _, p := range t.SubClassOf {
+			db.parents[name] = append(db.parents[name], p.Type)
+		}
+		for _, g := range t.Globs {
+			db.globs.add(name, g.Pattern, g.Weight, g.CaseSensitive)
+		}
+		for _, m := range t.Magic {
+			mg, err := compileMagic(name, m)
+			if err != nil {
+				return nil, err
+			}
+			db.magic = append(db.magic, mg)
+			db.magicOf[name] = true
+			db.extent = max(db.extent, mg.extent())
+		}
+	}
+	db.extent = min(db.extent, maxExtent)
+
+	slices.SortStableFunc(db.magic, func(a, b magic) int {
+		if a.priority != b.priority {
+			return b.priority - a.priority
+		}
+		return strings.Compare(a.typ, b.typ)
+	})
+	return db, nil
+}
+
+// readPackage reads the mime types of the XML package file into types, merging
+// the definitions of types already read.
+func readPackage(name string, types map[string]*xmlType) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var info xmlInfo
+	if err := xml.NewDecoder(f).Decode(&info); err != nil {
+		return fmt.Errorf("can't parse %s: %w", name, err)
+	}
+	for _, t := range info.Types {
+		old, ok := types[t.Type]
+		if !ok {
+			types[t.Type] = &t
+			continue
+		}
+		if t.GlobDeleteAll != nil {
+			old.Globs = nil
+		}
+		if t.MagicDeleteAll != nil {
+			old.Magic = nil
+		}
+		old.Aliases = append(old.Aliases, t.Aliases...)
+		old.SubClassOf = append(old.SubClassOf, t.SubClassOf...)
+		old.Globs = append(old.Globs, t.Globs...)
+		old.Magic = append(old.Magic, t.Magic...)
+	}
+	return nil
+}
+
+// Unalias returns the canonical name of the type.
+func (db *Database) Unalias(t string) string {
+	if c, ok := db.aliases[t]; ok {
+		return c
+	}
+	return t
+}
+
+// Parents returns the direct parent types of the type. Text types are
+// subclasses of text/plain and all types except inode ones are subclasses of
+// application/octet-stream.
+func (db *Database) Parents(t string) []string {
+	t = db.Unalias(t)
+	parents := slices.Clone(db.parents[t])
+	if strings.HasPrefix(t, "text/") && t != PlainText && !slices.Contains(parents, PlainText) {
+		parents = append(parents, PlainText)
+	}
+	if t != OctetStream && !strings.HasPrefix(t, "inode/") && len(parents) == 0 {
+		parents = append(parents, OctetStream)
+	}
+	return parents
+}
+
+// IsSubclass reports whether the type equals or is a subclass of the parent type.
+func (db *Database) IsSubclass(t, parent string) bool {
+	t, parent = db.Unalias(t), db.Unalias(parent)
+	seen := make(map[string]bool)
+	var walk func(string) bool
+	walk = func(t string) bool {
+		if t == parent {
+			return true
+		}
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for _, p := range db.Parents(t) {
+			if walk(p) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(t)
+}
+
+// TypeByName returns the types matching the file name with the highest glob weight.
+func (db *Database) TypeByName(name string) []string {
+	return db.globs.match(filepath.Base(name))
+}
+
+// TypeByContent returns the type of the highest priority magic rule matching
+// the data and the rule priority, or an empty type if no rule matches.
+func (db *Database) TypeByContent(data []byte) (string, int) {
+	for _, m := range db.magic {
+		if m.match(data) {
+			return m.typ, m.priority
+		}
+	}
+	return "", 0
+}
+
+// Detect returns the type of the file with the name and content. The file
+// name globs are used first and magic rules resolve ambiguous names. Names
+// are overridden for mislabeled files: by high priority magic rules, by
+// magic rules of binary files named as text, and by the text heuristic for
+// text files named as a type whose magic rules don't match.
+func (db *Database) Detect(name string, data []byte) string {
+	byName := db.TypeByName(name)
+	byContent, priority := db.TypeByContent(data)
+
+	switch {
+	case len(byName) == 1:
+		t := byName[0]
+		text := isText(data)
+		switch {
+		case byContent != "" && (db.IsSubclass(t, byContent) || db.IsSubclass(byContent, t)):
+			return db.Unalias(t)
+		case byContent != "" && (priority >= highPriority || !text && db.IsSubclass(t, PlainText)):
+			return db.Unalias(byContent)
+		case byContent == "" && len(data) > 0 && text && db.magicOf[db.Unalias(t)] && !db.IsSubclass(t, PlainText):
+			return PlainText
+		}
+		return db.Unalias(t)
+	case len(byName) > 1:
+		// Use the content to choose between the names.
+		for _, t := range byName {
+			if byContent != "" && db.IsSubclass(byContent, t) {
+				return db.Unalias(byContent)
+			}
+		}
+		if byContent != "" && priority >= highPriority {
+			return db.Unalias(byContent)
+		}
+		return db.Unalias(byName[0])
+	case byContent != "":
+		return db.Unalias(byContent)
+	case len(data) == 0:
+		return ZeroSize
+	case isText(data):
+		return PlainText
+	}
+	return OctetStream
+}
+
+// DetectFile returns the type of the file, reading the head of its content.
+func (db *Database) DetectFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data := make([]byte, max(db.extent, textExtent))
+	n, err := io.ReadFull(f, data)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return db.Detect(name, data[:n]), nil
+}
+
+// isText reports whether the head of a file looks like text: valid UTF-8,
+// except for a rune cut at the end, without control characters.
+func isText(data []byte) bool {
+	data = data[:min(len(data), textExtent)]
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			// A rune truncated by the read limit.
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		if r < ' ' && r != '\n' && r != '\r' && r != '\t' && r != '\f' && r != '\b' && r != 0x1b {
+			return false
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// xmlInfo is the XML form of a shared-mime-info package.
+type xmlInfo struct {
+	Types []xmlType `xml:"mime-type"`
+}
+
+// xmlType is the XML form of a mime type definition.
+type xmlType struct {
+	Type    string `xml:"type,attr"`
+	Aliases []struct {
+		Type string `xml:"type,attr"`
+	} `xml:"alias"`
+	SubClassOf []struct {
+		Type string `xml:"type,attr"`
+	} `xml:"sub-class-of"`
+	Globs []struct {
+		Pattern       string `xml:"pattern,attr"`
+		Weight        int    `xml:"weight,attr"`
+		CaseSensitive bool   `xml:"case-sensitive,attr"`
+	} `xml:"glob"`
+	Magic          []xmlMagic `xml:"magic"`
+	GlobDeleteAll  *struct{}  `xml:"glob-deleteall"`
+	MagicDeleteAll *struct{}  `xml:"magic-deleteall"`
+}
+
+// xmlMagic is the XML form of a magic rule set.
+type xmlMagic struct {
+	Priority int        `xml:"priority,attr"`
+	Matches  []xmlMatch `xml:"match"`
+}
+
+// xmlMatch is the XML form of a magic match with its nested matches.
+type xmlMatch struct {
+	Type    string     `xml:"type,attr"`
+	Offset  string     `xml:"offset,attr"`
+	Value   string     `xml:"value,attr"`
+	Mask    string     `xml:"mask,attr"`
+	Matches []xmlMatch `xml:"match"`
+}
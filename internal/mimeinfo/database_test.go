@@ -0,0 +1,281 @@
+package mimeinfo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testPackage is a small shared-mime-info package of the tests.
+const testPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+  <mime-type type="image/png">
+    <glob pattern="*.png"/>
+    <magic priority="50">
+      <match type="string" value="\x89PNG" offset="0"/>
+    </magic>
+  </mime-type>
+  <mime-type type="application/pdf">
+    <alias type="application/x-pdf"/>
+    <glob pattern="*.pdf"/>
+    <magic priority="50">
+      <match type="string" value="%PDF-" offset="0:1024"/>
+    </magic>
+  </mime-type>
+  <mime-type type="application/gzip">
+    <glob pattern="*.gz"/>
+    <magic priority="20">
+      <match type="big16" value="0x1f8b" offset="0"/>
+    </magic>
+  </mime-type>
+  <mime-type type="application/x-compressed-tar">
+    <sub-class-of type="application/gzip"/>
+    <glob pattern="*.tar.gz"/>
+  </mime-type>
+  <mime-type type="application/x-executable">
+    <magic priority="40">
+      <match type="string" value="\177ELF" offset="0">
+        <match type="byte" value="2" offset="16"/>
+      </match>
+    </magic>
+  </mime-type>
+  <mime-type type="application/x-sharedlib">
+    <magic priority="50">
+      <match type="string" value="\177ELF" offset="0">
+        <match type="byte" value="3" offset="16"/>
+      </match>
+    </magic>
+  </mime-type>
+  <mime-type type="text/x-csrc">
+    <glob pattern="*.c" case-sensitive="true"/>
+  </mime-type>
+  <mime-type type="text/x-c++src">
+    <glob pattern="*.C" case-sensitive="true"/>
+  </mime-type>
+  <mime-type type="text/x-makefile">
+    <glob pattern="Makefile" weight="60"/>
+    <glob pattern="makefile.*" weight="40"/>
+  </mime-type>
+  <mime-type type="application/x-ms-dos-executable">
+    <glob pattern="*.exe"/>
+    <magic priority="90">
+      <match type="string" value="MZ" offset="0"/>
+    </magic>
+  </mime-type>
+  <mime-type type="text/x-log">
+    <glob pattern="*.log"/>
+  </mime-type>
+</mime-info>
+`
+
+// loadTestDB loads the test package and the override packages, each in its
+// own directory.
+func loadTestDB(t *testing.T, overrides ...string) *Database {
+	t.Helper()
+	var dirs []string
+	for _, p := range append([]string{testPackage}, overrides...) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "test.xml"), []byte(p), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+	}
+	db, err := Load(dirs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// elf returns the head of an ELF file of the object file type.
+func elf(typ byte) []byte {
+	data := make([]byte, 20)
+	copy(data, "\x7fELF")
+	data[16] = typ
+	return data
+}
+
+func TestDetect(t *testing.T) {
+	db := loadTestDB(t)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00")
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		// Names and content agree, or only one of them is known.
+		{"a.png", png, "image/png"},
+		{"a", png, "image/png"},
+		{"a.png", nil, "image/png"},
+		{"a.PNG", nil, "image/png"},
+		{"doc.pdf", []byte("junk\n%PDF-1.7"), "application/pdf"},
+
+		// Longest globs, subclasses and case-sensitive globs.
+		{"a.tar.gz", []byte("\x1f\x8b\x08"), "application/x-compressed-tar"},
+		{"a.gz", []byte("\x1f\x8b\x08"), "application/gzip"},
+		{"x.c", []byte("int x;\n"), "text/x-csrc"},
+		{"x.C", []byte("int x;\n"), "text/x-c++src"},
+		{"Makefile", []byte("all:\n"), "text/x-makefile"},
+		{"makefile.am", []byte("all:\n"), "text/x-makefile"},
+
+		// Nested magic matches.
+		{"a.out", elf(2), "application/x-executable"},
+		{"lib", elf(3), "application/x-sharedlib"},
+		{"core", elf(4), OctetStream},
+
+		// Mislabeled files: high priority magic, binary files named as text
+		// and text named as a type with magic rules.
+		{"a.png", []byte("MZ\x90\x00"), "application/x-ms-dos-executable"},
+		{"a.log", png, "image/png"},
+		{"a.png", []byte("not an image\n"), PlainText},
+		{"a.log", []byte("started\n"), "text/x-log"},
+
+		// Fallbacks.
+		{"empty", nil, ZeroSize},
+		{"notes", []byte("héllo\n"), PlainText},
+		{"cut", []byte("h\xc3"), PlainText},
+		{"blob", []byte{0, 1, 2, 3}, OctetStream},
+		{"bad", []byte("\xff\xfe"), OctetStream},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.Detect(tt.name, tt.data); got != tt.want {
+				t.Errorf("Detect(%q, %q) = %s, want %s", tt.name, tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFile(t *testing.T) {
+	db := loadTestDB(t)
+	dir := t.TempDir()
+	name := filepath.Join(dir, "image")
+	if err := os.WriteFile(name, append([]byte("\x89PNG"), bytes.Repeat([]byte{0}, 100<<10)...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.DetectFile(name); err != nil || got != "image/png" {
+		t.Errorf("DetectFile = %s, %v, want image/png", got, err)
+	}
+	if _, err := db.DetectFile(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DetectFile of a missing file: got error %v, want not exist", err)
+	}
+}
+
+func TestSubclass(t *testing.T) {
+	db := loadTestDB(t)
+	tests := []struct {
+		t, parent string
+		want      bool
+	}{
+		{"application/x-compressed-tar", "application/gzip", true},
+		{"application/x-compressed-tar", OctetStream, true},
+		{"application/gzip", "application/x-compressed-tar", false},
+		{"text/x-csrc", PlainText, true},
+		{"application/x-pdf", "application/pdf", true},
+		{"inode/directory", OctetStream, false},
+	}
+	for _, tt := range tests {
+		if got := db.IsSubclass(tt.t, tt.parent); got != tt.want {
+			t.Errorf("IsSubclass(%s, %s) = %v, want %v", tt.t, tt.parent, got, tt.want)
+		}
+	}
+	if got := db.Unalias("application/x-pdf"); got != "application/pdf" {
+		t.Errorf("Unalias = %s, want application/pdf", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load(t.TempDir()); !errors.Is(err, ErrNoPackages) {
+		t.Errorf("Load of an empty directory: got error %v, want %v", err, ErrNoPackages)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.xml"), []byte("<mime-info><mime-type"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Load of a malformed package: got no error")
+	}
+
+	// Later packages add to and delete the rules of earlier ones.
+	db := loadTestDB(t, `<mime-info>
+  <mime-type type="image/png">
+    <glob-deleteall/>
+    <glob pattern="*.pnx"/>
+  </mime-type>
+  <mime-type type="text/x-log">
+    <magic><match type="string" value="LOG:" offset="0"/></magic>
+  </mime-type>
+</mime-info>`)
+	if got := db.TypeByName("a.png"); len(got) != 0 {
+		t.Errorf("deleted glob matches %q", got)
+	}
+	if got := db.TypeByName("a.pnx"); !slices.Equal(got, []string{"image/png"}) {
+		t.Errorf("added glob matches %q, want image/png", got)
+	}
+	if got, _ := db.TypeByContent([]byte("LOG: x")); got != "text/x-log" {
+		t.Errorf("added magic matches %q, want text/x-log", got)
+	}
+}
+
+func TestCompileMatch(t *testing.T) {
+	tests := []struct {
+		m          xmlMatch
+		start, end int
+		value      string
+		mask       string
+		err        bool
+	}{
+		{xmlMatch{Type: "string", Value: `a\tb\x41\101\\`, Offset: "2"}, 2, 2, "a\tbAA\\", "", false},
+		{xmlMatch{Type: "string", Value: `\xZ\`, Offset: "0:8"}, 0, 8, "xZ\\", "", false},
+		{xmlMatch{Type: "string", Value: "ab", Mask: "0xff00", Offset: "0"}, 0, 0, "ab", "\xff\x00", false},
+		{xmlMatch{Type: "big16", Value: "0x1f8b", Offset: "0"}, 0, 0, "\x1f\x8b", "", false},
+		{xmlMatch{Type: "little32", Value: "1", Mask: "0xff", Offset: "4"}, 4, 4, "\x01\x00\x00\x00", "\xff\x00\x00\x00", false},
+		{xmlMatch{Type: "byte", Value: "010", Offset: "0"}, 0, 0, "\x08", "", false},
+		{xmlMatch{Type: "string", Value: "a", Offset: "x"}, 0, 0, "", "", true},
+		{xmlMatch{Type: "string", Value: "a", Offset: "0:y"}, 0, 0, "", "", true},
+		{xmlMatch{Type: "string", Value: "ab", Mask: "0xff", Offset: "0"}, 0, 0, "", "", true},
+		{xmlMatch{Type: "byte", Value: "zz", Offset: "0"}, 0, 0, "", "", true},
+		{xmlMatch{Type: "regex", Value: "a", Offset: "0"}, 0, 0, "", "", true},
+	}
+	for _, tt := range tests {
+		m, err := compileMatch(tt.m)
+		if tt.err {
+			if err == nil {
+				t.Errorf("compileMatch(%+v): got no error", tt.m)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("compileMatch(%+v): %v", tt.m, err)
+			continue
+		}
+		if m.start != tt.start || m.end != tt.end || string(m.value) != tt.value || string(m.mask) != tt.mask {
+			t.Errorf("compileMatch(%+v) = %d:%d %q mask %q, want %d:%d %q mask %q",
+				tt.m, m.start, m.end, m.value, m.mask, tt.start, tt.end, tt.value, tt.mask)
+		}
+	}
+}
+
+func TestMatchValue(t *testing.T) {
+	m := match{start: 1, end: 3, value: []byte("ab"), mask: []byte{0xdf, 0xff}}
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"xab", true},
+		{"xxxAb", true},
+		{"xxxaB", false},
+		{"ab", false},
+		{"xxxxab", false},
+		{"xxxa", false},
+	}
+	for _, tt := range tests {
+		if got := m.matchValue([]byte(tt.data)); got != tt.want {
+			t.Errorf("matchValue(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package mimeinfo
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// defaultGlobWeight is the weight of globs without an explicit weight.
+const defaultGlobWeight = 50
+
+// glob represents a file name pattern of a type.
+type glob struct {
+	typ           string
+	pattern       string
+	weight        int
+	caseSensitive bool
+}
+
+// globs indexes the file name patterns: literal names and simple "*.ext"
+// suffixes are looked up in maps, other patterns are matched one by one.
+type globs struct {
+	literals map[string][]glob // Patterns without wildcards by name.
+	suffixes map[string][]glob // Patterns "*<suffix>" by suffix, e.g. ".tar.gz".
+	patterns []glob            // Other patterns.
+}
+
+// newGlobs creates an empty glob index.
+func newGlobs() globs {
+	return globs{literals: make(map[string][]glob), suffixes: make(map[string][]glob)}
+}
+
+// add adds the pattern of the type to the index.
+func (gs *globs) add(typ, pattern string, weight int, caseSensitive bool) {
+	if weight == 0 {
+		weight = defaultGlobWeight
+	}
+	g := glob{typ: typ, pattern: pattern, weight: weight, caseSensitive: caseSensitive}
+	key := pattern
+	if !caseSensitive {
+		key = strings.ToLower(key)
+	}
+
+	switch suffix, ok := strings.CutPrefix(key, "*"); {
+	case !strings.ContainsAny(key, "*?["):
+		gs.literals[key] = append(gs.literals[key], g)
+	case ok && strings.HasPrefix(suffix, ".") && !strings.ContainsAny(suffix, "*?["):
+		gs.suffixes[suffix] = append(gs.suffixes[suffix], g)
+	default:
+		gs.patterns = append(gs.patterns, g)
+	}
+}
+
+// match returns the distinct types of the longest patterns matching the file
+// name with the highest weight.
+func (gs *globs) match(name string) []string {
+	lower := strings.ToLower(name)
+	var best []glob
+
+	consider := func(g glob) {
+		switch {
+		case len(best) == 0 || g.weight > best[0].weight:
+			best = append(best[:0], g)
+		case g.weight == best[0].weight:
+			best = append(best, g)
+		}
+	}
+	// Case sensitive patterns are indexed as is, others in lower case.
+	lookup := func(m map[string][]glob, key, lowerKey string) {
+		for _, g := range m[key] {
+			if g.caseSensitive {
+				consider(g)
+			}
+		}
+		for _, g := range m[lowerKey] {
+			if !g.caseSensitive {
+				consider(g)
+			}
+		}
+	}
+
+	lookup(gs.literals, name, lower)
+	// Suffixes starting at each dot of the name.
+	for i := 0; i < len(name); i++ {
+		if name[i] == '.' {
+			lookup(gs.suffixes, name[i:], strings.ToLower(name[i:]))
+		}
+	}
+	for _, g := range gs.patterns {
+		p, n := g.pattern, name
+		if !g.caseSensitive {
+			p, n = strings.ToLower(p), lower
+		}
+		if ok, _ := path.Match(p, n); ok {
+			consider(g)
+		}
+	}
+
+	// The longest patterns of equal weight win, e.g. *.tar.gz over *.gz.
+	longest := 0
+	for _, g := range best {
+		longest = max(longest, len(g.pattern))
+	}
+	var types []string
+	for _, g := range best {
+		if len(g.pattern) == longest && !slices.Contains(types, g.typ) {
+			types = append(types, g.typ)
+		}
+	}
+	return types
+}
@@ -0,0 +1,234 @@
+package mimeinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultMagicPriority is the priority of magic rules without an explicit priority.
+	defaultMagicPriority = 50
+
+	// highPriority is the magic priority from which content overrides file names.
+	highPriority = 80
+
+	// maxExtent limits the number of bytes read for the magic rules.
+	maxExtent = 64 << 10
+
+	// textExtent is the number of bytes checked by the text heuristic.
+	textExtent = 512
+)
+
+// magic represents the magic rules of a type, any of its matches must match.
+type magic struct {
+	typ      string
+	priority int
+	matches  []match
+}
+
+// match represents a magic match: the value is compared at each offset of the
+// range, and if any nested matches exist one of them must match too.
+type match struct {
+	start, end int    // Offset range of the value start.
+	value      []byte // Value to compare.
+	mask       []byte // Mask applied to the data before comparing, nil for none.
+	children   []match
+}
+
+// compileMagic compiles the XML magic rules of the type.
+func compileMagic(typ string, m xmlMagic) (magic, error) {
+	mg := magic{typ: typ, priority: m.Priority}
+	if mg.priority == 0 {
+		mg.priority = defaultMagicPriority
+	}
+	var err error
+	mg.matches, err = compileMatches(m.Matches)
+	if err != nil {
+		return mg, fmt.Errorf("bad magic of %s: %w", typ, err)
+	}
+	return mg, nil
+}
+
+// compileMatches compiles the XML matches and their nested matches.
+func compileMatches(xms []xmlMatch) ([]match, error) {
+	var ms []match
+	for _, xm := range xms {
+		m, err := compileMatch(xm)
+		if err != nil {
+			return nil, err
+		}
+		if m.children, err = compileMatches(xm.Matches); err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// compileMatch compiles the value, mask and offset of the XML match.
+func compileMatch(xm xmlMatch) (m match, err error) {
+	from, to, ok := strings.Cut(xm.Offset, ":")
+	if m.start, err = strconv.Atoi(from); err != nil {
+		return m, fmt.Errorf("bad offset %q", xm.Offset)
+	}
+	m.end = m.start
+	if ok {
+		if m.end, err = strconv.Atoi(to); err != nil {
+			return m, fmt.Errorf("bad offset %q", xm.Offset)
+		}
+	}
+
+	switch xm.Type {
+	case "string":
+		m.value = unescape(xm.Value)
+		if xm.Mask != "" {
+			if m.mask, err = hex.DecodeString(strings.TrimPrefix(xm.Mask, "0x")); err != nil {
+				return m, fmt.Errorf("bad mask %q", xm.Mask)
+			}
+		}
+	case "byte", "host16", "host32", "big16", "big32", "little16", "little32":
+		if m.value, err = encodeNumber(xm.Type, xm.Value); err != nil {
+			return m, err
+		}
+		if xm.Mask != "" {
+			if m.mask, err = encodeNumber(xm.Type, xm.Mask); err != nil {
+				return m, err
+			}
+		}
+	default:
+		return m, fmt.Errorf("unknown match type %q", xm.Type)
+	}
+	if m.mask != nil && len(m.mask) != len(m.value) {
+		return m, fmt.Errorf("mask %q doesn't fit value %q", xm.Mask, xm.Value)
+	}
+	return m, nil
+}
+
+// encodeNumber encodes the decimal, hex or octal number in the byte order and
+// size of the match type.
+func encodeNumber(typ, s string) ([]byte, error) {
+	n, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("bad %s value %q", typ, s)
+	}
+	var order binary.AppendByteOrder = binary.NativeEndian
+	switch {
+	case strings.HasPrefix(typ, "big"):
+		order = binary.BigEndian
+	case strings.HasPrefix(typ, "little"):
+		order = binary.LittleEndian
+	}
+	switch {
+	case typ == "byte":
+		return []byte{byte(n)}, nil
+	case strings.HasSuffix(typ, "16"):
+		return order.AppendUint16(nil, uint16(n)), nil
+	default:
+		return order.AppendUint32(nil, uint32(n)), nil
+	}
+}
+
+// unescape decodes the C style escapes of a string value.
+func unescape(s string) []byte {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b = append(b, c)
+			continue
+		}
+		i++
+		switch c = s[i]; {
+		case c == 'n':
+			b = append(b, '\n')
+		case c == 'r':
+			b = append(b, '\r')
+		case c == 't':
+			b = append(b, '\t')
+		case c == 'x':
+			j := i + 1
+			for j < len(s) && j < i+3 && isHex(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				b = append(b, c)
+				continue
+			}
+			n, _ := strconv.ParseUint(s[i+1:j], 16, 8)
+			b = append(b, byte(n))
+			i = j - 1
+		case '0' <= c && c <= '7':
+			j := i
+			for j < len(s) && j < i+3 && '0' <= s[j] && s[j] <= '7' {
+				j++
+			}
+			n, _ := strconv.ParseUint(s[i:j], 8, 8)
+			b = append(b, byte(n))
+			i = j - 1
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// isHex reports whether the byte is a hex digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// extent returns the number of bytes needed to evaluate the rules.
+func (mg magic) extent() int {
+	return matchesExtent(mg.matches)
+}
+
+// matchesExtent returns the number of bytes needed to evaluate the matches.
+func matchesExtent(ms []match) (n int) {
+	for _, m := range ms {
+		n = max(n, m.end+len(m.value), matchesExtent(m.children))
+	}
+	return
+}
+
+// match reports whether any of the rules matches the data.
+func (mg magic) match(data []byte) bool {
+	return anyMatch(mg.matches, data)
+}
+
+// anyMatch reports whether any of the matches matches the data.
+func anyMatch(ms []match, data []byte) bool {
+	for _, m := range ms {
+		if m.matchValue(data) && (len(m.children) == 0 || anyMatch(m.children, data)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue reports whether the value is found at an offset of the range.
+func (m match) matchValue(data []byte) bool {
+	for off := m.start; off <= m.end && off+len(m.value) <= len(data); off++ {
+		chunk := data[off : off+len(m.value)]
+		if m.mask == nil {
+			if bytes.Equal(chunk, m.value) {
+				return true
+			}
+			continue
+		}
+		ok := true
+		for i := range chunk {
+			if chunk[i]&m.mask[i] != m.value[i]&m.mask[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -20,9 +20,17 @@ const _ = grpc.SupportPackageIsVersion7
 
 const (
 	Knotidx_GetKeys_FullMethodName        = "/knotidx/GetKeys"
+	Knotidx_SearchStream_FullMethodName   = "/knotidx/SearchStream"
 	Knotidx_Reload_FullMethodName         = "/knotidx/Reload"
 	Knotidx_Shutdown_FullMethodName       = "/knotidx/Shutdown"
 	Knotidx_ResetScheduler_FullMethodName = "/knotidx/ResetScheduler"
+	Knotidx_ReportAccess_FullMethodName   = "/knotidx/ReportAccess"
+	Knotidx_Status_FullMethodName         = "/knotidx/Status"
+	Knotidx_Reindex_FullMethodName        = "/knotidx/Reindex"
+	Knotidx_CancelIndexing_FullMethodName = "/knotidx/CancelIndexing"
+	Knotidx_AddIndexer_FullMethodName     = "/knotidx/AddIndexer"
+	Knotidx_RemoveIndexer_FullMethodName  = "/knotidx/RemoveIndexer"
+	Knotidx_ListIndexers_FullMethodName   = "/knotidx/ListIndexers"
 )
 
 // KnotidxClient is the client API for Knotidx service.
@@ -30,9 +38,17 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type KnotidxClient interface {
 	GetKeys(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
-	Reload(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	SearchStream(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (Knotidx_SearchStreamClient, error)
+	Reload(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ReloadResponse, error)
 	Shutdown(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
 	ResetScheduler(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	ReportAccess(ctx context.Context, in *AccessRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	Status(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	Reindex(ctx context.Context, in *IndexerRequest, opts ...grpc.CallOption) (*IndexersResponse, error)
+	CancelIndexing(ctx context.Context, in *IndexerRequest, opts ...grpc.CallOption) (*IndexersResponse, error)
+	AddIndexer(ctx context.Context, in *AddIndexerRequest, opts ...grpc.CallOption) (*IndexersResponse, error)
+	RemoveIndexer(ctx context.Context, in *RemoveIndexerRequest, opts ...grpc.CallOption) (*RemoveIndexerResponse, error)
+	ListIndexers(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ListIndexersResponse, error)
 }
 
 type knotidxClient struct {
@@ -52,8 +68,40 @@ func (c *knotidxClient) GetKeys(ctx context.Context, in *SearchRequest, opts ...
 	return out, nil
 }
 
-func (c *knotidxClient) Reload(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
-	out := new(EmptyResponse)
+func (c *knotidxClient) SearchStream(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (Knotidx_SearchStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Knotidx_ServiceDesc.Streams[0], Knotidx_SearchStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &knotidxSearchStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Knotidx_SearchStreamClient interface {
+	Recv() (*SearchItemResponse, error)
+	grpc.ClientStream
+}
+
+type knotidxSearchStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *knotidxSearchStreamClient) Recv() (*SearchItemResponse, error) {
+	m := new(SearchItemResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *knotidxClient) Reload(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ReloadResponse, error) {
+	out := new(ReloadResponse)
 	err := c.cc.Invoke(ctx, Knotidx_Reload_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -79,14 +127,85 @@ func (c *knotidxClient) ResetScheduler(ctx context.Context, in *EmptyRequest, op
 	return out, nil
 }
 
+func (c *knotidxClient) ReportAccess(ctx context.Context, in *AccessRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, Knotidx_ReportAccess_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *knotidxClient) Status(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Knotidx_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *knotidxClient) Reindex(ctx context.Context, in *IndexerRequest, opts ...grpc.CallOption) (*IndexersResponse, error) {
+	out := new(IndexersResponse)
+	err := c.cc.Invoke(ctx, Knotidx_Reindex_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *knotidxClient) CancelIndexing(ctx context.Context, in *IndexerRequest, opts ...grpc.CallOption) (*IndexersResponse, error) {
+	out := new(IndexersResponse)
+	err := c.cc.Invoke(ctx, Knotidx_CancelIndexing_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *knotidxClient) AddIndexer(ctx context.Context, in *AddIndexerRequest, opts ...grpc.CallOption) (*IndexersResponse, error) {
+	out := new(IndexersResponse)
+	err := c.cc.Invoke(ctx, Knotidx_AddIndexer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *knotidxClient) RemoveIndexer(ctx context.Context, in *RemoveIndexerRequest, opts ...grpc.CallOption) (*RemoveIndexerResponse, error) {
+	out := new(RemoveIndexerResponse)
+	err := c.cc.Invoke(ctx, Knotidx_RemoveIndexer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *knotidxClient) ListIndexers(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ListIndexersResponse, error) {
+	out := new(ListIndexersResponse)
+	err := c.cc.Invoke(ctx, Knotidx_ListIndexers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KnotidxServer is the server API for Knotidx service.
 // All implementations must embed UnimplementedKnotidxServer
 // for forward compatibility
 type KnotidxServer interface {
 	GetKeys(context.Context, *SearchRequest) (*SearchResponse, error)
-	Reload(context.Context, *EmptyRequest) (*EmptyResponse, error)
+	SearchStream(*SearchRequest, Knotidx_SearchStreamServer) error
+	Reload(context.Context, *EmptyRequest) (*ReloadResponse, error)
 	Shutdown(context.Context, *EmptyRequest) (*EmptyResponse, error)
 	ResetScheduler(context.Context, *EmptyRequest) (*EmptyResponse, error)
+	ReportAccess(context.Context, *AccessRequest) (*EmptyResponse, error)
+	Status(context.Context, *EmptyRequest) (*StatusResponse, error)
+	Reindex(context.Context, *IndexerRequest) (*IndexersResponse, error)
+	CancelIndexing(context.Context, *IndexerRequest) (*IndexersResponse, error)
+	AddIndexer(context.Context, *AddIndexerRequest) (*IndexersResponse, error)
+	RemoveIndexer(context.Context, *RemoveIndexerRequest) (*RemoveIndexerResponse, error)
+	ListIndexers(context.Context, *EmptyRequest) (*ListIndexersResponse, error)
 	mustEmbedUnimplementedKnotidxServer()
 }
 
@@ -97,7 +216,10 @@ type UnimplementedKnotidxServer struct {
 func (UnimplementedKnotidxServer) GetKeys(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetKeys not implemented")
 }
-func (UnimplementedKnotidxServer) Reload(context.Context, *EmptyRequest) (*EmptyResponse, error) {
+func (UnimplementedKnotidxServer) SearchStream(*SearchRequest, Knotidx_SearchStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SearchStream not implemented")
+}
+func (UnimplementedKnotidxServer) Reload(context.Context, *EmptyRequest) (*ReloadResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Reload not implemented")
 }
 func (UnimplementedKnotidxServer) Shutdown(context.Context, *EmptyRequest) (*EmptyResponse, error) {
@@ -106,6 +228,27 @@ func (UnimplementedKnotidxServer) Shutdown(context.Context, *EmptyRequest) (*Emp
 func (UnimplementedKnotidxServer) ResetScheduler(context.Context, *EmptyRequest) (*EmptyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResetScheduler not implemented")
 }
+func (UnimplementedKnotidxServer) ReportAccess(context.Context, *AccessRequest) (*EmptyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportAccess not implemented")
+}
+func (UnimplementedKnotidxServer) Status(context.Context, *EmptyRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedKnotidxServer) Reindex(context.Context, *IndexerRequest) (*IndexersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reindex not implemented")
+}
+func (UnimplementedKnotidxServer) CancelIndexing(context.Context, *IndexerRequest) (*IndexersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelIndexing not implemented")
+}
+func (UnimplementedKnotidxServer) AddIndexer(context.Context, *AddIndexerRequest) (*IndexersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddIndexer not implemented")
+}
+func (UnimplementedKnotidxServer) RemoveIndexer(context.Context, *RemoveIndexerRequest) (*RemoveIndexerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveIndexer not implemented")
+}
+func (UnimplementedKnotidxServer) ListIndexers(context.Context, *EmptyRequest) (*ListIndexersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIndexers not implemented")
+}
 func (UnimplementedKnotidxServer) mustEmbedUnimplementedKnotidxServer() {}
 
 // UnsafeKnotidxServer may be embedded to opt out of forward compatibility for this service.
@@ -137,6 +280,27 @@ func _Knotidx_GetKeys_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Knotidx_SearchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KnotidxServer).SearchStream(m, &knotidxSearchStreamServer{stream})
+}
+
+type Knotidx_SearchStreamServer interface {
+	Send(*SearchItemResponse) error
+	grpc.ServerStream
+}
+
+type knotidxSearchStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *knotidxSearchStreamServer) Send(m *SearchItemResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Knotidx_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EmptyRequest)
 	if err := dec(in); err != nil {
@@ -191,6 +355,132 @@ func _Knotidx_ResetScheduler_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Knotidx_ReportAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).ReportAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_ReportAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).ReportAccess(ctx, req.(*AccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Knotidx_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).Status(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Knotidx_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_Reindex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).Reindex(ctx, req.(*IndexerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Knotidx_CancelIndexing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).CancelIndexing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_CancelIndexing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).CancelIndexing(ctx, req.(*IndexerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Knotidx_AddIndexer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddIndexerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).AddIndexer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_AddIndexer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).AddIndexer(ctx, req.(*AddIndexerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Knotidx_RemoveIndexer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveIndexerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).RemoveIndexer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_RemoveIndexer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).RemoveIndexer(ctx, req.(*RemoveIndexerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Knotidx_ListIndexers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KnotidxServer).ListIndexers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Knotidx_ListIndexers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KnotidxServer).ListIndexers(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Knotidx_ServiceDesc is the grpc.ServiceDesc for Knotidx service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -214,7 +504,41 @@ var Knotidx_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ResetScheduler",
 			Handler:    _Knotidx_ResetScheduler_Handler,
 		},
+		{
+			MethodName: "ReportAccess",
+			Handler:    _Knotidx_ReportAccess_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _Knotidx_Status_Handler,
+		},
+		{
+			MethodName: "Reindex",
+			Handler:    _Knotidx_Reindex_Handler,
+		},
+		{
+			MethodName: "CancelIndexing",
+			Handler:    _Knotidx_CancelIndexing_Handler,
+		},
+		{
+			MethodName: "AddIndexer",
+			Handler:    _Knotidx_AddIndexer_Handler,
+		},
+		{
+			MethodName: "RemoveIndexer",
+			Handler:    _Knotidx_RemoveIndexer_Handler,
+		},
+		{
+			MethodName: "ListIndexers",
+			Handler:    _Knotidx_ListIndexers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchStream",
+			Handler:       _Knotidx_SearchStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "knotidx.proto",
 }
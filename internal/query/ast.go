@@ -0,0 +1,478 @@
+package query
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/fold"
+	"github.com/shtirlic/knotidx/internal/fuzzy"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// Node represents a node of the query AST.
+type Node interface {
+	Match(key string, item store.ItemInfo) bool // Match reports whether the item matches the node.
+	String() string                             // String returns the canonical form of the node.
+}
+
+// CompareOp represents a comparison operator of numeric and time terms.
+type CompareOp string
+
+// Comparison operators.
+const (
+	OpEq CompareOp = "="
+	OpLt CompareOp = "<"
+	OpLe CompareOp = "<="
+	OpGt CompareOp = ">"
+	OpGe CompareOp = ">="
+)
+
+// compare applies the operator to the result of a three-way comparison.
+func (op CompareOp) compare(c int) bool {
+	switch op {
+	case OpLt:
+		return c < 0
+	case OpLe:
+		return c <= 0
+	case OpGt:
+		return c > 0
+	case OpGe:
+		return c >= 0
+	default:
+		return c == 0
+	}
+}
+
+// And matches items matching all of its nodes.
+type And struct {
+	Nodes []Node
+}
+
+func (n *And) Match(key string, item store.ItemInfo) bool {
+	for _, c := range n.Nodes {
+		if !c.Match(key, item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *And) String() string {
+	return joinNodes(n.Nodes, " ")
+}
+
+// Or matches items matching any of its nodes.
+type Or struct {
+	Nodes []Node
+}
+
+func (n *Or) Match(key string, item store.ItemInfo) bool {
+	for _, c := range n.Nodes {
+		if c.Match(key, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Or) String() string {
+	return joinNodes(n.Nodes, " OR ")
+}
+
+// Not matches items not matching its node.
+type Not struct {
+	Node Node
+}
+
+func (n *Not) Match(key string, item store.ItemInfo) bool {
+	return !n.Node.Match(key, item)
+}
+
+func (n *Not) String() string {
+	return "-" + joinNodes([]Node{n.Node}, "")
+}
+
+// Text matches items whose path contains the value or, if fuzzy, whose base
+// name or path matches it as a subsequence or with typos.
+type Text struct {
+	Value string
+	Fuzzy bool      // Match the value with fuzzy.MatchPath.
+	Fold  fold.Mode // Foldings of the value and path.
+}
+
+func (n *Text) Match(key string, item store.ItemInfo) bool {
+	v, p := fold.String(n.Value, n.Fold), fold.String(item.Path, n.Fold)
+	if n.Fuzzy {
+		_, _, ok := fuzzy.MatchPath(v, p)
+		return ok
+	}
+	return strings.Contains(p, v)
+}
+
+// Highlights returns the ranges of the path matched by the value.
+func (n *Text) Highlights(path string) []fuzzy.Range {
+	v, p := fold.String(n.Value, n.Fold), fold.Map(path, n.Fold)
+	var ranges []fuzzy.Range
+	if n.Fuzzy {
+		_, ranges, _ = fuzzy.MatchPath(v, p.String)
+	} else if i := strings.Index(p.String, v); i >= 0 && v != "" {
+		ranges = []fuzzy.Range{{Start: i, End: i + len(v)}}
+	}
+	return originalRanges(p, ranges, 0)
+}
+
+func (n *Text) String() string {
+	return strconv.Quote(n.Value)
+}
+
+// StringField matches a string field of the item against a substring or,
+// if the pattern contains wildcards, a glob pattern.
+type StringField struct {
+	Field   string    // Field name: name, path, mime, hash, a metadata key or a media field.
+	Pattern string    // Substring or glob pattern.
+	Glob    bool      // Pattern is a glob pattern.
+	Exact   bool      // Pattern must match the whole value.
+	Fold    fold.Mode // Foldings of the pattern and value.
+}
+
+func (n *StringField) Match(key string, item store.ItemInfo) bool {
+	var v string
+	switch n.Field {
+	case "name":
+		v = item.Name
+	case "path":
+		v = item.Path
+	case "mime":
+		v = item.MimeType
+	case "hash":
+		v = item.Hash
+	default:
+		// Indexer specific metadata, e.g. author or driver, or media metadata.
+		if v = item.Meta[n.Field]; v == "" {
+			v = mediaString(item.Media, n.Field)
+		}
+	}
+	pattern, v := fold.String(n.Pattern, n.Fold), fold.String(v, n.Fold)
+	switch {
+	case n.Glob:
+		ok, _ := path.Match(pattern, v)
+		return ok
+	case n.Exact:
+		return v == pattern
+	default:
+		return strings.Contains(v, pattern)
+	}
+}
+
+func (n *StringField) String() string {
+	return n.Field + ":" + strconv.Quote(n.Pattern)
+}
+
+// Pattern matches the name of the item or, if the pattern contains a slash,
+// its path against a regular expression or a glob pattern.
+type Pattern struct {
+	Kind     string         // Pattern syntax: regex or glob.
+	Pattern  string         // Pattern as written in the query.
+	Expr     string         // Regular expression of the pattern.
+	Path     bool           // Pattern matches the path instead of the name.
+	Re       *regexp.Regexp // Compiled pattern.
+	Literals []string       // Substrings of every matching name or path.
+	Fold     fold.Mode      // Foldings of the name or path.
+}
+
+func (n *Pattern) Match(key string, item store.ItemInfo) bool {
+	v := item.Name
+	if n.Path {
+		v = item.Path
+	}
+	return n.Re.MatchString(fold.String(v, n.valueFold()))
+}
+
+// valueFold returns the foldings of the matched values, the case is folded by
+// the pattern.
+func (n *Pattern) valueFold() fold.Mode {
+	if n.Fold == 0 {
+		return 0
+	}
+	return n.Fold&^fold.Case | fold.Normalize
+}
+
+// Highlights returns the ranges of the path matched by the pattern, only
+// for names that end the path.
+func (n *Pattern) Highlights(p string) []fuzzy.Range {
+	offset := 0
+	if !n.Path {
+		name := path.Base(p)
+		if !strings.HasSuffix(p, name) {
+			return nil
+		}
+		offset = len(p) - len(name)
+	}
+	f := fold.Map(p[offset:], n.valueFold())
+	var ranges []fuzzy.Range
+	for _, m := range n.Re.FindAllStringIndex(f.String, -1) {
+		if m[0] < m[1] {
+			ranges = append(ranges, fuzzy.Range{Start: m[0], End: m[1]})
+		}
+	}
+	return originalRanges(f, ranges, offset)
+}
+
+func (n *Pattern) String() string {
+	return n.Kind + ":" + strconv.Quote(n.Pattern)
+}
+
+// originalRanges maps the ranges of the folded string back to the original
+// string and shifts them by the offset of the original in the path.
+func originalRanges(f *fold.Folded, ranges []fuzzy.Range, offset int) []fuzzy.Range {
+	for i, r := range ranges {
+		r.Start, r.End = f.Original(r.Start, r.End)
+		ranges[i] = fuzzy.Range{Start: offset + r.Start, End: offset + r.End}
+	}
+	return ranges
+}
+
+// mediaString returns the string media field of the item metadata.
+func mediaString(m *store.MediaInfo, field string) string {
+	if m == nil {
+		return ""
+	}
+	switch field {
+	case "title":
+		return m.Title
+	case "artist":
+		return m.Artist
+	case "album":
+		return m.Album
+	case "genre":
+		return m.Genre
+	case "author":
+		return m.Author
+	case "publisher":
+		return m.Publisher
+	case "language":
+		return m.Language
+	case "camera":
+		return m.Camera
+	}
+	return ""
+}
+
+// ContentIndex looks up phrases in the full-text index of the item contents.
+type ContentIndex interface {
+	MatchPhrase(key string, terms []string) bool // MatchPhrase reports whether the item content contains the terms in order.
+}
+
+// Content matches items whose indexed content contains the terms of the
+// phrase at consecutive positions. It matches no items until a content index
+// is set.
+type Content struct {
+	Phrase string       // Phrase as written in the query.
+	Terms  []string     // Terms of the phrase.
+	Index  ContentIndex // Full-text index to look up the terms in.
+}
+
+func (n *Content) Match(key string, item store.ItemInfo) bool {
+	return n.Index != nil && n.Index.MatchPhrase(key, n.Terms)
+}
+
+func (n *Content) String() string {
+	return "content:" + strconv.Quote(n.Phrase)
+}
+
+// Tag matches items with a tag of the user.xdg.tags extended attribute equal to
+// the pattern or, if the pattern contains wildcards, matching the glob pattern.
+// Tags are compared case-insensitively.
+type Tag struct {
+	Pattern string // Tag or glob pattern in lower case.
+	Glob    bool   // Pattern is a glob pattern.
+}
+
+func (n *Tag) Match(key string, item store.ItemInfo) bool {
+	for _, t := range item.Tags() {
+		t = strings.ToLower(t)
+		if n.Glob {
+			if ok, _ := path.Match(n.Pattern, t); ok {
+				return true
+			}
+		} else if t == n.Pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Tag) String() string {
+	return "tag:" + strconv.Quote(n.Pattern)
+}
+
+// XAttr matches items having the extended attribute and, if the pattern is not
+// empty, whose attribute value matches the substring or glob pattern. Unlike
+// path globs, wildcards of value globs match slashes too.
+type XAttr struct {
+	Name    string         // Full attribute name, e.g. user.xdg.origin.url.
+	Pattern string         // Substring or glob pattern of the value.
+	Glob    *regexp.Regexp // Compiled glob pattern, nil for substrings.
+}
+
+func (n *XAttr) Match(key string, item store.ItemInfo) bool {
+	v, ok := item.XAttrs[n.Name]
+	switch {
+	case !ok:
+		return false
+	case n.Glob != nil:
+		return n.Glob.MatchString(v)
+	default:
+		return strings.Contains(v, n.Pattern)
+	}
+}
+
+func (n *XAttr) String() string {
+	if n.Pattern == "" {
+		return "xattr:" + strconv.Quote(n.Name)
+	}
+	return "xattr:" + strconv.Quote(n.Name+"="+n.Pattern)
+}
+
+// Type matches items of the given item type.
+type Type struct {
+	Type store.ItemType
+}
+
+func (n *Type) Match(key string, item store.ItemInfo) bool {
+	return item.Type == n.Type
+}
+
+func (n *Type) String() string {
+	return "type:" + string(n.Type)
+}
+
+// Size compares the item size in bytes.
+type Size struct {
+	Op   CompareOp
+	Size int64
+}
+
+func (n *Size) Match(key string, item store.ItemInfo) bool {
+	var c int
+	switch {
+	case item.Size < n.Size:
+		c = -1
+	case item.Size > n.Size:
+		c = 1
+	}
+	return n.Op.compare(c)
+}
+
+func (n *Size) String() string {
+	return fmt.Sprintf("size:%s%d", n.Op, n.Size)
+}
+
+// MediaNumber compares a numeric media field of the item: width, height or year.
+// Items without the field don't match.
+type MediaNumber struct {
+	Field string
+	Op    CompareOp
+	Value int
+}
+
+func (n *MediaNumber) Match(key string, item store.ItemInfo) bool {
+	if item.Media == nil {
+		return false
+	}
+	var v int
+	switch n.Field {
+	case "width":
+		v = item.Media.Width
+	case "height":
+		v = item.Media.Height
+	case "year":
+		v = item.Media.Year
+	}
+	if v == 0 {
+		return false
+	}
+	var c int
+	switch {
+	case v < n.Value:
+		c = -1
+	case v > n.Value:
+		c = 1
+	}
+	return n.Op.compare(c)
+}
+
+func (n *MediaNumber) String() string {
+	return fmt.Sprintf("%s:%s%d", n.Field, n.Op, n.Value)
+}
+
+// Duration compares the duration of audio and video items. Items without a
+// duration don't match.
+type Duration struct {
+	Op       CompareOp
+	Duration time.Duration
+}
+
+func (n *Duration) Match(key string, item store.ItemInfo) bool {
+	if item.Media == nil || item.Media.Duration == 0 {
+		return false
+	}
+	return n.Op.compare(compareDuration(item.Media.Duration, n.Duration))
+}
+
+func (n *Duration) String() string {
+	return fmt.Sprintf("duration:%s%s", n.Op, n.Duration)
+}
+
+// ModTime compares the item modification time. If Age is set the comparison is
+// applied to the item age (time since modification), so mtime:<7d matches items
+// modified during the last week.
+type ModTime struct {
+	Op   CompareOp
+	Time time.Time     // Absolute time to compare with.
+	Age  time.Duration // Age to compare with, used if Time is zero.
+}
+
+func (n *ModTime) Match(key string, item store.ItemInfo) bool {
+	if n.Time.IsZero() {
+		return n.Op.compare(compareDuration(time.Since(item.ModTime), n.Age))
+	}
+	return n.Op.compare(item.ModTime.Compare(n.Time))
+}
+
+func (n *ModTime) String() string {
+	if n.Time.IsZero() {
+		return fmt.Sprintf("mtime:%s%s", n.Op, n.Age)
+	}
+	return fmt.Sprintf("mtime:%s%s", n.Op, n.Time.Format(time.RFC3339))
+}
+
+// compareDuration returns a three-way comparison of two durations.
+func compareDuration(a, b time.Duration) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// joinNodes joins nodes string forms with the separator, grouping nested
+// boolean nodes with parentheses.
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		switch n.(type) {
+		case *And, *Or:
+			parts = append(parts, "("+n.String()+")")
+		default:
+			parts = append(parts, n.String())
+		}
+	}
+	return strings.Join(parts, sep)
+}
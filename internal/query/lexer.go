@@ -0,0 +1,179 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind represents the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota // End of the query.
+	tokLParen                  // Opening parenthesis.
+	tokRParen                  // Closing parenthesis.
+	tokNot                     // Negation, "-" prefix or NOT.
+	tokAnd                     // Explicit AND keyword.
+	tokOr                      // OR keyword.
+	tokTerm                    // Bare or field term.
+)
+
+// token represents a lexical token of the query.
+type token struct {
+	kind  tokenKind
+	pos   int    // Byte offset of the token in the query.
+	field string // Field name for field terms, empty for bare terms.
+	value string // Term value with quotes removed.
+}
+
+// lexer splits the query string into tokens.
+type lexer struct {
+	input string
+	pos   int
+}
+
+// next returns the next token from the input.
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	switch c := l.input[l.pos]; {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, pos: start}, nil
+	case c == '-' && l.pos+1 < len(l.input) && (l.input[l.pos+1] == '(' || !isTermEnd(l.input[l.pos+1])):
+		l.pos++
+		return token{kind: tokNot, pos: start}, nil
+	}
+
+	return l.term()
+}
+
+// term reads a bare word, a quoted string or a field:value pair.
+func (l *lexer) term() (token, error) {
+	start := l.pos
+	tok := token{kind: tokTerm, pos: start}
+
+	// Quoted bare term.
+	if l.input[l.pos] == '"' {
+		v, err := l.quoted()
+		if err != nil {
+			return tok, err
+		}
+		tok.value = v
+		return tok, nil
+	}
+
+	var b strings.Builder
+	quoted := false
+	depth := 0 // Open parentheses of an unquoted field value.
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		// Unquoted field values keep balanced and escaped parentheses,
+		// e.g. regex:(?i)foo or regex:(a|b)\), a closing parenthesis
+		// without an opening one ends the term, e.g. in (name:foo).
+		if tok.field != "" && !quoted {
+			if c == '\\' && l.pos+1 < len(l.input) && (l.input[l.pos+1] == '(' || l.input[l.pos+1] == ')') {
+				b.WriteString(l.input[l.pos : l.pos+2])
+				l.pos += 2
+				continue
+			}
+			if c == '(' || (c == ')' && depth > 0) {
+				if c == '(' {
+					depth++
+				} else {
+					depth--
+				}
+				b.WriteByte(c)
+				l.pos++
+				continue
+			}
+		}
+		if isTermEnd(c) {
+			break
+		}
+		if c == ':' && tok.field == "" && b.Len() > 0 {
+			tok.field = b.String()
+			b.Reset()
+			l.pos++
+			if l.pos < len(l.input) && l.input[l.pos] == '"' {
+				v, err := l.quoted()
+				if err != nil {
+					return tok, err
+				}
+				b.WriteString(v)
+				quoted = true
+			}
+			continue
+		}
+		if c == '"' {
+			return tok, &Error{Pos: l.pos, Msg: "unexpected quote"}
+		}
+		b.WriteByte(c)
+		l.pos++
+	}
+	if depth > 0 {
+		return tok, &Error{Pos: start, Msg: fmt.Sprintf("unbalanced parentheses in value of field %q, quote the value", tok.field)}
+	}
+	tok.value = b.String()
+
+	if tok.field == "" {
+		switch tok.value {
+		case "AND":
+			tok.kind = tokAnd
+		case "OR":
+			tok.kind = tokOr
+		case "NOT":
+			tok.kind = tokNot
+		}
+	}
+	return tok, nil
+}
+
+// quoted reads a double quoted string starting at the current position.
+// Backslash escapes quotes and backslashes, other backslashes are kept for
+// the escapes of regular expressions and glob patterns.
+func (l *lexer) quoted() (string, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.input) && (l.input[l.pos+1] == '"' || l.input[l.pos+1] == '\\'):
+			b.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+		case c == '"':
+			l.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return "", &Error{Pos: start, Msg: "unterminated quoted string"}
+}
+
+// skipSpace advances the position past any whitespace.
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+// isTermEnd reports whether c terminates an unquoted term.
+func isTermEnd(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')'
+}
@@ -0,0 +1,387 @@
+package query
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shtirlic/knotidx/internal/fulltext"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// Size units accepted by the size field.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// Duration units accepted by the mtime field in addition to time.ParseDuration ones.
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// Date layouts accepted by the mtime field.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parser is a recursive descent parser for the query language:
+//
+//	or      = and { "OR" and }
+//	and     = unary { ["AND"] unary }
+//	unary   = ( "-" | "NOT" ) unary | primary
+//	primary = "(" or ")" | term
+type parser struct {
+	lex lexer
+	tok token
+}
+
+// newParser creates a parser for the query string.
+func newParser(s string) *parser {
+	return &parser{lex: lexer{input: s}}
+}
+
+// parse parses the whole query. It returns nil for an empty query.
+func (p *parser) parse() (Node, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	return n, nil
+}
+
+// advance reads the next token.
+func (p *parser) advance() error {
+	var err error
+	p.tok, err = p.lex.next()
+	return err
+}
+
+func (p *parser) parseOr() (Node, error) {
+	n, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []Node{n}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if n, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &Or{Nodes: nodes}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes []Node
+	for {
+		switch p.tok.kind {
+		case tokAnd:
+			if len(nodes) == 0 {
+				return nil, p.errorf("unexpected AND")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		case tokEOF, tokOr, tokRParen:
+			switch len(nodes) {
+			case 0:
+				return nil, p.errorf("expected term, got %s", p.describe())
+			case 1:
+				return nodes[0], nil
+			}
+			return &And{Nodes: nodes}, nil
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.tok.kind == tokNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Node: n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	switch p.tok.kind {
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ), got %s", p.describe())
+		}
+		return n, p.advance()
+	case tokTerm:
+		n, err := p.parseTerm(p.tok)
+		if err != nil {
+			return nil, err
+		}
+		return n, p.advance()
+	}
+	return nil, p.errorf("expected term, got %s", p.describe())
+}
+
+// parseTerm converts a term token into a query node.
+func (p *parser) parseTerm(t token) (Node, error) {
+	if t.field == "" {
+		return &Text{Value: t.value}, nil
+	}
+	if t.value == "" {
+		return nil, p.errorf("empty value for field %q", t.field)
+	}
+
+	switch t.field {
+	case "name", "path", "mime", "author", "vendor", "model", "driver", "subsystem",
+		"title", "artist", "album", "genre", "publisher", "language", "camera":
+		n := &StringField{Field: t.field, Pattern: t.value}
+		if strings.ContainsAny(t.value, "*?[") {
+			if _, err := path.Match(t.value, ""); err != nil {
+				return nil, p.errorf("bad %s pattern %q", t.field, t.value)
+			}
+			n.Glob = true
+		}
+		return n, nil
+	case "hash":
+		return &StringField{Field: t.field, Pattern: t.value, Exact: true}, nil
+	case "regex", "glob":
+		return p.parsePattern(t.field, t.value)
+	case "tag":
+		n := &Tag{Pattern: strings.ToLower(t.value)}
+		if strings.ContainsAny(t.value, "*?[") {
+			if _, err := path.Match(n.Pattern, ""); err != nil {
+				return nil, p.errorf("bad tag pattern %q", t.value)
+			}
+			n.Glob = true
+		}
+		return n, nil
+	case "content":
+		terms := fulltext.Terms(t.value)
+		if len(terms) == 0 {
+			return nil, p.errorf("no words in content phrase %q", t.value)
+		}
+		return &Content{Phrase: t.value, Terms: terms}, nil
+	case "comment":
+		return p.parseXAttr(store.XAttrComment, t.value)
+	case "xattr":
+		name, pattern, _ := strings.Cut(t.value, "=")
+		if !strings.HasPrefix(name, "user.") {
+			name = "user." + name
+		}
+		return p.parseXAttr(name, pattern)
+	case "type":
+		return &Type{Type: store.ItemType(t.value)}, nil
+	case "size":
+		op, v := splitOp(t.value)
+		size, err := parseSize(v)
+		if err != nil {
+			return nil, p.errorf("bad size %q", v)
+		}
+		return &Size{Op: op, Size: size}, nil
+	case "width", "height", "year":
+		op, v := splitOp(t.value)
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, p.errorf("bad %s %q", t.field, v)
+		}
+		return &MediaNumber{Field: t.field, Op: op, Value: n}, nil
+	case "duration":
+		op, v := splitOp(t.value)
+		d, err := parseAge(v)
+		if err != nil {
+			return nil, p.errorf("bad duration %q", v)
+		}
+		return &Duration{Op: op, Duration: d}, nil
+	case "mtime":
+		op, v := splitOp(t.value)
+		if d, err := parseAge(v); err == nil {
+			return &ModTime{Op: op, Age: d}, nil
+		}
+		tm, err := parseDate(v)
+		if err != nil {
+			return nil, p.errorf("bad mtime %q", v)
+		}
+		return &ModTime{Op: op, Time: tm}, nil
+	}
+	return nil, p.errorf("unknown field %q", t.field)
+}
+
+// parseXAttr returns the extended attribute node matching the value pattern.
+func (p *parser) parseXAttr(name, pattern string) (Node, error) {
+	n := &XAttr{Name: name, Pattern: pattern}
+	if strings.ContainsAny(pattern, "*?[") {
+		re, err := globRegexp(pattern)
+		if err != nil {
+			return nil, p.errorf("bad %s pattern %q", name, pattern)
+		}
+		n.Glob = re
+	}
+	return n, nil
+}
+
+// globRegexp compiles the glob pattern to a regular expression matching the
+// whole string, its wildcards match any character including slashes.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`^(?s:`)
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i++; i == len(pattern) {
+				return nil, path.ErrBadPattern
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			j := strings.IndexByte(pattern[i+1:], ']')
+			if j < 0 {
+				return nil, path.ErrBadPattern
+			}
+			class := pattern[i+1 : i+1+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString(`)$`)
+	return regexp.Compile(b.String())
+}
+
+// errorf returns a parse error at the current token position.
+func (p *parser) errorf(format string, args ...any) error {
+	return &Error{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// describe returns the human readable form of the current token.
+func (p *parser) describe() string {
+	switch p.tok.kind {
+	case tokEOF:
+		return "end of query"
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	case tokNot:
+		return "NOT"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	}
+	return strconv.Quote(p.tok.value)
+}
+
+// splitOp splits a comparison operator prefix from the value.
+func splitOp(v string) (CompareOp, string) {
+	for _, op := range []CompareOp{OpLe, OpGe, OpLt, OpGt, OpEq} {
+		if s, ok := strings.CutPrefix(v, string(op)); ok {
+			return op, s
+		}
+	}
+	return OpEq, v
+}
+
+// parseSize parses a size with an optional binary unit suffix, e.g. 10M or 1.5GiB.
+func parseSize(v string) (int64, error) {
+	i := strings.IndexFunc(v, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(v)
+	}
+	unit, ok := sizeUnits[strings.ToLower(v[i:])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", v[i:])
+	}
+	n, err := strconv.ParseFloat(v[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad size number %q", v[:i])
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// parseAge parses an age like 90s, 2h, 7d, 2w or 1y.
+func parseAge(v string) (time.Duration, error) {
+	if len(v) > 1 {
+		if unit, ok := durationUnits[v[len(v)-1:]]; ok {
+			n, err := strconv.ParseFloat(v[:len(v)-1], 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("bad age %q", v)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("bad age %q", v)
+	}
+	return d, nil
+}
+
+// parseDate parses an absolute date in local time.
+func parseDate(v string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad date %q", v)
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // Canonical form of the parsed query.
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"foo", `"foo"`},
+		{"foo bar", `"foo" "bar"`},
+		{"foo AND bar", `"foo" "bar"`},
+
+		// AND binds tighter than OR, NOT tighter than AND.
+		{"foo OR bar baz", `"foo" OR ("bar" "baz")`},
+		{"foo bar OR baz", `("foo" "bar") OR "baz"`},
+		{"a AND b OR c AND d", `("a" "b") OR ("c" "d")`},
+		{"a OR b OR c", `"a" OR "b" OR "c"`},
+		{"-a b", `-"a" "b"`},
+		{"NOT a OR b", `-"a" OR "b"`},
+		{"NOT -a", `--"a"`},
+		{"a (b OR c)", `"a" ("b" OR "c")`},
+		{"-(a OR b) c", `-("a" OR "b") "c"`},
+		{"((a))", `"a"`},
+		{"(a b) OR (c -d)", `("a" "b") OR ("c" -"d")`},
+
+		// Dashes only negate at the start of a term.
+		{"a-b", `"a-b"`},
+		{"- a", `"-" "a"`},
+		{"-", `"-"`},
+
+		// Keywords are upper case and unquoted.
+		{"a and b", `"a" "and" "b"`},
+		{`"OR" "AND" "NOT"`, `"OR" "AND" "NOT"`},
+
+		// Quoting.
+		{`"foo bar"`, `"foo bar"`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{`"a\\b"`, `"a\\b"`},
+		{`"a\d"`, `"a\\d"`},
+		{`""`, `""`},
+		{`"a"b`, `"a" "b"`},
+		{`name:"a b"`, `name:"a b"`},
+		{`name:"(x)"`, `name:"(x)"`},
+		{`regex:"^report-\d{4}\.pdf$"`, `regex:"^report-\\d{4}\\.pdf$"`},
+
+		// Fields.
+		{"name:*.go", `name:"*.go"`},
+		{"hash:abc", `hash:"abc"`},
+		{"type:dir", "type:dir"},
+		{"size:>10k", "size:>10240"},
+		{"size:<=1.5M", "size:<=1572864"},
+		{"width:>=1920", "width:>=1920"},
+		{"mtime:<7d", "mtime:<168h0m0s"},
+		{"duration:>90s", "duration:>1m30s"},
+		{"tag:Work", `tag:"work"`},
+		{"xattr:origin=http*", `xattr:"user.origin=http*"`},
+		{"comment:todo", `xattr:"user.xdg.comment=todo"`},
+		{`content:"exact phrase"`, `content:"exact phrase"`},
+		{"glob:/src/**/*.go", `glob:"/src/**/*.go"`},
+
+		// Unquoted field values keep balanced and escaped parentheses.
+		{"regex:(foo|bar)", `regex:"(foo|bar)"`},
+		{"regex:(?i)foo", `regex:"(?i)foo"`},
+		{`regex:a\(b`, `regex:"a\\(b"`},
+		{"(name:foo)", `name:"foo"`},
+		{"a (regex:(b|c))", `"a" regex:"(b|c)"`},
+		{"(regex:(b|c) OR d)", `regex:"(b|c)" OR "d"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`"abc`, 0, "unterminated quoted string"},
+		{`a "b`, 2, "unterminated quoted string"},
+		{`name:"x`, 5, "unterminated quoted string"},
+		{`ab"c`, 2, "unexpected quote"},
+		{"(a", 2, "expected ), got end of query"},
+		{"(a b", 4, "expected ), got end of query"},
+		{"a)", 1, "unexpected )"},
+		{"()", 1, "expected term, got )"},
+		{"AND a", 0, "unexpected AND"},
+		{"a OR", 4, "expected term, got end of query"},
+		{"a OR OR b", 5, "expected term, got OR"},
+		{"OR a", 0, "expected term, got OR"},
+		{"a NOT", 5, "expected term, got end of query"},
+		{"-(", 2, "expected term, got end of query"},
+		{"foo:bar", 0, `unknown field "foo"`},
+		{"a name:", 2, `empty value for field "name"`},
+		{"regex:(foo bar)", 0, `unbalanced parentheses in value of field "regex", quote the value`},
+		{"a regex:((b)", 2, `unbalanced parentheses in value of field "regex", quote the value`},
+		{"a size:big", 2, `bad size "big"`},
+		{"size:10x", 0, `bad size "10x"`},
+		{"mtime:yesterday", 0, `bad mtime "yesterday"`},
+		{"year:-1", 0, `bad year "-1"`},
+		{"name:[a", 0, `bad name pattern "[a"`},
+		{"content:!?", 0, `no words in content phrase "!?"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var qe *Error
+			if !errors.As(err, &qe) {
+				t.Fatalf("got error %v, want query error", err)
+			}
+			if qe.Pos != tt.pos || qe.Msg != tt.msg {
+				t.Errorf("got %q at %d, want %q at %d", qe.Msg, qe.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	tests := []struct {
+		query string
+		fuzzy bool
+		want  []string
+	}{
+		{"", false, nil},
+		{"foo bar", false, []string{"foo", "bar"}},
+		{"foo OR bar", false, nil},
+		{"-foo bar", false, []string{"bar"}},
+		{"a (b OR c) d", false, []string{"a", "d"}},
+		{"a (b c)", false, []string{"a", "b", "c"}},
+		{`"foo bar"`, false, []string{"foo bar"}},
+		{"name:*.go path:/src", false, []string{".go", "/src"}},
+		{`name:a*b\*c?d`, false, []string{"a", "b*c", "d"}},
+		{"name:[ab]x", false, []string{"x"}},
+		{"mime:text/plain type:file size:>1k", false, nil},
+		{`regex:"^report-\d{4}\.pdf$"`, false, []string{"report-", ".pdf"}},
+		{`regex:"(?i)readme"`, false, nil},
+		{"glob:*.md", false, []string{".md"}},
+		{"foo name:bar", true, []string{"bar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.fuzzy {
+				q.SetFuzzy()
+			}
+			if got := q.Literals(); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
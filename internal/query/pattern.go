@@ -0,0 +1,155 @@
+package query
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/shtirlic/knotidx/internal/fold"
+)
+
+// parsePattern returns the node matching the RE2 regular expression or glob
+// pattern of the kind. Patterns containing a slash match the item path.
+func (p *parser) parsePattern(kind, pattern string) (Node, error) {
+	n := &Pattern{Kind: kind, Pattern: pattern, Path: strings.Contains(pattern, "/")}
+	expr := pattern
+	var err error
+	if kind == "glob" {
+		if expr, err = globExpr(pattern, n.Path); err != nil {
+			return nil, p.errorf("bad glob %q: %v", pattern, err)
+		}
+	}
+	n.Expr = expr
+	if n.Re, err = regexp.Compile(expr); err != nil {
+		if se := (*syntax.Error)(nil); errors.As(err, &se) {
+			return nil, p.errorf("bad %s %q: %v %q", kind, pattern, se.Code, se.Expr)
+		}
+		return nil, p.errorf("bad %s %q: %v", kind, pattern, err)
+	}
+	// The expression compiled, so it parses.
+	re, _ := syntax.Parse(expr, syntax.Perl)
+	n.Literals = regexLiterals(re.Simplify())
+	return n, nil
+}
+
+// setFold recompiles the pattern to match names and paths folded by the
+// mode: case-insensitively with the i flag and without the diacritics of
+// its literals.
+func (n *Pattern) setFold(mode fold.Mode) {
+	re, err := syntax.Parse(n.Expr, syntax.Perl)
+	if err != nil {
+		return
+	}
+	if mode&fold.Accents != 0 {
+		foldLiterals(re, mode&^fold.Case)
+	}
+	expr := re.String()
+	if mode&fold.Case != 0 {
+		expr = "(?i)" + expr
+	}
+	if n.Re, err = regexp.Compile(expr); err == nil {
+		n.Fold = mode
+	}
+}
+
+// foldLiterals folds the literals of the regular expression by the mode.
+func foldLiterals(re *syntax.Regexp, mode fold.Mode) {
+	if re.Op == syntax.OpLiteral {
+		re.Rune = []rune(fold.String(string(re.Rune), mode))
+	}
+	for _, sub := range re.Sub {
+		foldLiterals(sub, mode)
+	}
+}
+
+// globExpr translates the glob pattern to a regular expression matching
+// whole names or, for path patterns, paths. In path patterns "*" and "?"
+// don't match slashes, "**" matches any number of directories and relative
+// patterns match at any directory.
+func globExpr(pattern string, isPath bool) (string, error) {
+	var b strings.Builder
+	switch {
+	case !isPath:
+		b.WriteString(`^`)
+	case strings.HasPrefix(pattern, "/"):
+		b.WriteString(`^`)
+	default:
+		b.WriteString(`(?:^|/)`)
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if !strings.HasPrefix(pattern[i:], "**") {
+				b.WriteString(`[^/]*`)
+				break
+			}
+			i++
+			if strings.HasPrefix(pattern[i+1:], "/") {
+				b.WriteString(`(?:.*/)?`)
+				i++
+				break
+			}
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`[^/]`)
+		case '\\':
+			if i++; i == len(pattern) {
+				return "", path.ErrBadPattern
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			j := strings.IndexByte(pattern[i+1:], ']')
+			if j < 0 {
+				return "", path.ErrBadPattern
+			}
+			class := pattern[i+1 : i+1+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString(`$`)
+	return b.String(), nil
+}
+
+// regexLiterals returns substrings that every string matching the simplified
+// regular expression contains, used to narrow the store search with the
+// trigram index. Case folded literals and alternatives contribute none.
+func regexLiterals(re *syntax.Regexp) (lits []string) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase == 0 {
+			lits = append(lits, string(re.Rune))
+		}
+	case syntax.OpCapture, syntax.OpPlus:
+		lits = regexLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			lits = regexLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		// Join the runs of adjacent literals.
+		var run strings.Builder
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral && sub.Flags&syntax.FoldCase == 0 {
+				run.WriteString(string(sub.Rune))
+				continue
+			}
+			if run.Len() > 0 {
+				lits = append(lits, run.String())
+				run.Reset()
+			}
+			lits = append(lits, regexLiterals(sub)...)
+		}
+		if run.Len() > 0 {
+			lits = append(lits, run.String())
+		}
+	}
+	return
+}
@@ -0,0 +1,302 @@
+// Package query implements the knotidx search query language.
+//
+// A query is a list of terms joined by implicit AND. Terms can be combined with
+// OR, negated with a leading "-" or NOT and grouped with parentheses:
+//
+//	type:file size:>10M mtime:<7d mime:image/* name:foo
+//	(name:*.jpg OR name:*.png) -path:/tmp
+//	content:"exact phrase" name:*.md
+//	regex:"^report-\d{4}\.pdf$" glob:"/home/**/docs/*"
+//
+// Bare words and quoted strings match as substrings of the item path or, in
+// fuzzy mode, as abbreviations of the item name or path.
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shtirlic/knotidx/internal/fold"
+	"github.com/shtirlic/knotidx/internal/fuzzy"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// Error represents a query parse error.
+type Error struct {
+	Pos int    // Byte offset of the error in the query.
+	Msg string // Description of the error.
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("query: %s at position %d", e.Msg, e.Pos)
+}
+
+// Query represents a parsed search query.
+type Query struct {
+	Root Node // Root of the query AST, nil matches everything.
+}
+
+// Parse parses the query string and returns the parsed Query.
+func Parse(s string) (*Query, error) {
+	p := newParser(s)
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{Root: root}, nil
+}
+
+// Match reports whether the item stored under key matches the query.
+func (q *Query) Match(key string, item store.ItemInfo) bool {
+	if q == nil || q.Root == nil {
+		return true
+	}
+	return q.Root.Match(key, item)
+}
+
+// Literals returns substrings that the path of every matching item must contain.
+// They are used to narrow the store search with the trigram index.
+func (q *Query) Literals() []string {
+	if q == nil || q.Root == nil {
+		return nil
+	}
+	return literals(q.Root)
+}
+
+// literals collects required path substrings from the node and its AND children.
+func literals(n Node) (lits []string) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			lits = append(lits, literals(c)...)
+		}
+	case *Text:
+		// Fuzzy values needn't be substrings of the path.
+		if !n.Fuzzy {
+			lits = append(lits, n.Value)
+		}
+	case *StringField:
+		if n.Field != "name" && n.Field != "path" {
+			break
+		}
+		if !n.Glob {
+			lits = append(lits, n.Pattern)
+			break
+		}
+		lits = append(lits, globLiterals(n.Pattern)...)
+	case *Pattern:
+		lits = append(lits, n.Literals...)
+	}
+	return
+}
+
+// ContentTerms returns the content terms that every matching item must contain.
+// They are used to narrow the store search with the content index.
+func (q *Query) ContentTerms() []string {
+	if q == nil || q.Root == nil {
+		return nil
+	}
+	return contentTerms(q.Root)
+}
+
+// contentTerms collects required content terms from the node and its AND children.
+func contentTerms(n Node) (terms []string) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			terms = append(terms, contentTerms(c)...)
+		}
+	case *Content:
+		terms = append(terms, n.Terms...)
+	}
+	return
+}
+
+// SetContentIndex sets the full-text index used by the content terms of the query.
+func (q *Query) SetContentIndex(idx ContentIndex) {
+	if q == nil || q.Root == nil {
+		return
+	}
+	setContentIndex(q.Root, idx)
+}
+
+// setContentIndex sets the full-text index of the content nodes of the tree.
+func setContentIndex(n Node, idx ContentIndex) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			setContentIndex(c, idx)
+		}
+	case *Or:
+		for _, c := range n.Nodes {
+			setContentIndex(c, idx)
+		}
+	case *Not:
+		setContentIndex(n.Node, idx)
+	case *Content:
+		n.Index = idx
+	}
+}
+
+// RankTerms returns the name and path substrings searched for by the query,
+// including those of alternatives. They are used to rank the matching items.
+func (q *Query) RankTerms() []string {
+	if q == nil || q.Root == nil {
+		return nil
+	}
+	return rankTerms(q.Root)
+}
+
+// rankTerms collects the name and path substrings of the node and its AND and
+// OR children. Negated nodes don't contribute.
+func rankTerms(n Node) (terms []string) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			terms = append(terms, rankTerms(c)...)
+		}
+	case *Or:
+		for _, c := range n.Nodes {
+			terms = append(terms, rankTerms(c)...)
+		}
+	case *Text:
+		terms = append(terms, n.Value)
+	case *StringField, *Pattern:
+		terms = literals(n)
+	}
+	return
+}
+
+// SetFuzzy makes the bare words of the query match fuzzily the base names and
+// paths of items, tolerating typos and abbreviations. Fuzzy words don't
+// narrow the store search.
+func (q *Query) SetFuzzy() {
+	if q == nil || q.Root == nil {
+		return
+	}
+	setFuzzy(q.Root)
+}
+
+// setFuzzy sets the text nodes of the tree fuzzy.
+func setFuzzy(n Node) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			setFuzzy(c)
+		}
+	case *Or:
+		for _, c := range n.Nodes {
+			setFuzzy(c)
+		}
+	case *Not:
+		setFuzzy(n.Node)
+	case *Text:
+		n.Fuzzy = true
+	}
+}
+
+// SetFold makes the bare words, the string fields and the regex and glob
+// patterns of the query match the normalized and folded item fields, e.g.
+// case- and accent-insensitively.
+func (q *Query) SetFold(mode fold.Mode) {
+	if q == nil || q.Root == nil {
+		return
+	}
+	setFold(q.Root, mode)
+}
+
+// setFold sets the foldings of the text, string field and pattern nodes of
+// the tree.
+func setFold(n Node, mode fold.Mode) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			setFold(c, mode)
+		}
+	case *Or:
+		for _, c := range n.Nodes {
+			setFold(c, mode)
+		}
+	case *Not:
+		setFold(n.Node, mode)
+	case *Text:
+		n.Fold = mode
+	case *StringField:
+		n.Fold = mode
+	case *Pattern:
+		n.setFold(mode)
+	}
+}
+
+// Highlights returns the sorted ranges of the path matched by the bare words
+// and the regex and glob patterns of the query, negated terms excluded.
+func (q *Query) Highlights(path string) []fuzzy.Range {
+	if q == nil || q.Root == nil {
+		return nil
+	}
+	return fuzzy.Merge(highlights(q.Root, path))
+}
+
+// highlights collects the ranges of the path matched by the text and pattern
+// nodes of the node and its AND and OR children.
+func highlights(n Node, path string) (ranges []fuzzy.Range) {
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			ranges = append(ranges, highlights(c, path)...)
+		}
+	case *Or:
+		for _, c := range n.Nodes {
+			ranges = append(ranges, highlights(c, path)...)
+		}
+	case *Text:
+		ranges = n.Highlights(path)
+	case *Pattern:
+		ranges = n.Highlights(path)
+	}
+	return
+}
+
+// globLiterals returns the literal runs of a glob pattern.
+func globLiterals(pattern string) (lits []string) {
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			lits = append(lits, b.String())
+			b.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*', '?':
+			flush()
+		case '[':
+			flush()
+			// Skip the character class.
+			for i < len(pattern) && pattern[i] != ']' {
+				if pattern[i] == '\\' {
+					i++
+				}
+				i++
+			}
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+	return
+}
+
+// String returns the canonical form of the query.
+func (q *Query) String() string {
+	if q == nil || q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
@@ -0,0 +1,229 @@
+// Package rank scores search results by relevance: where and how the query
+// terms match the item path, the depth of the path, the recency of the item
+// and the frecency of its accesses reported by clients.
+package rank
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shtirlic/knotidx/internal/fold"
+	"github.com/shtirlic/knotidx/internal/fuzzy"
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+// Match scores of a query term, by where it is found in the item path.
+const (
+	exactNameScore  = 1.0 // Whole name.
+	exactStemScore  = 0.9 // Name without its extension.
+	namePrefixScore = 0.8 // Start of the name.
+	nameWordScore   = 0.7 // Start of a word of the name.
+	nameScore       = 0.6 // Anywhere in the name.
+	parentScore     = 0.4 // Name of the parent directory.
+	dirScore        = 0.3 // Anywhere in the directory.
+	noTermScore     = 0.5 // Queries without name or path terms.
+)
+
+// Weights of the score components added to the match score.
+const (
+	recencyWeight  = 0.3
+	frecencyWeight = 0.5
+)
+
+// depthPenalty is the match score decrease per directory level.
+const depthPenalty = 0.05
+
+// recencyHalfLife is the item age at which the recency component halves.
+const recencyHalfLife = 30 * 24 * time.Hour
+
+// Scorer scores items against the terms of a query.
+type Scorer struct {
+	terms []string  // Case and accent folded name and path terms.
+	fuzzy bool      // Score terms that aren't substrings as fuzzy matches.
+	now   time.Time // Reference time of recency and frecency.
+}
+
+// Result represents a search result with its score.
+type Result struct {
+	store.SearchResult
+	Score float64
+}
+
+// NewScorer creates a scorer for the name and path terms of a query.
+func NewScorer(terms []string) *Scorer {
+	s := &Scorer{now: time.Now()}
+	for _, t := range terms {
+		if t != "" {
+			s.terms = append(s.terms, fold.String(t, fold.All))
+		}
+	}
+	return s
+}
+
+// SetFuzzy makes the scorer score the terms that aren't substrings of the
+// name or directory by the quality of their fuzzy match.
+func (s *Scorer) SetFuzzy(fuzzy bool) {
+	s.fuzzy = fuzzy
+}
+
+// Score returns the relevance of the search result, higher is more relevant.
+// The match score in [0, 1] is lowered by the path depth, recency and the
+// frecency of the accesses of the result add up to recencyWeight and
+// frecencyWeight.
+func (s *Scorer) Score(r store.SearchResult) float64 {
+	item := r.Item
+	p := fold.String(strings.TrimRight(item.Path, "/"), fold.All)
+	name := fold.String(item.Name, fold.All)
+	if name == "" || !strings.HasSuffix(p, name) {
+		name = path.Base(p)
+	}
+	dir := strings.TrimSuffix(p, name)
+	depth := strings.Count(strings.Trim(dir, "/"), "/")
+	if strings.Trim(dir, "/") != "" {
+		depth++
+	}
+
+	score := s.match(name, dir) / (1 + depthPenalty*float64(depth))
+	if !item.ModTime.IsZero() {
+		age := max(s.now.Sub(item.ModTime), 0)
+		score += recencyWeight * math.Exp2(-float64(age)/float64(recencyHalfLife))
+	}
+	// Saturate the frecency in [0, 1).
+	if f := r.Access.At(s.now); f > 0 {
+		score += frecencyWeight * f / (1 + f)
+	}
+	return score
+}
+
+// match returns the mean match score of the terms in the folded name and
+// directory of an item.
+func (s *Scorer) match(name, dir string) float64 {
+	if len(s.terms) == 0 {
+		return noTermScore
+	}
+	var sum float64
+	for _, t := range s.terms {
+		score := matchTerm(t, name, dir)
+		if score == 0 && s.fuzzy {
+			score = matchFuzzy(t, name, dir)
+		}
+		sum += score
+	}
+	return sum / float64(len(s.terms))
+}
+
+// matchTerm returns the match score of the term in the name and directory.
+func matchTerm(term, name, dir string) float64 {
+	stem := strings.TrimSuffix(name, path.Ext(name))
+	switch {
+	case name == term:
+		return exactNameScore
+	case stem == term:
+		return exactStemScore
+	case strings.HasPrefix(name, term):
+		return namePrefixScore
+	case wordPrefix(name, term):
+		return nameWordScore
+	case strings.Contains(name, term):
+		return nameScore
+	case strings.Contains(path.Base(dir), term):
+		return parentScore
+	case strings.Contains(dir, term):
+		return dirScore
+	}
+	// Terms spanning the directory and name, or matched by content.
+	return 0
+}
+
+// matchFuzzy returns the match score of the term fuzzily matching the name
+// or the whole path, scaled by the quality of the match.
+func matchFuzzy(term, name, dir string) float64 {
+	if score, _, ok := fuzzy.Match(term, name); ok {
+		return nameScore * fuzzy.Normalize(score, term)
+	}
+	if score, _, ok := fuzzy.Subsequence(term, dir+name); ok {
+		return dirScore * fuzzy.Normalize(score, term)
+	}
+	return 0
+}
+
+// wordPrefix reports whether the term starts a word of s, after a
+// character that isn't a letter or digit.
+func wordPrefix(s, term string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], term)
+		if j < 0 {
+			return false
+		}
+		i += j
+		if r, _ := utf8.DecodeLastRuneInString(s[:i]); !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return true
+		}
+		_, n := utf8.DecodeRuneInString(s[i:])
+		i += n
+	}
+}
+
+// compare orders results by decreasing score, then by key.
+func compare(a, b Result) int {
+	return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Key, b.Key))
+}
+
+// Top keeps the best results of those added, up to a maximum number, so all
+// matches of a search can be ranked with bounded memory.
+type Top struct {
+	max     int
+	added   int
+	results resultHeap
+}
+
+// NewTop creates a Top keeping at most max results.
+func NewTop(max int) *Top {
+	return &Top{max: max}
+}
+
+// Add adds the result, dropping the worst kept one if the maximum is reached.
+func (t *Top) Add(r Result) {
+	t.added++
+	switch {
+	case t.max <= 0:
+	case len(t.results) < t.max:
+		heap.Push(&t.results, r)
+	case compare(r, t.results[0]) < 0:
+		t.results[0] = r
+		heap.Fix(&t.results, 0)
+	}
+}
+
+// Added returns the number of results added, kept or not.
+func (t *Top) Added() int {
+	return t.added
+}
+
+// Results returns the kept results by decreasing score, then by key.
+func (t *Top) Results() []Result {
+	results := slices.Clone(t.results)
+	slices.SortFunc(results, compare)
+	return results
+}
+
+// resultHeap is a heap of results with the worst one first.
+type resultHeap []Result
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return compare(h[i], h[j]) > 0 }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x any)        { *h = append(*h, x.(Result)) }
+func (h *resultHeap) Pop() any {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
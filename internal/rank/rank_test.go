@@ -0,0 +1,34 @@
+package rank
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/shtirlic/knotidx/internal/store"
+)
+
+func TestTop(t *testing.T) {
+	var all []Result
+	for i := range 1000 {
+		// Scores with ties, ordered by key.
+		all = append(all, Result{SearchResult: store.SearchResult{Key: fmt.Sprintf("k%04d", i)}, Score: float64(rand.IntN(100))})
+	}
+	want := slices.Clone(all)
+	slices.SortFunc(want, compare)
+
+	for _, max := range []int{0, 1, 10, 999, 1000, 2000} {
+		top := NewTop(max)
+		for _, r := range all {
+			top.Add(r)
+		}
+		got := top.Results()
+		if n := min(max, len(all)); !slices.EqualFunc(got, want[:n], func(a, b Result) bool { return a.Key == b.Key && a.Score == b.Score }) {
+			t.Errorf("NewTop(%d): got %d results, want the best %d", max, len(got), n)
+		}
+		if top.Added() != len(all) {
+			t.Errorf("NewTop(%d): Added() = %d, want %d", max, top.Added(), len(all))
+		}
+	}
+}
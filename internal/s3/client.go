@@ -0,0 +1,211 @@
+// Package s3 implements a minimal client for listing objects of S3 compatible
+// object storages with the ListObjectsV2 API.
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors related to client operations.
+var (
+	ErrRequest = errors.New("s3 request error")
+)
+
+// DefaultRegion is the region used when none is configured.
+const DefaultRegion = "us-east-1"
+
+// Client represents an S3 API client.
+type Client struct {
+	Endpoint     string       // Endpoint URL, empty for AWS.
+	Region       string       // Region used for request signing.
+	AccessKey    string       // Access key ID, empty for anonymous requests.
+	SecretKey    string       // Secret access key.
+	SessionToken string       // Session token of temporary credentials.
+	HTTPClient   *http.Client // HTTP client, http.DefaultClient if nil.
+}
+
+// NewClient creates a client for the endpoint, taking missing region and
+// credentials from the AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN environment variables.
+func NewClient(endpoint, region, accessKey, secretKey, sessionToken string) *Client {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = DefaultRegion
+	}
+	if accessKey == "" && secretKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return &Client{
+		Endpoint:     strings.TrimSuffix(endpoint, "/"),
+		Region:       region,
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		SessionToken: sessionToken,
+	}
+}
+
+// Object represents an object of a listing.
+type Object struct {
+	Key          string    // Key of the object.
+	Size         int64     // Size of the object in bytes.
+	ETag         string    // Entity tag without quotes.
+	LastModified time.Time // Last modification time.
+	StorageClass string    // Storage class, e.g. STANDARD.
+}
+
+// ListInput represents the parameters of a ListObjectsV2 request.
+type ListInput struct {
+	Bucket            string // Bucket name.
+	Prefix            string // List only keys starting with the prefix.
+	StartAfter        string // List keys after this key.
+	ContinuationToken string // Token of the next page returned by a previous request.
+	MaxKeys           int    // Maximum number of keys per page, 0 for the server default.
+}
+
+// ListOutput represents a page of a ListObjectsV2 response.
+type ListOutput struct {
+	Objects               []Object // Objects of the page in key order.
+	IsTruncated           bool     // More pages are available.
+	NextContinuationToken string   // Token to request the next page.
+}
+
+// listBucketResult is the XML form of the ListObjectsV2 response.
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+		StorageClass string    `xml:"StorageClass"`
+	} `xml:"Contents"`
+}
+
+// errorResponse is the XML form of an S3 error.
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// ListObjects requests a page of the bucket objects.
+func (c *Client) ListObjects(ctx context.Context, in ListInput) (*ListOutput, error) {
+	q := url.Values{"list-type": {"2"}}
+	if in.Prefix != "" {
+		q.Set("prefix", in.Prefix)
+	}
+	if in.StartAfter != "" {
+		q.Set("start-after", in.StartAfter)
+	}
+	if in.ContinuationToken != "" {
+		q.Set("continuation-token", in.ContinuationToken)
+	}
+	if in.MaxKeys > 0 {
+		q.Set("max-keys", strconv.Itoa(in.MaxKeys))
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, in.Bucket, "", q)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("%w: bad ListObjectsV2 response: %w", ErrRequest, err)
+	}
+	out := &ListOutput{IsTruncated: res.IsTruncated, NextContinuationToken: res.NextContinuationToken}
+	for _, o := range res.Contents {
+		out.Objects = append(out.Objects, Object{
+			Key:          o.Key,
+			Size:         o.Size,
+			ETag:         strings.Trim(o.ETag, `"`),
+			LastModified: o.LastModified,
+			StorageClass: o.StorageClass,
+		})
+	}
+	return out, nil
+}
+
+// ContentType requests the Content-Type header of the object with a HEAD request.
+func (c *Client) ContentType(ctx context.Context, bucket, key string) (string, error) {
+	resp, err := c.do(ctx, http.MethodHead, bucket, key, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("Content-Type"), nil
+}
+
+// do sends a signed request for the bucket and object key and checks the response status.
+func (c *Client) do(ctx context.Context, method, bucket, key string, q url.Values) (*http.Response, error) {
+	u, err := c.url(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = canonicalQuery(q)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.sign(req, time.Now())
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var e errorResponse
+	if body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		return nil, fmt.Errorf("%w: %s %s: %s: %s", ErrRequest, method, u.Path, e.Code, e.Message)
+	}
+	return nil, fmt.Errorf("%w: %s %s: %s", ErrRequest, method, u.Path, resp.Status)
+}
+
+// url returns the URL of the bucket and object key. Custom endpoints use path
+// style URLs, AWS uses virtual hosted style ones.
+func (c *Client) url(bucket, key string) (*url.URL, error) {
+	var u *url.URL
+	if c.Endpoint == "" {
+		u = &url.URL{
+			Scheme: "https",
+			Host:   bucket + ".s3." + c.Region + ".amazonaws.com",
+			Path:   "/" + key,
+		}
+	} else {
+		var err error
+		if u, err = url.Parse(c.Endpoint); err != nil {
+			return nil, fmt.Errorf("%w: bad endpoint %q: %w", ErrRequest, c.Endpoint, err)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	}
+	// The signature requires the RFC 3986 encoding of the path.
+	u.RawPath = uriEncode(u.Path, false)
+	return u, nil
+}
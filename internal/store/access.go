@@ -0,0 +1,104 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// accessPrefix is the reserved key prefix of the item access entries: prefix
+// + item key, access count, last access time and frecency as value.
+const accessPrefix = reservedPrefix + "a\x00"
+
+// FrecencyHalfLife is the time after which the weight of an access halves.
+const FrecencyHalfLife = 30 * 24 * time.Hour
+
+// Access represents the accesses to an item reported by clients.
+type Access struct {
+	Count    int64     // Number of accesses.
+	Last     time.Time // Time of the last access.
+	Frecency float64   // Sum of the access weights at the time of the last access.
+}
+
+// At returns the frecency at the time: the sum of the access weights,
+// halving every FrecencyHalfLife.
+func (a Access) At(t time.Time) float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	age := max(t.Sub(a.Last), 0)
+	return a.Frecency * math.Exp2(-float64(age)/float64(FrecencyHalfLife))
+}
+
+// encode serializes the access entry value.
+func (a Access) encode() []byte {
+	b := binary.AppendUvarint(nil, uint64(a.Count))
+	b = binary.AppendVarint(b, a.Last.UnixNano())
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(a.Frecency))
+}
+
+// decodeAccess deserializes the access entry value.
+func decodeAccess(b []byte) (a Access, err error) {
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return a, fmt.Errorf("bad access entry")
+	}
+	last, m := binary.Varint(b[n:])
+	if m <= 0 || len(b[n+m:]) != 8 {
+		return a, fmt.Errorf("bad access entry")
+	}
+	a.Count = int64(count)
+	a.Last = time.Unix(0, last)
+	a.Frecency = math.Float64frombits(binary.BigEndian.Uint64(b[n+m:]))
+	return a, nil
+}
+
+// accessKey returns the access entry key of the item key.
+func accessKey(key string) []byte {
+	return []byte(accessPrefix + key)
+}
+
+// getAccess returns the access entry of the item key, a zero one if none exists.
+func getAccess(txn *badger.Txn, key string) (Access, error) {
+	item, err := txn.Get(accessKey(key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return Access{}, nil
+	}
+	if err != nil {
+		return Access{}, err
+	}
+	v, err := item.ValueCopy(nil)
+	if err != nil {
+		return Access{}, err
+	}
+	return decodeAccess(v)
+}
+
+// ReportAccess records an access to the item key at the time.
+func (s *BadgerStore) ReportAccess(key string, at time.Time) error {
+	s.Open()
+	return s.db.Update(func(txn *badger.Txn) error {
+		a, err := getAccess(txn, key)
+		if err != nil {
+			return err
+		}
+		a.Frecency = a.At(at) + 1
+		a.Count++
+		a.Last = at
+		return txn.Set(accessKey(key), a.encode())
+	})
+}
+
+// Access returns the accesses to the item key reported by clients.
+func (s *BadgerStore) Access(key string) (a Access) {
+	s.Open()
+	s.db.View(func(txn *badger.Txn) (err error) {
+		a, err = getAccess(txn, key)
+		return
+	})
+	return
+}
@@ -6,9 +6,11 @@ import (
 	"log/slog"
 	"math"
 	"strings"
+	"sync"
 
 	"github.com/dgraph-io/badger/v4"
 	"github.com/dgraph-io/badger/v4/options"
+	"github.com/shtirlic/knotidx/internal/fold"
 )
 
 // BadgerDatabaseType represents the Badger database type.
@@ -19,6 +21,10 @@ type BadgerStore struct {
 	storePath string
 	db        *badger.DB
 	inMemory  bool
+
+	keysMu  sync.Mutex // Protects keys and counted.
+	keys    int        // Number of item keys, kept up to date by Add and Delete once counted.
+	counted bool       // The item keys were counted by Stats.
 }
 
 // Maintenance performs maintenance tasks on the Badger store, such as value log garbage collection.
@@ -54,6 +60,61 @@ func (s *BadgerStore) Info() string {
 	return fmt.Sprintf("Badger Store memory:%v path:%v", s.inMemory, s.storePath)
 }
 
+// Stats returns the approximate number of item keys and the sizes of the
+// Badger store. The item keys are counted by the first call, and then kept
+// up to date by Add and Delete, concurrent updates of a key can be counted
+// twice.
+func (s *BadgerStore) Stats() (stats Stats) {
+	s.Open()
+	stats.LSMSize, stats.VlogSize = s.db.Size()
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	if !s.counted {
+		s.keys, s.counted = s.countKeys(""), true
+	}
+	stats.Keys = s.keys
+	return
+}
+
+// countKeys counts the item keys with the prefix.
+func (s *BadgerStore) countKeys(prefix string) (n int) {
+	s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		// Items sort after the secondary index entries.
+		for it.Seek([]byte(seekKey(prefix))); it.ValidForPrefix([]byte(prefix)); it.Next() {
+			n++
+		}
+		return nil
+	})
+	return
+}
+
+// Count returns the number of item keys with the prefix.
+func (s *BadgerStore) Count(prefix string) int {
+	s.Open()
+	return s.countKeys(prefix)
+}
+
+// addKeys adds n to the number of item keys if they were counted.
+func (s *BadgerStore) addKeys(n int) {
+	s.keysMu.Lock()
+	if s.counted {
+		s.keys += n
+	}
+	s.keysMu.Unlock()
+}
+
+// keysCounted reports whether the item keys were counted.
+func (s *BadgerStore) keysCounted() bool {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	return s.counted
+}
+
 // Open opens the Badger store.
 func (s *BadgerStore) Open() (err error) {
 	if s.db != nil {
@@ -79,10 +140,67 @@ func (s *BadgerStore) Open() (err error) {
 	if err != nil {
 		err = errors.Join(ErrOpenStore, err)
 		slog.Debug("error while opening store", "store", s, "error", err)
+		return
+	}
+
+	// Build the trigram index for stores created before it existed.
+	if err = s.buildTrigramIndex(); err != nil {
+		err = errors.Join(ErrOpenStore, err)
+		slog.Debug("error while building trigram index", "store", s, "error", err)
 	}
 	return
 }
 
+// buildTrigramIndex (re)builds the trigram index if its version differs from the current one.
+func (s *BadgerStore) buildTrigramIndex() error {
+	var version string
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(trigramVersionKey))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v, err := item.ValueCopy(nil)
+		version = string(v)
+		return err
+	})
+	if err != nil || version == trigramVersion {
+		return err
+	}
+
+	slog.Info("Building trigram index", "store", s.Info())
+	if err = s.db.DropPrefix([]byte(trigramPrefix), []byte(foldedPrefix)); err != nil {
+		return err
+	}
+
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+	err = s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek([]byte(seekKey(""))); it.Valid(); it.Next() {
+			key := string(it.Item().Key())
+			for _, k := range trigramKeys(key) {
+				if err := wb.Set(k, nil); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err = wb.Set([]byte(trigramVersionKey), []byte(trigramVersion)); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
+
 // Close closes the Badger store.
 func (s *BadgerStore) Close() (err error) {
 
@@ -91,6 +209,9 @@ func (s *BadgerStore) Close() (err error) {
 	}
 	slog.Debug("Closing store", "store", s)
 
+	s.keysMu.Lock()
+	s.keys, s.counted = 0, false
+	s.keysMu.Unlock()
 	err = s.db.Close()
 	if err != nil {
 		slog.Debug("error while closing store", "store", s, "error", err)
@@ -107,25 +228,50 @@ func (s *BadgerStore) Reset() (err error) {
 		slog.Debug("error while reseting store", "store", s, "error", err)
 		return
 	}
+	s.keysMu.Lock()
+	s.keys = 0
+	s.keysMu.Unlock()
+	// The empty store has an up to date trigram index.
+	err = s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(trigramVersionKey), []byte(trigramVersion))
+	})
 	return
 }
 
 // Delete deletes an item from the Badger store based on the key.
 func (s *BadgerStore) Delete(key string) (err error) {
 	s.Open()
-	txn := s.db.NewTransaction(true)
-	defer txn.Discard()
-
-	// Delete the item using the transaction.
-	err = txn.Delete([]byte(key))
+	var found bool
+	var terms []string
+	err = s.db.View(func(txn *badger.Txn) (err error) {
+		_, err = txn.Get([]byte(key))
+		found = err == nil
+		_, terms, err = contentDoc(txn, key)
+		return
+	})
 	if err != nil {
 		return
 	}
 
-	// Commit the transaction.
-	if err = txn.Commit(); err != nil {
+	// Delete the item, its access entry, its trigram and its full-text index
+	// entries in a batch, which splits the transactions of long keys.
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+	keys := append(trigramKeys(key), []byte(key), accessKey(key), contentDocKey(key))
+	for _, t := range terms {
+		keys = append(keys, contentKey(t, key))
+	}
+	for _, k := range keys {
+		if err = wb.Delete(k); err != nil {
+			return
+		}
+	}
+	if err = wb.Flush(); err != nil {
 		return
 	}
+	if found {
+		s.addKeys(-1)
+	}
 
 	slog.Debug("Store Delete", "key", key)
 	return nil
@@ -135,16 +281,11 @@ func (s *BadgerStore) Delete(key string) (err error) {
 func (s *BadgerStore) Find(key string) (item ItemInfo) {
 	s.Open()
 	s.db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
-		defer it.Close()
-		prefix := []byte(key)
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			ib := it.Item()
-			if string(ib.Key()) == key {
-				item = Item(ib)
-				break
-			}
+		i, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
 		}
+		item = Item(i)
 		return nil
 	})
 	return
@@ -152,42 +293,173 @@ func (s *BadgerStore) Find(key string) (item ItemInfo) {
 
 // Keys retrieves keys from the Badger store based on the prefix, pattern, and limit.
 func (s *BadgerStore) Keys(prefix string, pattern string, limit int) (keys []string) {
+	s.scan(SearchOptions{Prefix: prefix, Literals: []string{pattern}, Limit: limit}, func(_ *badger.Txn, key string, _ func() ItemInfo) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return
+}
+
+// Children retrieves the keys with the prefix that have no slash after it,
+// e.g. the keys of the entries of a directory. The keys of deeper entries are
+// skipped by seeking past their subtree instead of iterating over it.
+func (s *BadgerStore) Children(prefix string) (keys []string) {
+	s.Open()
+	s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek([]byte(seekKey(prefix))); it.ValidForPrefix([]byte(prefix)); {
+			key := string(it.Item().Key())
+			if i := strings.IndexByte(key[len(prefix):], '/'); i >= 0 {
+				// '0' follows '/', so all the keys of the subtree sort before it.
+				it.Seek([]byte(key[:len(prefix)+i] + "0"))
+				continue
+			}
+			keys = append(keys, key)
+			it.Next()
+		}
+		return nil
+	})
+	return
+}
+
+// Search retrieves the items matching the search options.
+func (s *BadgerStore) Search(so SearchOptions) (results []SearchResult) {
+	s.Each(so, func(r SearchResult) bool {
+		results = append(results, r)
+		return true
+	})
+	return
+}
+
+// Each calls fn in key order for every item matching the search options
+// without buffering the results. Iteration stops when fn returns false.
+func (s *BadgerStore) Each(so SearchOptions, fn func(SearchResult) bool) {
+	s.scan(so, func(txn *badger.Txn, key string, item func() ItemInfo) bool {
+		r := SearchResult{Key: key, Item: item()}
+		if so.Access {
+			var err error
+			if r.Access, err = getAccess(txn, key); err != nil {
+				slog.Error("Store Each access", "key", key, "error", err)
+			}
+		}
+		return fn(r)
+	})
+}
+
+// scan calls fn for every key matching the search options, up to the limit.
+// If the literals are long enough or content terms are set, candidate keys are
+// taken from the trigram and content indexes instead of scanning all keys. Item values are decoded on demand by
+// the item function passed to fn. Iteration stops when fn returns false.
+func (s *BadgerStore) scan(so SearchOptions, fn func(txn *badger.Txn, key string, item func() ItemInfo) bool) {
+	limit := so.Limit
 	if limit == 0 {
 		limit = math.MaxInt
 	}
+
 	s.db.View(func(txn *badger.Txn) error {
+		// match checks the literals and the match function, decoding the item once on demand.
+		// It reports whether the search should continue.
+		literals := so.Literals
+		if so.Fold {
+			literals = make([]string, len(so.Literals))
+			for i, l := range so.Literals {
+				literals[i] = fold.String(l, fold.All)
+			}
+		}
+		match := func(key string, get func() (*badger.Item, error)) bool {
+			folded := keyPath(key)
+			if so.Fold && len(literals) > 0 {
+				folded = fold.String(folded, fold.All)
+			}
+			for _, l := range literals {
+				if !strings.Contains(folded, l) {
+					return true
+				}
+			}
+			var info *ItemInfo
+			item := func() ItemInfo {
+				if info == nil {
+					info = &ItemInfo{}
+					if i, err := get(); err == nil {
+						*info = Item(i)
+					}
+				}
+				return *info
+			}
+			if so.Match != nil && !so.Match(key, item()) {
+				return true
+			}
+			limit--
+			return fn(txn, key, item) && limit > 0
+		}
+
+		// Use the trigram and content indexes to find candidate keys.
+		if postings := postingPrefixes(literals, so.Fold, so.Terms); len(postings) > 0 {
+			intersectPostings(txn, postings, so.Prefix, startKey(so.Prefix, so.After), func(key string) bool {
+				return match(key, func() (*badger.Item, error) {
+					return txn.Get([]byte(key))
+				})
+			})
+			return nil
+		}
+
+		// Scan all keys with the prefix.
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchValues = false
 		it := txn.NewIterator(opts)
 		defer it.Close()
-		for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)) && limit > 0; it.Next() {
+		prefix := []byte(so.Prefix)
+		for it.Seek([]byte(startKey(seekKey(so.Prefix), so.After))); it.ValidForPrefix(prefix); it.Next() {
 			item := it.Item()
-			if strings.Contains(string(item.Key()), pattern) {
-				keys = append(keys, string(item.Key()))
-				limit--
+			if !match(string(item.Key()), func() (*badger.Item, error) { return item, nil }) {
+				break
 			}
 		}
 		return nil
 	})
-	return
 }
 
-// Add adds or updates items in the Badger store.
-// If the transaction becomes too big, it is committed, and a new transaction is started.
+// Add adds or updates items and their trigram index entries in the Badger store.
+// The write batch commits intermediate transactions when they become too big.
 func (s *BadgerStore) Add(updates map[string]ItemInfo) (err error) {
 	s.Open()
-	txn := s.db.NewTransaction(true)
-	defer txn.Discard()
+	added := 0
+	if s.keysCounted() {
+		added = s.newKeys(updates)
+	}
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
 	for k, v := range updates {
-		// Set the key-value pair in the transaction.
-		if err := txn.Set([]byte(k), v.Encode()); errors.Is(err, badger.ErrTxnTooBig) {
-			// If the transaction becomes too big, commit it and start a new transaction.
-			_ = txn.Commit()
-			txn = s.db.NewTransaction(true)
-			_ = txn.Set([]byte(k), v.Encode())
+		// Set the key-value pair in the batch.
+		if err = wb.Set([]byte(k), v.Encode()); err != nil {
+			return
+		}
+		for _, tk := range trigramKeys(k) {
+			if err = wb.Set(tk, nil); err != nil {
+				return
+			}
 		}
 	}
-	err = txn.Commit()
+	if err = wb.Flush(); err != nil {
+		return
+	}
+	s.addKeys(added)
+	return
+}
+
+// newKeys returns the number of keys of the updates missing from the store.
+func (s *BadgerStore) newKeys(updates map[string]ItemInfo) (n int) {
+	s.db.View(func(txn *badger.Txn) error {
+		for k := range updates {
+			if _, err := txn.Get([]byte(k)); errors.Is(err, badger.ErrKeyNotFound) {
+				n++
+			}
+		}
+		return nil
+	})
 	return
 }
 
@@ -199,7 +471,7 @@ func (s *BadgerStore) Items() (items []*ItemInfo, err error) {
 		opts.PrefetchSize = 10
 		it := txn.NewIterator(opts)
 		defer it.Close()
-		for it.Rewind(); it.Valid(); it.Next() {
+		for it.Seek([]byte(seekKey(""))); it.Valid(); it.Next() {
 			i := it.Item()
 			storeItem := Item(i)
 			items = append(items, &storeItem)
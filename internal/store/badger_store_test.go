@@ -0,0 +1,426 @@
+package store
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// newTestStore returns an open in-memory store closed at the end of the test.
+func newTestStore(tb testing.TB) *BadgerStore {
+	tb.Helper()
+	s := NewInMemoryBadgerStore().(*BadgerStore)
+	if err := s.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { s.Close() })
+	return s
+}
+
+// reservedKeys returns the secondary index entry keys with the prefix.
+func reservedKeys(t *testing.T, s *BadgerStore, prefix string) (keys []string) {
+	t.Helper()
+	s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			keys = append(keys, string(it.Item().KeyCopy(nil)))
+		}
+		return nil
+	})
+	return
+}
+
+// postingKeys returns the trigram and folded trigram posting list entries.
+func postingKeys(t *testing.T, s *BadgerStore) []string {
+	keys := append(reservedKeys(t, s, trigramPrefix), reservedKeys(t, s, foldedPrefix)...)
+	slices.Sort(keys)
+	return keys
+}
+
+// wantPostings returns the posting list entries of the item keys.
+func wantPostings(keys ...string) (want []string) {
+	for _, key := range keys {
+		for _, k := range trigramKeys(key) {
+			want = append(want, string(k))
+		}
+	}
+	slices.Sort(want)
+	return slices.Compact(want)
+}
+
+// item returns an item of the path.
+func item(path string) ItemInfo {
+	return NewItemInfo(path[strings.LastIndex(path, "/")+1:], path, time.Unix(0, 0), 1, "file")
+}
+
+func TestTrigramKeys(t *testing.T) {
+	for _, tt := range []struct {
+		key          string
+		plain, folds []string
+	}{
+		{"fs_file_/a/bc", []string{"/a/", "/bc", "a/b"}, nil},
+		{"fs_file_/Ab", []string{"/Ab"}, []string{"/ab"}},
+		{"git_branch_main", []string{"ain", "mai"}, nil},
+		{"nokey", []string{"key", "nok", "oke"}, nil},
+	} {
+		var plain, folds []string
+		for _, k := range trigramKeys(tt.key) {
+			k := string(k)
+			if tri, ok := strings.CutPrefix(k, trigramPrefix); ok {
+				plain = append(plain, strings.TrimSuffix(tri, tt.key))
+			} else if tri, ok := strings.CutPrefix(k, foldedPrefix); ok {
+				folds = append(folds, strings.TrimSuffix(tri, tt.key))
+			}
+		}
+		if !slices.Equal(plain, tt.plain) || !slices.Equal(folds, tt.folds) {
+			t.Errorf("trigramKeys(%q) = %q and folded %q, want %q and folded %q", tt.key, plain, folds, tt.plain, tt.folds)
+		}
+	}
+}
+
+func TestAddDeletePostings(t *testing.T) {
+	s := newTestStore(t)
+	keys := []string{"fs_file_/docs/Résumé.txt", "fs_file_/docs/notes.md"}
+	if err := s.Add(map[string]ItemInfo{keys[0]: item("/docs/Résumé.txt"), keys[1]: item("/docs/notes.md")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := postingKeys(t, s), wantPostings(keys...); !slices.Equal(got, want) {
+		t.Fatalf("postings after Add:\n got %q\nwant %q", got, want)
+	}
+
+	// Adding the item again with changed values keeps its postings.
+	changed := item("/docs/notes.md")
+	changed.Size = 2
+	if err := s.Add(map[string]ItemInfo{keys[1]: changed}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := postingKeys(t, s), wantPostings(keys...); !slices.Equal(got, want) {
+		t.Fatalf("postings after re-Add:\n got %q\nwant %q", got, want)
+	}
+
+	// Renaming the item deletes the postings of its old key.
+	renamed := "fs_file_/docs/todo.md"
+	if err := s.Delete(keys[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(map[string]ItemInfo{renamed: item("/docs/todo.md")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := postingKeys(t, s), wantPostings(keys[0], renamed); !slices.Equal(got, want) {
+		t.Fatalf("postings after rename:\n got %q\nwant %q", got, want)
+	}
+	if got := s.Keys("", "notes", 0); len(got) != 0 {
+		t.Errorf("Keys(notes) after rename = %q, want none", got)
+	}
+	if got := s.Keys("", "todo", 0); !slices.Equal(got, []string{renamed}) {
+		t.Errorf("Keys(todo) after rename = %q, want %q", got, renamed)
+	}
+
+	// Deleting all items leaves no postings.
+	for _, key := range []string{keys[0], renamed} {
+		if err := s.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := postingKeys(t, s); len(got) != 0 {
+		t.Errorf("postings after Delete = %q, want none", got)
+	}
+}
+
+func TestDeleteLongKey(t *testing.T) {
+	s := newTestStore(t)
+	// The postings of a long path with many distinct trigrams exceed the size
+	// of a single transaction.
+	letters := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	var b strings.Builder
+	for i := range 8000 {
+		b.WriteByte(letters[(i*i+i/7)%len(letters)])
+	}
+	path := "/" + b.String()
+	key := "fs_file_" + path
+	if err := s.Add(map[string]ItemInfo{key: item(path)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetContent(key, "h", []string{"alpha", "beta"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if s.Find(key).Path != "" {
+		t.Error("item found after Delete")
+	}
+	if got := postingKeys(t, s); len(got) != 0 {
+		t.Errorf("%d postings after Delete, want none", len(got))
+	}
+	for _, prefix := range []string{contentPrefix, contentDocPrefix} {
+		if got := reservedKeys(t, s, prefix); len(got) != 0 {
+			t.Errorf("content entries %q after Delete, want none", got)
+		}
+	}
+}
+
+func TestStatsKeys(t *testing.T) {
+	s := newTestStore(t)
+	add := func(paths ...string) {
+		t.Helper()
+		items := make(map[string]ItemInfo)
+		for _, p := range paths {
+			items["fs_file_"+p] = item(p)
+		}
+		if err := s.Add(items); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(step string, want int) {
+		t.Helper()
+		if got := s.Stats().Keys; got != want {
+			t.Errorf("%s: got %d keys, want %d", step, got, want)
+		}
+		if n := s.countKeys(""); n != want {
+			t.Errorf("%s: counted %d keys, want %d", step, n, want)
+		}
+	}
+
+	// Items added before the first count are counted.
+	add("/a", "/b")
+	check("first count", 2)
+	add("/b", "/c")
+	check("add and update", 3)
+	if err := s.Delete("fs_file_/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("fs_file_/missing"); err != nil {
+		t.Fatal(err)
+	}
+	check("delete", 2)
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	check("reset", 0)
+	add("/d")
+	check("add after reset", 1)
+}
+
+func TestChildrenCount(t *testing.T) {
+	s := newTestStore(t)
+	items := make(map[string]ItemInfo)
+	for _, p := range []string{"/d/a", "/d/b/x", "/d/b/y/z", "/d/b.txt", "/d/c", "/d0/e", "/e"} {
+		items["fs_file_"+p] = item(p)
+	}
+	if err := s.Add(items); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"fs_file_/d/a", "fs_file_/d/b.txt", "fs_file_/d/c"}
+	if got := s.Children("fs_file_/d/"); !slices.Equal(got, want) {
+		t.Errorf("children: got %v, want %v", got, want)
+	}
+	if n := s.Count("fs_file_/d/"); n != 5 {
+		t.Errorf("count: got %d, want 5", n)
+	}
+	if n := s.Count(""); n != len(items) {
+		t.Errorf("count all: got %d, want %d", n, len(items))
+	}
+}
+
+func TestState(t *testing.T) {
+	s := newTestStore(t)
+	if v := s.State("fs_filters_/a"); v != nil {
+		t.Errorf("missing state: got %q, want nil", v)
+	}
+	if err := s.SetState("fs_filters_/a", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.State("fs_filters_/a"); string(v) != "x" {
+		t.Errorf("got state %q, want x", v)
+	}
+	if keys := s.Keys("", "", 0); len(keys) != 0 {
+		t.Errorf("state listed as item keys %v", keys)
+	}
+	if err := s.SetState("fs_filters_/a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := s.State("fs_filters_/a"); v != nil {
+		t.Errorf("deleted state: got %q, want nil", v)
+	}
+}
+
+func TestEachAccess(t *testing.T) {
+	s := newTestStore(t)
+	keys := []string{"fs_file_/a", "fs_file_/b"}
+	if err := s.Add(map[string]ItemInfo{keys[0]: item("/a"), keys[1]: item("/b")}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Unix(100, 0)
+	if err := s.ReportAccess(keys[1], at); err != nil {
+		t.Fatal(err)
+	}
+	for _, read := range []bool{false, true} {
+		got := make(map[string]Access)
+		s.Each(SearchOptions{Access: read}, func(r SearchResult) bool {
+			got[r.Key] = r.Access
+			return true
+		})
+		want := Access{}
+		if read {
+			want = Access{Count: 1, Last: at, Frecency: 1}
+		}
+		if a := got[keys[1]]; a.Count != want.Count || !a.Last.Equal(want.Last) || a.Frecency != want.Frecency {
+			t.Errorf("Each(Access %v) access of %s = %+v, want %+v", read, keys[1], a, want)
+		}
+		if a := got[keys[0]]; a.Count != 0 {
+			t.Errorf("Each(Access %v) access of %s = %+v, want none", read, keys[0], a)
+		}
+	}
+}
+
+func TestSearchFold(t *testing.T) {
+	s := newTestStore(t)
+	key := "fs_file_/docs/Résumé.txt"
+	if err := s.Add(map[string]ItemInfo{key: item("/docs/Résumé.txt")}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		literal string
+		fold    bool
+		want    int
+	}{
+		{"Résumé", false, 1},
+		{"resume", false, 0},
+		{"resume", true, 1},
+		{"RESUME", true, 1},
+		{"docs/r", true, 1},
+		{"DOCS", true, 1},
+
+		// Only the path part of the key is matched.
+		{"file_/docs", false, 0},
+		{"FILE_/DOCS", true, 0},
+	} {
+		got := s.Search(SearchOptions{Literals: []string{tt.literal}, Fold: tt.fold})
+		if len(got) != tt.want {
+			t.Errorf("Search(%q, fold %v) = %d items, want %d", tt.literal, tt.fold, len(got), tt.want)
+		}
+	}
+}
+
+func TestIntersectPostings(t *testing.T) {
+	s := newTestStore(t)
+	paths := []string{"/a/abcdef", "/a/abcxyz", "/a/xyzdef", "/b/abcdef", "/b/defabc"}
+	items := make(map[string]ItemInfo)
+	for _, p := range paths {
+		items["fs_file_"+p] = item(p)
+	}
+	if err := s.Add(items); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		literals []string
+		prefix   string
+		after    string
+		limit    int
+		want     []string
+	}{
+		{"single", []string{"xyz"}, "", "", 0, []string{"/a/abcxyz", "/a/xyzdef"}},
+		{"all", []string{"abc", "def"}, "", "", 0, []string{"/a/abcdef", "/b/abcdef", "/b/defabc"}},
+		{"adjacent", []string{"cde"}, "", "", 0, []string{"/a/abcdef", "/b/abcdef"}},
+		{"prefix", []string{"abc", "def"}, "fs_file_/b/", "", 0, []string{"/b/abcdef", "/b/defabc"}},
+		{"after", []string{"abc", "def"}, "", "fs_file_/a/abcdef", 0, []string{"/b/abcdef", "/b/defabc"}},
+		{"stop", []string{"abc"}, "", "", 2, []string{"/a/abcdef", "/a/abcxyz"}},
+		{"none", []string{"abc", "zzz"}, "", "", 0, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			s.db.View(func(txn *badger.Txn) error {
+				intersectPostings(txn, postingPrefixes(tt.literals, false, nil), tt.prefix, startKey(tt.prefix, tt.after), func(key string) bool {
+					got = append(got, strings.TrimPrefix(key, "fs_file_"))
+					return tt.limit == 0 || len(got) < tt.limit
+				})
+				return nil
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// benchItems is the number of items of the benchmark store. The default one
+// takes several GiB of memory, lower it with -benchitems on smaller machines.
+var benchItems = flag.Int("benchitems", 1_000_000, "number of items of the benchmark store")
+
+var (
+	benchOnce  sync.Once
+	benchStore *BadgerStore
+)
+
+// newBenchStore returns an in-memory store of benchItems items shared by the benchmarks.
+func newBenchStore(b *testing.B) *BadgerStore {
+	benchOnce.Do(func() {
+		s := NewInMemoryBadgerStore().(*BadgerStore)
+		if err := s.Open(); err != nil {
+			b.Fatal(err)
+		}
+		items := make(map[string]ItemInfo)
+		for i := range *benchItems {
+			path := fmt.Sprintf("/home/%03d/file%07d.txt", i%1000, i)
+			items["fs_file_"+path] = item(path)
+			if len(items) == 10*BatchCount || i == *benchItems-1 {
+				if err := s.Add(items); err != nil {
+					b.Fatal(err)
+				}
+				clear(items)
+			}
+		}
+		benchStore = s
+	})
+	return benchStore
+}
+
+// BenchmarkKeys compares a substring search via the trigram index with a full scan.
+func BenchmarkKeys(b *testing.B) {
+	s := newBenchStore(b)
+	literal := "file0123456"
+	b.ResetTimer()
+
+	b.Run("trigram", func(b *testing.B) {
+		for b.Loop() {
+			if n := len(s.Keys("", literal, 0)); n != 1 {
+				b.Fatalf("found %d keys, want 1", n)
+			}
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		// Check every item key for the literal, as Keys did without the trigram index.
+		for b.Loop() {
+			var keys []string
+			s.db.View(func(txn *badger.Txn) error {
+				opts := badger.DefaultIteratorOptions
+				opts.PrefetchValues = false
+				it := txn.NewIterator(opts)
+				defer it.Close()
+				for it.Seek([]byte(seekKey(""))); it.Valid(); it.Next() {
+					if key := string(it.Item().Key()); strings.Contains(key, literal) {
+						keys = append(keys, key)
+					}
+				}
+				return nil
+			})
+			if len(keys) != 1 {
+				b.Fatalf("found %d keys, want 1", len(keys))
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// Reserved key prefixes of the full-text index.
+const (
+	contentPrefix    = reservedPrefix + "c\x00" // content posting list entries: prefix + term + "\x00" + item key, term positions as value
+	contentDocPrefix = reservedPrefix + "d\x00" // content document entries: prefix + item key, item hash and terms as value
+)
+
+// contentTermPrefix returns the prefix of the posting list entries of the term.
+func contentTermPrefix(term string) string {
+	return contentPrefix + term + "\x00"
+}
+
+// contentKey returns the posting list entry key of the item key for the term.
+func contentKey(term string, key string) []byte {
+	return []byte(contentTermPrefix(term) + key)
+}
+
+// contentDocKey returns the content document entry key of the item key.
+func contentDocKey(key string) []byte {
+	return []byte(contentDocPrefix + key)
+}
+
+// encodePositions encodes the ascending term positions as varint deltas.
+func encodePositions(positions []int) []byte {
+	var b []byte
+	last := 0
+	for _, p := range positions {
+		b = binary.AppendUvarint(b, uint64(p-last))
+		last = p
+	}
+	return b
+}
+
+// decodePositions decodes the varint deltas of the term positions.
+func decodePositions(b []byte) []int {
+	var positions []int
+	last := 0
+	for len(b) > 0 {
+		d, n := binary.Uvarint(b)
+		if n <= 0 {
+			break
+		}
+		last += int(d)
+		positions = append(positions, last)
+		b = b[n:]
+	}
+	return positions
+}
+
+// contentDoc returns the item hash and the distinct terms of the content
+// document entry of the item key.
+func contentDoc(txn *badger.Txn, key string) (hash string, terms []string, err error) {
+	item, err := txn.Get(contentDocKey(key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	v, err := item.ValueCopy(nil)
+	if err != nil {
+		return "", nil, err
+	}
+	hash, list, _ := strings.Cut(string(v), "\x00")
+	if list != "" {
+		terms = strings.Split(list, "\x00")
+	}
+	return hash, terms, nil
+}
+
+// SetContent replaces the full-text index entries of the item key with the
+// positions of the terms, recording the hash of the indexed item version.
+// Empty terms remove the entries.
+func (s *BadgerStore) SetContent(key string, hash string, terms []string) error {
+	s.Open()
+	var old []string
+	err := s.db.View(func(txn *badger.Txn) (err error) {
+		_, old, err = contentDoc(txn, key)
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	positions := make(map[string][]int)
+	for i, t := range terms {
+		positions[t] = append(positions[t], i)
+	}
+
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, t := range old {
+		if _, ok := positions[t]; !ok {
+			if err := wb.Delete(contentKey(t, key)); err != nil {
+				return err
+			}
+		}
+	}
+	if len(positions) == 0 {
+		if err := wb.Delete(contentDocKey(key)); err != nil {
+			return err
+		}
+		return wb.Flush()
+	}
+	distinct := make([]string, 0, len(positions))
+	for t, p := range positions {
+		if err := wb.Set(contentKey(t, key), encodePositions(p)); err != nil {
+			return err
+		}
+		distinct = append(distinct, t)
+	}
+	slices.Sort(distinct)
+	if err := wb.Set(contentDocKey(key), []byte(hash+"\x00"+strings.Join(distinct, "\x00"))); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
+
+// ContentHash returns the hash of the item version whose content is indexed
+// under the key, or an empty string if none is.
+func (s *BadgerStore) ContentHash(key string) (hash string) {
+	s.Open()
+	s.db.View(func(txn *badger.Txn) (err error) {
+		hash, _, err = contentDoc(txn, key)
+		return
+	})
+	return
+}
+
+// MatchPhrase reports whether the indexed content of the item key contains
+// the terms at consecutive positions.
+func (s *BadgerStore) MatchPhrase(key string, terms []string) (ok bool) {
+	if len(terms) == 0 {
+		return false
+	}
+	s.Open()
+	s.db.View(func(txn *badger.Txn) error {
+		positions := make([][]int, len(terms))
+		for i, t := range terms {
+			item, err := txn.Get(contentKey(t, key))
+			if err != nil {
+				return nil
+			}
+			if err = item.Value(func(v []byte) error {
+				positions[i] = decodePositions(v)
+				return nil
+			}); err != nil {
+				return nil
+			}
+		}
+		for _, p := range positions[0] {
+			ok = true
+			for i := 1; i < len(terms) && ok; i++ {
+				_, ok = slices.BinarySearch(positions[i], p+i)
+			}
+			if ok {
+				return nil
+			}
+		}
+		return nil
+	})
+	return
+}
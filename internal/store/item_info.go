@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -23,8 +25,35 @@ type ItemInfo struct {
 	ModTime  time.Time // Modification time of the item.
 	Size     int64     // Size of the item.
 	Hash     string    // Hash of the item.
+
+	Meta   map[string]string // Indexer specific metadata, e.g. commit author.
+	XAttrs map[string]string // Extended user attributes, e.g. user.xdg.tags.
+	Media  *MediaInfo        // Metadata extracted from images, audio, e-books and videos.
+}
+
+// MediaInfo represents metadata extracted from the content of an item.
+type MediaInfo struct {
+	Title     string        // Title of the work.
+	Artist    string        // Artist of audio and video.
+	Album     string        // Album of audio.
+	Genre     string        // Genre of audio and video.
+	Author    string        // Author of e-books.
+	Publisher string        // Publisher of e-books.
+	Language  string        // Language of e-books.
+	Camera    string        // Camera make and model of images.
+	Year      int           // Year of creation or release.
+	Width     int           // Width of images and videos in pixels.
+	Height    int           // Height of images and videos in pixels.
+	Duration  time.Duration // Duration of audio and video.
 }
 
+// Well known extended user attributes.
+const (
+	XAttrTags    = "user.xdg.tags"       // Comma separated list of tags.
+	XAttrComment = "user.xdg.comment"    // Comment.
+	XAttrOrigin  = "user.xdg.origin.url" // URL the file was downloaded from.
+)
+
 // NewItemInfo creates a new ItemInfo with the specified attributes.
 func NewItemInfo(name string, path string, modTime time.Time, size int64, t ItemType) (i ItemInfo) {
 	i = ItemInfo{Name: name, Path: path, ModTime: modTime, Size: size, Type: t}
@@ -39,13 +68,34 @@ func (o *ItemInfo) XXhash() string {
 // String converts the item information to a string for hashing purposes.
 func (o *ItemInfo) String() string {
 
-	return strings.Join([]string{
+	fields := []string{
 		o.Path,
 		string(o.Type),
 		o.MimeType,
 		o.ModTime.String(),
 		strconv.FormatInt(o.Size, 10),
-	}, ":")
+	}
+	// Metadata in key order, items without metadata keep their hashes.
+	for _, k := range slices.Sorted(maps.Keys(o.Meta)) {
+		fields = append(fields, k+"="+o.Meta[k])
+	}
+	for _, k := range slices.Sorted(maps.Keys(o.XAttrs)) {
+		fields = append(fields, k+"="+o.XAttrs[k])
+	}
+	if o.Media != nil {
+		fields = append(fields, fmt.Sprintf("%+v", *o.Media))
+	}
+	return strings.Join(fields, ":")
+}
+
+// Tags returns the tags of the user.xdg.tags extended attribute.
+func (o *ItemInfo) Tags() (tags []string) {
+	for _, t := range strings.Split(o.XAttrs[XAttrTags], ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return
 }
 
 // KeyName generates a key name for the item.
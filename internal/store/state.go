@@ -0,0 +1,32 @@
+package store
+
+import "github.com/dgraph-io/badger/v4"
+
+// statePrefix is the reserved key prefix of the state entries saved by the
+// indexers between runs: prefix + state name, state as value.
+const statePrefix = reservedPrefix + "s\x00"
+
+// State returns the state saved under the name, nil if none.
+func (s *BadgerStore) State(name string) (value []byte) {
+	s.Open()
+	s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(statePrefix + name))
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	return
+}
+
+// SetState saves the state under the name, deleting it if the value is nil.
+func (s *BadgerStore) SetState(name string, value []byte) error {
+	s.Open()
+	return s.db.Update(func(txn *badger.Txn) error {
+		if value == nil {
+			return txn.Delete([]byte(statePrefix + name))
+		}
+		return txn.Set([]byte(statePrefix+name), value)
+	})
+}
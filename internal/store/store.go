@@ -3,6 +3,7 @@ package store
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/shtirlic/knotidx/internal/config"
 )
@@ -21,14 +22,58 @@ type Store interface {
 	Delete(key string) error                                // Delete a key from the store.
 	Find(key string) ItemInfo                               // Find information about a key in the store.
 	Info() string                                           // Get information about the store.
+	Stats() Stats                                           // Get the statistics of the store.
 	Maintenance()                                           // Perform maintenance tasks on the store.
 	Type() DatabaseType                                     // Get the type of the database.
 	Keys(prefix string, pattern string, limit int) []string // Get keys based on prefix, pattern, and limit.
+	Children(prefix string) []string                        // Get the keys with the prefix and no slash after it.
+	Count(prefix string) int                                // Count the keys with the prefix.
+	Search(opts SearchOptions) []SearchResult               // Get the items matching the search options.
+	Each(opts SearchOptions, fn func(SearchResult) bool)    // Iterate over the items matching the search options.
+
+	SetContent(key string, hash string, terms []string) error // Replace the full-text index entries of an item.
+	ContentHash(key string) string                            // Get the hash of the item version whose content is indexed.
+	MatchPhrase(key string, terms []string) bool              // Check whether the item content contains the terms in order.
+
+	ReportAccess(key string, at time.Time) error // Record an access to an item reported by a client.
+	Access(key string) Access                    // Get the accesses to an item reported by clients.
+
+	State(name string) []byte                 // Get the state saved by an indexer between runs.
+	SetState(name string, value []byte) error // Save the state of an indexer, nil deletes it.
 
 	Add(map[string]ItemInfo) error // Add items to the store.
 	Items() ([]*ItemInfo, error)   // Get all items from the store. // DEBUG func
 }
 
+// Stats represents the statistics of a store.
+type Stats struct {
+	Keys     int   // Approximate number of item keys, secondary index entries excluded.
+	LSMSize  int64 // Size in bytes of the LSM tree on disk.
+	VlogSize int64 // Size in bytes of the value log on disk.
+}
+
+// MatchFunc reports whether the item stored under the key matches a search.
+type MatchFunc func(key string, item ItemInfo) bool
+
+// SearchOptions represents the options of a store search.
+type SearchOptions struct {
+	Prefix   string    // Prefix of the keys to search.
+	Literals []string  // Substrings the path of every matching key must contain, used to narrow the search.
+	Fold     bool      // Literals match the keys case- and accent-insensitively.
+	Terms    []string  // Content terms every matching item must contain, used to narrow the search.
+	Match    MatchFunc // Match filters the items, nil matches all items.
+	Limit    int       // Maximum number of keys to return, 0 means no limit.
+	After    string    // Resume the search after this key, used for pagination.
+	Access   bool      // Read the accesses of the matching items with them.
+}
+
+// SearchResult represents an item found by a store search.
+type SearchResult struct {
+	Key    string   // Key of the item in the store.
+	Item   ItemInfo // Information about the item.
+	Access Access   // Accesses to the item, if read by the search.
+}
+
 // BatchCount specifies the batch count for store operations.
 const BatchCount int = 100
 
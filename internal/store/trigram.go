@@ -0,0 +1,181 @@
+package store
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/shtirlic/knotidx/internal/fold"
+)
+
+// Reserved key prefixes of the secondary indexes. Item keys never start with
+// the reservedPrefix byte, so secondary index entries sort before all items.
+const (
+	reservedPrefix     = "\x00"
+	trigramPrefix      = reservedPrefix + "t\x00" // trigram posting list entries: prefix + trigram + item key
+	foldedPrefix       = reservedPrefix + "f\x00" // folded trigram posting list entries: prefix + trigram of the folded key path + item key
+	metaPrefix         = reservedPrefix + "m\x00" // store metadata entries
+	trigramVersionKey  = metaPrefix + "trigram"   // version of the trigram index
+	trigramVersion     = "3"
+	trigramLength      = 3
+	firstItemKeyPrefix = "\x01" // first possible item key
+)
+
+// isReservedKey reports whether the key belongs to a secondary index.
+func isReservedKey(key []byte) bool {
+	return len(key) > 0 && key[0] == reservedPrefix[0]
+}
+
+// seekKey returns the key to start an item iteration with the prefix from,
+// skipping the secondary index entries for the empty prefix.
+func seekKey(prefix string) string {
+	if prefix == "" {
+		return firstItemKeyPrefix
+	}
+	return prefix
+}
+
+// startKey returns the key to start an iteration from: the first key after
+// the after key, if set and past the seek key, or the seek key otherwise.
+func startKey(seek string, after string) string {
+	if after == "" {
+		return seek
+	}
+	return max(seek, after+"\x00")
+}
+
+// trigrams returns the distinct trigrams of s in sorted order.
+func trigrams(s string) []string {
+	if len(s) < trigramLength {
+		return nil
+	}
+	tris := make([]string, 0, len(s)-trigramLength+1)
+	for i := 0; i+trigramLength <= len(s); i++ {
+		tris = append(tris, s[i:i+trigramLength])
+	}
+	slices.Sort(tris)
+	return slices.Compact(tris)
+}
+
+// literalTrigrams returns the distinct trigrams of all literals.
+func literalTrigrams(literals []string) []string {
+	var tris []string
+	for _, l := range literals {
+		tris = append(tris, trigrams(l)...)
+	}
+	slices.Sort(tris)
+	return slices.Compact(tris)
+}
+
+// keyPath returns the path part of the item key, after its indexer and item
+// type, or the whole key if it has none.
+func keyPath(key string) string {
+	if _, rest, ok := strings.Cut(key, "_"); ok {
+		if _, path, ok := strings.Cut(rest, "_"); ok {
+			return path
+		}
+	}
+	return key
+}
+
+// trigramKeys returns the posting list entry keys of the item key for the
+// trigrams of its path and the trigrams of its case and accent folded path
+// that aren't trigrams of the path already.
+func trigramKeys(key string) (keys [][]byte) {
+	path := keyPath(key)
+	tris := trigrams(path)
+	for _, tri := range tris {
+		keys = append(keys, []byte(trigramPrefix+tri+key))
+	}
+	for _, tri := range trigrams(fold.String(path, fold.All)) {
+		if _, ok := slices.BinarySearch(tris, tri); !ok {
+			keys = append(keys, []byte(foldedPrefix+tri+key))
+		}
+	}
+	return
+}
+
+// postingPrefixes returns the prefixes of the posting lists of the literals and
+// terms, each as the prefixes of the lists whose union it is: the trigram
+// posting lists of the literals, joined with the folded trigram posting lists
+// if the literals are folded, and the content posting lists of the terms.
+func postingPrefixes(literals []string, folded bool, terms []string) (postings [][]string) {
+	for _, tri := range literalTrigrams(literals) {
+		if folded {
+			postings = append(postings, []string{foldedPrefix + tri, trigramPrefix + tri})
+		} else {
+			postings = append(postings, []string{trigramPrefix + tri})
+		}
+	}
+	for _, t := range slices.Compact(slices.Sorted(slices.Values(terms))) {
+		postings = append(postings, []string{contentTermPrefix(t)})
+	}
+	return
+}
+
+// postingIterator iterates over the item keys of the union of posting lists.
+type postingIterator struct {
+	its      []*badger.Iterator
+	prefixes [][]byte
+}
+
+// seek positions the iterator at the first item key greater or equal to key and
+// returns it. It returns false if the posting lists are exhausted.
+func (p *postingIterator) seek(key string) (next string, ok bool) {
+	for i, it := range p.its {
+		it.Seek(append(bytes.Clone(p.prefixes[i]), key...))
+		if !it.ValidForPrefix(p.prefixes[i]) {
+			continue
+		}
+		if k := string(it.Item().Key()[len(p.prefixes[i]):]); !ok || k < next {
+			next, ok = k, true
+		}
+	}
+	return
+}
+
+// intersectPostings calls fn in key order for every item key starting with prefix,
+// beginning at the start key, that is present in all postings, each the union of
+// the posting lists of its prefixes, using a leapfrog join so only the shortest
+// posting is walked entirely. Iteration stops when fn returns false.
+func intersectPostings(txn *badger.Txn, postings [][]string, prefix string, start string, fn func(key string) bool) {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+
+	its := make([]*postingIterator, len(postings))
+	for i, prefixes := range postings {
+		its[i] = &postingIterator{}
+		for _, posting := range prefixes {
+			p := []byte(posting)
+			o := opts
+			o.Prefix = p
+			it := txn.NewIterator(o)
+			defer it.Close()
+			its[i].its = append(its[i].its, it)
+			its[i].prefixes = append(its[i].prefixes, p)
+		}
+	}
+
+	candidate := start
+	for {
+		matched := 0
+		for i := 0; matched < len(its); i = (i + 1) % len(its) {
+			key, ok := its[i].seek(candidate)
+			if !ok || !strings.HasPrefix(key, prefix) {
+				return
+			}
+			if key == candidate {
+				matched++
+				continue
+			}
+			candidate = key
+			matched = 1
+		}
+		if !fn(candidate) {
+			return
+		}
+		// Smallest key greater than the matched one.
+		candidate += "\x00"
+	}
+}